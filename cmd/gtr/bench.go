@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// The label under which lookups matching no route are reported
+const _noMatch = "(no match)"
+
+// Replays a URL corpus against a rule file and reports throughput,
+// allocations, and per-route latency percentiles
+//
+//	gtr bench [-rounds 10] [-overlay production.json] rules.json urls.txt
+//
+// Rule files are JSON, which is also valid YAML, so `routes.yaml`
+// files written in JSON syntax are accepted.
+func bench(args []string) error {
+	return runBench(os.Stdout, args)
+}
+
+// Runs the bench command and writes its report to w
+func runBench(w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	rounds := flags.Int("rounds", 10, "number of times the corpus is replayed")
+	overlays := flags.String("overlay", "", "comma separated JSON overlay files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: gtr bench [flags] <rules> <urls>")
+	}
+	if *rounds < 1 {
+		return fmt.Errorf("-rounds must be at least 1")
+	}
+	rt, err := loadTable(flags.Arg(0), *overlays)
+	if err != nil {
+		return err
+	}
+	urls, err := readURLs(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("%s contains no URLs", flags.Arg(1))
+	}
+
+	// The results are recorded into preallocated slices, so that the
+	// memory statistics only account for the lookups
+	lookups := len(urls) * *rounds
+	hashes := make([]string, lookups)
+	elapsed := make([]time.Duration, lookups)
+	before := runtime.MemStats{}
+	after := runtime.MemStats{}
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for round := 0; round < *rounds; round++ {
+		for index, url := range urls {
+			lookup := time.Now()
+			hash, err := rt.Find(url)
+			position := round*len(urls) + index
+			elapsed[position] = time.Since(lookup)
+			if err != nil {
+				hash = _noMatch
+			}
+			hashes[position] = hash
+		}
+	}
+	total := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	latencies := make(map[string][]time.Duration)
+	for position, hash := range hashes {
+		latencies[hash] = append(latencies[hash], elapsed[position])
+	}
+	fmt.Fprintf(w, "routes:      %d\n", len(rt.Routes()))
+	fmt.Fprintf(w, "lookups:     %d (%d urls x %d rounds)\n", lookups, len(urls), *rounds)
+	fmt.Fprintf(w, "throughput:  %.0f lookups/s\n", float64(lookups)/total.Seconds())
+	fmt.Fprintf(w, "allocations: %.1f allocs/lookup, %.1f B/lookup\n",
+		float64(after.Mallocs-before.Mallocs)/float64(lookups),
+		float64(after.TotalAlloc-before.TotalAlloc)/float64(lookups))
+	fmt.Fprintln(w)
+	return report(w, rt, latencies)
+}
+
+// Prints the latency percentiles of every route, busiest first
+func report(w io.Writer, rt *gtr.RouteTable, latencies map[string][]time.Duration) error {
+	hashes := make([]string, 0, len(latencies))
+	for hash, samples := range latencies {
+		hashes = append(hashes, hash)
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i] < samples[j]
+		})
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		if len(latencies[hashes[i]]) != len(latencies[hashes[j]]) {
+			return len(latencies[hashes[i]]) > len(latencies[hashes[j]])
+		}
+		return hashes[i] < hashes[j]
+	})
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ROUTE\tLOOKUPS\tP50\tP90\tP99\tMAX")
+	for _, hash := range hashes {
+		samples := latencies[hash]
+		name := hash
+		if route, err := rt.Lookup(hash); err == nil {
+			name = route.Template()
+		}
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t%s\t%s\n", name, len(samples),
+			percentile(samples, 50), percentile(samples, 90), percentile(samples, 99), samples[len(samples)-1])
+	}
+	return writer.Flush()
+}
+
+// Gets a percentile of sorted samples using the nearest-rank method
+func percentile(samples []time.Duration, p int) time.Duration {
+	rank := (p*len(samples) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return samples[rank-1]
+}
+
+// Reads a file of newline separated URLs. Empty lines and lines
+// starting with `#` are skipped.
+func readURLs(path string) ([]*url.URL, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	urls := make([]*url.URL, 0)
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		url, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		urls = append(urls, url)
+	}
+	return urls, scanner.Err()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func PrepareCorpus(t *testing.T) (string, string) {
+	directory := t.TempDir()
+	rules := filepath.Join(directory, "rules.json")
+	urls := filepath.Join(directory, "urls.txt")
+	os.WriteFile(rules, []byte(`{"routes": [
+		{"template": "http://bench.example.com/users/:id"},
+		{"template": "http://bench.example.com/posts/:id"}
+	]}`), 0644)
+	os.WriteFile(urls, []byte(strings.Join([]string{
+		"# the corpus",
+		"http://bench.example.com/users/1",
+		"",
+		"http://bench.example.com/users/2",
+		"http://bench.example.com/posts/1",
+		"http://bench.example.com/comments/1",
+	}, "\n")), 0644)
+	return rules, urls
+}
+
+func TestBenchReport(t *testing.T) {
+	rules, urls := PrepareCorpus(t)
+	output := bytes.Buffer{}
+	if err := runBench(&output, []string{"-rounds", "2", rules, urls}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 9 {
+		t.Logf("unexpected report\n%s", output.String())
+		t.FailNow()
+	}
+	if !strings.HasPrefix(lines[1], "lookups:     8 (4 urls x 2 rounds)") || !strings.HasPrefix(lines[3], "allocations: ") {
+		t.Logf("unexpected summary\n%s", output.String())
+		t.FailNow()
+	}
+	if fields := strings.Fields(lines[5]); strings.Join(fields, " ") != "ROUTE LOOKUPS P50 P90 P99 MAX" {
+		t.Logf("unexpected header %q", lines[5])
+		t.FailNow()
+	}
+	// The busiest route comes first, and routes with as many lookups
+	// are ordered by hash
+	expected := map[string]string{
+		"http://bench.example.com/users/:id": "4",
+		"http://bench.example.com/posts/:id": "2",
+		_noMatch:                             "2",
+	}
+	for index, line := range lines[6:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			t.Logf("unexpected row %q", line)
+			t.FailNow()
+		}
+		if index == 0 && fields[0] != "http://bench.example.com/users/:id" {
+			t.Logf("expected the busiest route first but found %q", line)
+			t.FailNow()
+		}
+		name := strings.Join(fields[:len(fields)-5], " ")
+		if expected[name] != fields[len(fields)-5] {
+			t.Logf("unexpected row %q", line)
+			t.FailNow()
+		}
+	}
+}
+
+func TestBenchFlags(t *testing.T) {
+	rules, urls := PrepareCorpus(t)
+	tests := [][]string{
+		{"-unknown", rules, urls},
+		{rules},
+		{"-rounds", "0", rules, urls},
+		{rules, filepath.Join(t.TempDir(), "missing.txt")},
+	}
+	for _, args := range tests {
+		if err := runBench(&bytes.Buffer{}, args); err == nil {
+			t.Logf("expected %v to fail", args)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// The gtr command exposes route tables loaded from rule files
+//
+//	gtr serve -rules rules.json [-overlay production.json] [-addr :8080] [-admin]
+//	gtr bench [-rounds 10] [-overlay production.json] rules.json urls.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// The commands of the CLI keyed by name
+var _commands = map[string]func(args []string) error{
+	"serve": serve,
+	"bench": bench,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, ok := _commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gtr <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  serve    serves the classification service of a rule file")
+	fmt.Fprintln(os.Stderr, "  bench    replays a URL corpus against a rule file")
+}
+
+// Loads a route table from a rule file and comma separated overlays
+func loadTable(rules string, overlays string) (*gtr.RouteTable, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("a rule file is required")
+	}
+	rt := gtr.DefaultRouteTable()
+	files := make([]string, 0)
+	for _, overlay := range strings.Split(overlays, ",") {
+		if len(overlay) > 0 {
+			files = append(files, overlay)
+		}
+	}
+	if err := rt.LoadRuleFiles(rules, files...); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	rules := flags.String("rules", "", "JSON rule file")
+	overlays := flags.String("overlay", "", "comma separated JSON overlay files")
+	admin := flags.Bool("admin", false, "serve the admin API under /admin/")
+	flags.Parse(args)
+	rt, err := loadTable(*rules, *overlays)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", gtr.NewServiceHandler(rt))
+	if *admin {
+		mux.Handle("/admin/", http.StripPrefix("/admin", gtr.NewAdminHandler(rt)))
+	}
+	fmt.Fprintf(os.Stderr, "serving %d routes on %s\n", len(rt.Routes()), *addr)
+	return http.ListenAndServe(*addr, mux)
+}
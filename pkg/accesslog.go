@@ -0,0 +1,179 @@
+package gtr
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The AccessLogOptions struct controls how access logs are learned
+// Params:
+//   - BaseURL: The scheme and host of the logged service, since the
+//     common log format does not record them
+//   - Register: Registers learned templates for unmatched URLs as
+//     CLASS_LEARNED routes
+//   - Config: The config of registered learned templates
+type AccessLogOptions struct {
+	BaseURL  *url.URL
+	Register bool
+	Config   map[string]any
+}
+
+// The AccessLogReport struct summarizes an access log
+type AccessLogReport struct {
+	Lines     int              `json:"lines"`
+	Matched   int              `json:"matched"`
+	Malformed int              `json:"malformed"`
+	Unmatched []UnmatchedShape `json:"unmatched"`
+}
+
+// The UnmatchedShape struct groups unmatched URLs that share the same
+// shape once their identifiers are replaced by parameters
+type UnmatchedShape struct {
+	Template string   `json:"template"`
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+const (
+	_maxExamples = 3
+)
+
+var (
+	_uuidSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	_hexSegment  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// Reads an access log in common or combined log format, matches every
+// request against the route table, and reports the shapes of the URLs
+// that did not match
+func (rt *RouteTable) LearnFromAccessLog(r io.Reader, opts AccessLogOptions) (*AccessLogReport, error) {
+	report := AccessLogReport{Unmatched: make([]UnmatchedShape, 0)}
+	shapes := make(map[string]*UnmatchedShape)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		report.Lines++
+		target, ok := parseAccessLogTarget(line)
+		if !ok {
+			report.Malformed++
+			continue
+		}
+		url, err := url.ParseRequestURI(target)
+		if err != nil {
+			report.Malformed++
+			continue
+		}
+		if opts.BaseURL != nil {
+			url.Scheme = opts.BaseURL.Scheme
+			url.Host = opts.BaseURL.Host
+		}
+		if _, err := rt.Find(url); err == nil {
+			report.Matched++
+			continue
+		}
+		template := learnTemplate(url)
+		shape, ok := shapes[template]
+		if !ok {
+			shape = &UnmatchedShape{Template: template, Examples: make([]string, 0)}
+			shapes[template] = shape
+		}
+		shape.Count++
+		if len(shape.Examples) < _maxExamples {
+			shape.Examples = append(shape.Examples, target)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, shape := range shapes {
+		report.Unmatched = append(report.Unmatched, *shape)
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		if report.Unmatched[i].Count != report.Unmatched[j].Count {
+			return report.Unmatched[i].Count > report.Unmatched[j].Count
+		}
+		return report.Unmatched[i].Template < report.Unmatched[j].Template
+	})
+	if opts.Register {
+		for _, shape := range report.Unmatched {
+			url, err := url.Parse(shape.Template)
+			if err != nil {
+				return nil, err
+			}
+			if err := rt.Register(url, opts.Config, WithClass(CLASS_LEARNED)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &report, nil
+}
+
+// Extracts the request target from a log line such as
+// `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326`
+func parseAccessLogTarget(line string) (string, bool) {
+	start := strings.IndexByte(line, '"')
+	if start < 0 {
+		return "", false
+	}
+	end := strings.IndexByte(line[start+1:], '"')
+	if end < 0 {
+		return "", false
+	}
+	fields := strings.Fields(line[start+1 : start+1+end])
+	if len(fields) < 2 || !strings.HasPrefix(fields[1], "/") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// Replaces the segments of a URL that look like identifiers with
+// parameters. Query strings are dropped so learned templates do not
+// constrain them.
+func learnTemplate(url *url.URL) string {
+	segments := strings.Split(url.Path, "/")
+	counts := make(map[string]int)
+	for index, segment := range segments {
+		name := ""
+		switch {
+		case len(segment) == 0:
+			continue
+		case isNumeric(segment):
+			name = "id"
+		case _uuidSegment.MatchString(segment):
+			name = "uuid"
+		case _hexSegment.MatchString(segment):
+			name = "hash"
+		default:
+			continue
+		}
+		counts[name]++
+		if counts[name] > 1 {
+			name += strconv.Itoa(counts[name])
+		}
+		segments[index] = ":" + name
+	}
+	template := *url
+	template.Path = strings.Join(segments, "/")
+	template.RawPath = ""
+	template.RawQuery = ""
+	template.ForceQuery = false
+	return formatTemplate(&template)
+}
+
+func isNumeric(segment string) bool {
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(segment) > 0
+}
@@ -0,0 +1,39 @@
+package gtr
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLearnFromAccessLog(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": nil,
+	})
+	log := strings.Join([]string{
+		`127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /api/v1/users/ken/details HTTP/1.0" 200 2326`,
+		`127.0.0.1 - - [10/Oct/2000:13:55:37 -0700] "GET /api/v1/orders/15/items/3 HTTP/1.1" 200 12 "-" "curl/8.0"`,
+		`127.0.0.1 - - [10/Oct/2000:13:55:38 -0700] "GET /api/v1/orders/16/items/4?x=1 HTTP/1.1" 200 12 "-" "curl/8.0"`,
+		`127.0.0.1 - - [10/Oct/2000:13:55:39 -0700] "GET /api/v1/files/0f8fad5b-d9cb-469f-a165-70867728950e HTTP/1.1" 404 0`,
+		`garbage`,
+	}, "\n")
+	base, _ := url.Parse("http://www.abcdefg.com")
+	report, err := rt.LearnFromAccessLog(strings.NewReader(log), AccessLogOptions{BaseURL: base, Register: true})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if report.Lines != 5 || report.Matched != 1 || report.Malformed != 1 {
+		t.Logf("unexpected report %+v", report)
+		t.FailNow()
+	}
+	if len(report.Unmatched) != 2 || report.Unmatched[0].Template != "http://www.abcdefg.com/api/v1/orders/:id/items/:id2" || report.Unmatched[0].Count != 2 {
+		t.Logf("unexpected shapes %+v", report.Unmatched)
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/orders/99/items/1")
+	if _, err := rt.Find(url); err != nil {
+		t.Log("learned template was not registered")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,211 @@
+package gtr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The RouteInfo struct is the representation of a route exposed by
+// the admin API
+type RouteInfo struct {
+	Hash      string              `json:"hash"`
+	Name      string              `json:"name,omitempty"`
+	Template  string              `json:"template"`
+	Method    string              `json:"method,omitempty"`
+	Class     string              `json:"class"`
+	Source    string              `json:"source,omitempty"`
+	Params    []string            `json:"params"`
+	ParamDocs map[string]ParamDoc `json:"paramDocs,omitempty"`
+	Config    map[string]any      `json:"config"`
+	Ownership *Ownership          `json:"ownership,omitempty"`
+}
+
+const (
+	_mergePatchContentType = "application/merge-patch+json"
+)
+
+// Describes a registered route
+func (rt *RouteTable) Info(hash string) (*RouteInfo, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return nil, err
+	}
+	return rt.info(route), nil
+}
+
+func (rt *RouteTable) info(route *Route) *RouteInfo {
+	return &RouteInfo{
+		Hash:      route.hash,
+		Name:      route.name,
+		Template:  route.template,
+		Method:    route.method,
+		Class:     route.class.String(),
+		Source:    route.source,
+		Params:    route.Params(),
+		ParamDocs: route.ParamDocs(),
+		Config:    rt.configs[route.hash],
+		Ownership: route.owners(),
+	}
+}
+
+// Creates an http.Handler exposing the route table as a REST resource
+//
+//	GET   /routes          lists every route
+//	GET   /routes/{hash}   describes a route
+//	PATCH /routes/{hash}   updates the config of a route using a JSON
+//	                       Merge Patch (application/merge-patch+json)
+//	GET   /explain?url=...&method=... explains how a URL is resolved
+//	GET   /stats           reports the statistics of the table
+//	GET   /bypass          reports the state of the emergency bypass
+//	PUT   /bypass          turns the emergency bypass on or off, for
+//	                       example {"on": true, "reason": "..."}
+//	GET   /schema          gets the JSON Schema of rule files
+//
+// Use http.StripPrefix to mount the handler under a prefix.
+func NewAdminHandler(rt *RouteTable) http.Handler {
+	return &adminHandler{rt: rt}
+}
+
+type adminHandler struct {
+	rt *RouteTable
+}
+
+func (handler *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "routes":
+		handler.routes(w, r)
+	case strings.HasPrefix(path, "routes/"):
+		handler.route(w, r, strings.TrimPrefix(path, "routes/"))
+	case path == "explain":
+		handler.explain(w, r)
+	case path == "stats":
+		handler.stats(w, r)
+	case path == "bypass":
+		handler.bypass(w, r)
+	case path == "schema":
+		handler.schema(w, r)
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (handler *adminHandler) routes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	infos := make([]*RouteInfo, 0)
+	for _, route := range handler.rt.Routes() {
+		info, _ := handler.rt.Info(route.hash)
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (handler *adminHandler) route(w http.ResponseWriter, r *http.Request, hash string) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != _mergePatchContentType {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("expected "+_mergePatchContentType))
+			return
+		}
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := handler.rt.PatchConfig(hash, patch); err != nil {
+			writeError(w, statusOf(err), err)
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	info, err := handler.rt.Info(hash)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (handler *adminHandler) explain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	url, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, handler.rt.ExplainMethod(r.URL.Query().Get("method"), url))
+}
+
+func (handler *adminHandler) stats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, handler.rt.Stats())
+}
+
+func (handler *adminHandler) bypass(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var bypass GlobalBypass
+		if err := json.NewDecoder(r.Body).Decode(&bypass); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		handler.rt.SetGlobalBypass(bypass.On, bypass.Reason)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, handler.rt.GlobalBypass())
+}
+
+func (handler *adminHandler) schema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ConfigSchema())
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, HASH_NOT_REGISTERED), isMiss(err):
+		return http.StatusNotFound
+	case errors.Is(err, INVALID_PATCH), errors.Is(err, INVALID_VALUE), errors.Is(err, UNSUPPORTED_SCHEME):
+		return http.StatusBadRequest
+	case errors.Is(err, TABLE_FROZEN):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
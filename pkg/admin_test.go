@@ -0,0 +1,71 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10, "format": "json"},
+	})
+	hash, _ := rt.Find(PrepareURL(t))
+	handler := NewAdminHandler(rt)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/routes", nil))
+	infos := make([]RouteInfo, 0)
+	if err := json.Unmarshal(recorder.Body.Bytes(), &infos); err != nil || len(infos) != 1 || infos[0].Hash != hash {
+		t.Logf("unexpected listing %s", recorder.Body.String())
+		t.FailNow()
+	}
+
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPatch, "/routes/"+hash, strings.NewReader(`{"ttl": 5, "format": null}`))
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	handler.ServeHTTP(recorder, request)
+	info := RouteInfo{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &info); err != nil || recorder.Code != http.StatusOK {
+		t.Logf("unexpected response %d %s", recorder.Code, recorder.Body.String())
+		t.FailNow()
+	}
+	if info.Config["ttl"] != 5.0 || len(info.Config) != 1 {
+		t.Logf("patch applied incorrectly %v", info.Config)
+		t.FailNow()
+	}
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPatch, "/routes/"+hash, strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Log("expected unsupported media type")
+		t.FailNow()
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/routes/unknown", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Log("expected not found")
+		t.FailNow()
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/explain?url="+PrepareURL(t).String(), nil))
+	explanation := Explanation{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &explanation); err != nil || explanation.Selected != hash {
+		t.Logf("unexpected explanation %s", recorder.Body.String())
+		t.FailNow()
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	stats := Stats{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil || stats.Routes != 1 {
+		t.Logf("unexpected stats %s", recorder.Body.String())
+		t.FailNow()
+	}
+}
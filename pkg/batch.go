@@ -0,0 +1,46 @@
+package gtr
+
+import (
+	"net/url"
+	"runtime"
+	"sync"
+)
+
+// The Result struct is the outcome of a single lookup of FindBatch
+type Result struct {
+	URL  *url.URL
+	Hash string
+	Err  error
+}
+
+// Finds the route templates of many URLs at once using a pool of
+// GOMAXPROCS workers. Results are returned in the order of the URLs.
+// The route table may be mutated while the batch is running, since
+// registered routes are replaced rather than mutated in place, but
+// every lookup sees the table as it is when the lookup is made, so the
+// lookups of a batch may see different versions of the table.
+func (rt *RouteTable) FindBatch(urls []*url.URL) []Result {
+	results := make([]Result, len(urls))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(urls) {
+		workers = len(urls)
+	}
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				hash, err := rt.Find(urls[index])
+				results[index] = Result{URL: urls[index], Hash: hash, Err: err}
+			}
+		}()
+	}
+	for index := range urls {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
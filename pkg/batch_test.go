@@ -0,0 +1,43 @@
+package gtr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestFindBatch(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	urls := make([]*url.URL, 0)
+	for i := 0; i < 100; i++ {
+		url, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/api/v1/users/user%d/details?type=cache", i))
+		urls = append(urls, url)
+	}
+	miss, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/details")
+	urls = append(urls, miss)
+	results := rt.FindBatch(urls)
+	if len(results) != len(urls) {
+		t.Logf("expected %d results but found %d", len(urls), len(results))
+		t.FailNow()
+	}
+	for index, result := range results[:100] {
+		if result.URL != urls[index] || result.Err != nil || result.Hash != CreateHash(template) {
+			t.Logf("unexpected result %v for %s", result, urls[index])
+			t.FailNow()
+		}
+	}
+	if !errors.Is(results[100].Err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", results[100].Err)
+		t.FailNow()
+	}
+	if len(rt.FindBatch(nil)) != 0 {
+		t.Log("expected no results for an empty batch")
+		t.FailNow()
+	}
+}
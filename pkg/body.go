@@ -0,0 +1,98 @@
+package gtr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Declares which parts of a JSON request body identify a request
+// so that POST requests (GraphQL, JSON-RPC, ...) can be cached
+// Params:
+//   - pointers: RFC 6901 JSON pointers, for example `/variables/id`
+func WithBodyKeys(pointers ...string) RouteOption {
+	return func(route *Route) error {
+		for _, pointer := range pointers {
+			if len(pointer) > 0 && !strings.HasPrefix(pointer, "/") {
+				return fmt.Errorf("%w: %s", INVALID_POINTER, pointer)
+			}
+		}
+		route.bodyKeys = append([]string(nil), pointers...)
+		return nil
+	}
+}
+
+// Creates a cache key for a matched request and its JSON body.
+// The key covers the route, its parameter values, and the values
+// found at the body keys declared for the route. Pointers that do
+// not resolve contribute a null value.
+func (rt *RouteTable) CacheKeyForBody(match *MatchResult, body []byte) (string, error) {
+	route, err := rt.Lookup(match.Hash)
+	if err != nil {
+		return "", err
+	}
+	var document any
+	if len(route.bodyKeys) > 0 {
+		if err := json.Unmarshal(body, &document); err != nil {
+			return "", fmt.Errorf("%w: %s", INVALID_BODY, err.Error())
+		}
+	}
+	hash := sha256.New()
+	hash.Write([]byte(match.Hash))
+	writeKeyVersion(hash, rt.GetConfig(match.Hash))
+	names := make([]string, 0, len(match.Params))
+	for name := range match.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(match.Params[name]))
+	}
+	for _, pointer := range route.bodyKeys {
+		value, _ := resolvePointer(document, pointer)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte{0})
+		hash.Write([]byte(pointer))
+		hash.Write([]byte{0})
+		hash.Write(encoded)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Resolves an RFC 6901 JSON pointer against a decoded JSON document
+func resolvePointer(document any, pointer string) (any, bool) {
+	if len(pointer) == 0 {
+		return document, true
+	}
+	current := document
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,60 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestCacheKeyForBody(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/graphql")
+	if err := rt.Register(template, nil, WithBodyKeys("/operationName", "/variables/id")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(template)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	first, err := rt.CacheKeyForBody(match, []byte(`{"operationName":"GetUser","variables":{"id":1},"extensions":{"a":1}}`))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	second, _ := rt.CacheKeyForBody(match, []byte(`{"variables":{"id":1},"operationName":"GetUser"}`))
+	if first != second {
+		t.Log("irrelevant body fields changed the key")
+		t.FailNow()
+	}
+	third, _ := rt.CacheKeyForBody(match, []byte(`{"operationName":"GetUser","variables":{"id":2}}`))
+	if first == third {
+		t.Log("body keys were not part of the key")
+		t.FailNow()
+	}
+	if _, err := rt.CacheKeyForBody(match, []byte(`{`)); !errors.Is(err, INVALID_BODY) {
+		t.Log("expected invalid body error")
+		t.FailNow()
+	}
+	other, _ := url.Parse("http://www.abcdefg.com/rpc")
+	if err := rt.Register(other, nil, WithBodyKeys("id")); !errors.Is(err, INVALID_POINTER) {
+		t.Log("expected invalid pointer error")
+		t.FailNow()
+	}
+}
+
+func TestResolvePointer(t *testing.T) {
+	document := map[string]any{
+		"a/b": []any{"x", map[string]any{"~c": 1.0}},
+	}
+	value, ok := resolvePointer(document, "/a~1b/1/~0c")
+	if !ok || value != 1.0 {
+		t.Log("pointer resolved incorrectly")
+		t.FailNow()
+	}
+	if _, ok := resolvePointer(document, "/a~1b/5"); ok {
+		t.Log("out of range pointer resolved")
+		t.FailNow()
+	}
+}
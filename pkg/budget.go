@@ -0,0 +1,96 @@
+package gtr
+
+import (
+	"sync"
+	"time"
+)
+
+// The ErrorBudget struct tracks the upstream error rate of a route
+// over a sliding window, approximated by weighting the previous
+// window by how much of it still overlaps the sliding window
+type ErrorBudget struct {
+	mutex       sync.Mutex
+	budget      float64
+	window      time.Duration
+	minRequests int
+	start       time.Time
+	current     budgetWindow
+	previous    budgetWindow
+	now         func() time.Time
+}
+
+type budgetWindow struct {
+	requests int
+	errors   int
+}
+
+// Creates the error budget of a policy
+// Returns nil if the policy has no error budget
+func NewErrorBudget(policy Policy) *ErrorBudget {
+	budget, ok := policy.ErrorBudget()
+	if !ok {
+		return nil
+	}
+	return &ErrorBudget{
+		budget:      budget,
+		window:      policy.ErrorWindow(),
+		minRequests: policy.ErrorMinRequests(),
+		start:       time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Records the outcome of an upstream request
+func (budget *ErrorBudget) Record(failed bool) {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	budget.advance()
+	budget.current.requests++
+	if failed {
+		budget.current.errors++
+	}
+}
+
+// Gets the upstream error rate over the sliding window
+func (budget *ErrorBudget) Rate() float64 {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	requests, errors := budget.counts()
+	if requests == 0 {
+		return 0
+	}
+	return errors / requests
+}
+
+// Checks whether the upstream error rate exceeds the error budget
+func (budget *ErrorBudget) Exhausted() bool {
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	requests, errors := budget.counts()
+	if requests < float64(budget.minRequests) || requests == 0 {
+		return false
+	}
+	return errors/requests > budget.budget
+}
+
+func (budget *ErrorBudget) counts() (float64, float64) {
+	budget.advance()
+	overlap := 1 - float64(budget.now().Sub(budget.start))/float64(budget.window)
+	requests := float64(budget.current.requests) + float64(budget.previous.requests)*overlap
+	errors := float64(budget.current.errors) + float64(budget.previous.errors)*overlap
+	return requests, errors
+}
+
+// Moves to the window containing the current time
+func (budget *ErrorBudget) advance() {
+	elapsed := budget.now().Sub(budget.start)
+	if elapsed < budget.window {
+		return
+	}
+	budget.previous = budget.current
+	if elapsed >= 2*budget.window {
+		budget.previous = budgetWindow{}
+	}
+	budget.current = budgetWindow{}
+	budget.start = budget.start.Add(elapsed / budget.window * budget.window)
+}
@@ -0,0 +1,63 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorBudgetPolicy(t *testing.T) {
+	policy := Policy{"error_budget": 0.25, "error_window": "10s", "error_min_requests": 4, "stale_if_error": "1h"}
+	if budget, ok := policy.ErrorBudget(); !ok || budget != 0.25 {
+		t.Logf("unexpected error budget %v", budget)
+		t.FailNow()
+	}
+	if stale, ok := policy.StaleIfError(); !ok || stale != time.Hour || policy.ErrorWindow() != 10*time.Second || policy.ErrorMinRequests() != 4 {
+		t.Log("unexpected error budget settings")
+		t.FailNow()
+	}
+	if stale, ok := (Policy{"stale_while_revalidate": 30}).StaleWhileRevalidate(); !ok || stale != 30*time.Second {
+		t.Logf("unexpected stale-while-revalidate period %s", stale)
+		t.FailNow()
+	}
+	if NewErrorBudget(Policy{}) != nil {
+		t.Log("expected no error budget without the policy key")
+		t.FailNow()
+	}
+	if (Policy{}).ErrorWindow() != time.Minute || (Policy{}).ErrorMinRequests() != 10 {
+		t.Log("unexpected defaults")
+		t.FailNow()
+	}
+}
+
+func TestErrorBudget(t *testing.T) {
+	now := time.Now()
+	budget := NewErrorBudget(Policy{"error_budget": 0.25, "error_window": "10s", "error_min_requests": 4})
+	budget.start = now
+	budget.now = func() time.Time { return now }
+	budget.Record(true)
+	budget.Record(true)
+	if budget.Exhausted() {
+		t.Log("expected the budget to hold below the minimum requests")
+		t.FailNow()
+	}
+	budget.Record(false)
+	budget.Record(false)
+	if !budget.Exhausted() || budget.Rate() != 0.5 {
+		t.Logf("expected the budget to be exhausted at rate %v", budget.Rate())
+		t.FailNow()
+	}
+	// Halfway through the next window the previous window counts half
+	now = now.Add(15 * time.Second)
+	for i := 0; i < 4; i++ {
+		budget.Record(false)
+	}
+	if budget.Rate() != 1.0/6 || budget.Exhausted() {
+		t.Logf("unexpected rate %v", budget.Rate())
+		t.FailNow()
+	}
+	now = now.Add(time.Minute)
+	if budget.Rate() != 0 {
+		t.Logf("expected old windows to be dropped but found rate %v", budget.Rate())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,186 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Builds a URL matching the route by filling the parameters of its
+// template. Literal query params of the template are added as they
+// are, while typed and wildcard query params must be given values
+// through the query.
+// Params:
+//   - params: The values of the route parameters keyed by name,
+//     including host parameters and query placeholders
+//   - query: Query params added to the URL
+func (route *Route) Build(params map[string]string, query url.Values) (*url.URL, error) {
+	if len(route.url.Opaque) > 0 {
+		return nil, fmt.Errorf("%w: virtual routes cannot be built", UNSUPPORTED_SCHEME)
+	}
+	names := make(map[string]bool)
+	for _, name := range route.Params() {
+		names[name] = true
+	}
+	for name := range params {
+		if !names[name] {
+			return nil, fmt.Errorf("%w: %s", UNKNOWN_PARAMETER, name)
+		}
+	}
+	host, err := route.buildHost(params)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]string, len(route.segments))
+	for position, segment := range route.segments {
+		segments[position] = segment.value
+		if !segment.param() {
+			continue
+		}
+		value, err := segment.fill(params)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(value, "/") {
+			return nil, fmt.Errorf("%w: %s contains a slash", INVALID_VALUE, segment.value)
+		}
+		segments[position] = value
+	}
+	values, err := route.buildQuery(params, query)
+	if err != nil {
+		return nil, err
+	}
+	return &url.URL{
+		Scheme:   route.url.Scheme,
+		Host:     host,
+		Path:     "/" + strings.Join(segments, "/"),
+		RawQuery: values.Encode(),
+	}, nil
+}
+
+// Fills the host labels of the template. Wildcard labels cannot be
+// filled, as they are not named.
+func (route *Route) buildHost(params map[string]string) (string, error) {
+	if len(route.hostLabels) == 0 {
+		return route.host, nil
+	}
+	labels := make([]string, len(route.hostLabels))
+	for index, label := range route.hostLabels {
+		labels[index] = label
+		if label == "*" {
+			return "", fmt.Errorf("%w: the host of %s has wildcard labels", MISSING_PARAMETER, route.template)
+		}
+		name, ok := route.hostParams[index]
+		if !ok {
+			continue
+		}
+		value, err := fill(name, route.hostConstraints[index], params)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(value, ".") {
+			return "", fmt.Errorf("%w: %s contains a dot", INVALID_VALUE, name)
+		}
+		labels[index] = strings.ToLower(value)
+	}
+	host := strings.Join(labels, ".")
+	if len(route.hostPort) > 0 {
+		host += ":" + route.hostPort
+	}
+	return host, nil
+}
+
+// Adds the query params of the template to the given query, checking
+// the values of typed and wildcard query params
+func (route *Route) buildQuery(params map[string]string, query url.Values) (url.Values, error) {
+	values := make(url.Values, len(query)+len(route.queryParams))
+	for key, value := range query {
+		values[key] = append([]string(nil), value...)
+	}
+	if route.noQuery && len(values) > 0 {
+		return nil, fmt.Errorf("%w: %s accepts no query params", INVALID_VALUE, route.template)
+	}
+	for key, value := range route.queryParams {
+		if name, ok := route.queryNames[key]; ok {
+			filled, err := fill(name, route.queryConstraints[key], params)
+			if err != nil {
+				return nil, err
+			}
+			values.Set(key, filled)
+			continue
+		}
+		if paramType, ok := route.queryTypes[key]; ok {
+			if !values.Has(key) {
+				return nil, fmt.Errorf("%w: query param %s", MISSING_PARAMETER, key)
+			}
+			if _, err := paramType.Coerce(values.Get(key)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if values.Has(key) && values.Get(key) != value {
+			return nil, fmt.Errorf("%w: query param %s must be %s", INVALID_VALUE, key, value)
+		}
+		values.Set(key, value)
+	}
+	if len(route.queryWildcards) > 0 {
+		given := make(map[string]string, len(values))
+		for key := range values {
+			given[key] = values.Get(key)
+		}
+		for pattern, value := range route.queryWildcards {
+			switch _, result := matchWildcard(pattern, value, given); result {
+			case CHECK_MISSING:
+				return nil, fmt.Errorf("%w: query params matching %s", MISSING_PARAMETER, pattern)
+			case CHECK_MISMATCH:
+				return nil, fmt.Errorf("%w: query params matching %s must match %s", INVALID_VALUE, pattern, value)
+			}
+		}
+	}
+	return values, nil
+}
+
+// Gets the value of a parameter, checking it against its constraint
+func fill(name string, constraint paramConstraint, params map[string]string) (string, error) {
+	value, ok := params[name]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("%w: %s", MISSING_PARAMETER, name)
+	}
+	if constraint != nil && !constraint.accepts(value) {
+		return "", fmt.Errorf("%w: %s does not satisfy %s", INVALID_VALUE, name, constraint)
+	}
+	return value, nil
+}
+
+// Gets the value of a parameter segment, joining the value of a
+// parameter and its extension for segments with an extension parameter
+func (segment segment) fill(params map[string]string) (string, error) {
+	constraint, ok := segment.constraint.(extensionConstraint)
+	if !ok {
+		return fill(segment.value, segment.constraint, params)
+	}
+	base, err := fill(segment.value, constraint.base, params)
+	if err != nil {
+		return "", err
+	}
+	extension, err := fill(segment.extension, nil, params)
+	if err != nil {
+		return "", err
+	}
+	if !constraint.allows(extension) || strings.Contains(extension, ".") {
+		return "", fmt.Errorf("%w: %s does not satisfy %s", INVALID_VALUE, segment.extension, constraint)
+	}
+	return base + "." + extension, nil
+}
+
+// Builds a URL of the route registered under a name (see WithName
+// and Route.Build)
+func (rt *RouteTable) BuildByName(name string, params map[string]string, query url.Values) (*url.URL, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, err := rt.named(name)
+	if err != nil {
+		return nil, err
+	}
+	return route.Build(params, query)
+}
@@ -0,0 +1,81 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	template, _ := ParseTemplate("https://:tenant.shop.com/api/v1/users/:id<int>/posts/:slug?type=cache&page=<int>&sort=:order")
+	if err := rt.Register(template, nil, WithName("posts")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	built, err := rt.BuildByName("posts", map[string]string{"tenant": "Acme", "id": "42", "slug": "hello world", "order": "asc"}, url.Values{"page": {"2"}})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := "https://acme.shop.com/api/v1/users/42/posts/hello%20world?page=2&sort=asc&type=cache"
+	if built.String() != expected {
+		t.Logf("expected %s but found %s", expected, built)
+		t.FailNow()
+	}
+	match, err := rt.Match(built)
+	if err != nil || match.Params["slug"] != "hello world" || match.Params["tenant"] != "acme" {
+		t.Logf("expected the built URL to match but found %v %v", match, err)
+		t.FailNow()
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/users/:id<int>?type=cache&page=<int>"), nil, WithName("user"))
+	tests := []struct {
+		params map[string]string
+		query  url.Values
+		err    error
+	}{
+		{map[string]string{}, url.Values{"page": {"1"}}, MISSING_PARAMETER},
+		{map[string]string{"id": "ken"}, url.Values{"page": {"1"}}, INVALID_VALUE},
+		{map[string]string{"id": "1/2"}, url.Values{"page": {"1"}}, INVALID_VALUE},
+		{map[string]string{"id": "1", "name": "ken"}, url.Values{"page": {"1"}}, UNKNOWN_PARAMETER},
+		{map[string]string{"id": "1"}, nil, MISSING_PARAMETER},
+		{map[string]string{"id": "1"}, url.Values{"page": {"one"}}, INVALID_VALUE},
+		{map[string]string{"id": "1"}, url.Values{"page": {"1"}, "type": {"fresh"}}, INVALID_VALUE},
+	}
+	for _, test := range tests {
+		if _, err := rt.BuildByName("user", test.params, test.query); !errors.Is(err, test.err) {
+			t.Logf("expected %v for %v %v but found %v", test.err, test.params, test.query, err)
+			t.FailNow()
+		}
+	}
+	if _, err := rt.BuildByName("unknown", nil, nil); !errors.Is(err, NAME_NOT_REGISTERED) {
+		t.Logf("expected NAME_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/posts/:id"), nil, WithName("user")); !errors.Is(err, DUPLICATE_NAME) {
+		t.Logf("expected DUPLICATE_NAME but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestBuildWildcards(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/items?filter[*]=*"), nil, WithName("items"))
+	if _, err := rt.BuildByName("items", nil, nil); !errors.Is(err, MISSING_PARAMETER) {
+		t.Logf("expected MISSING_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+	built, err := rt.BuildByName("items", nil, url.Values{"filter[name]": {"ken"}})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(built); err != nil {
+		t.Logf("expected %s to match: %v", built, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,43 @@
+package gtr
+
+import "time"
+
+// The GlobalBypass struct describes the emergency bypass of the route
+// table
+// Params:
+//   - On: Whether caching is bypassed for every route
+//   - Reason: Why the bypass was turned on, for incident responders
+//   - Since: When the bypass was turned on
+type GlobalBypass struct {
+	On     bool      `json:"on"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// Turns the emergency bypass on or off. While it is on, the policy of
+// every route reports bypass (see GetPolicy) regardless of its config,
+// so that caching can be disabled at once during an incident. Matching
+// is left untouched. The bypass can be toggled on frozen tables too.
+// Params:
+//   - on: Whether caching is bypassed for every route
+//   - reason: Why the bypass is turned on
+func (rt *RouteTable) SetGlobalBypass(on bool, reason string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if !on {
+		rt.bypass = GlobalBypass{}
+		return
+	}
+	if !rt.bypass.On {
+		rt.bypass.Since = time.Now()
+	}
+	rt.bypass.On = true
+	rt.bypass.Reason = reason
+}
+
+// Gets the state of the emergency bypass
+func (rt *RouteTable) GlobalBypass() GlobalBypass {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.bypass
+}
@@ -0,0 +1,78 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGlobalBypass(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 10}, WithMethodOverlay(ANY_METHOD, map[string]any{"bypass": false})); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	if rt.GetPolicy(hash, "GET").Bypass() {
+		t.Log("expected caching not to be bypassed")
+		t.FailNow()
+	}
+	rt.Freeze()
+	rt.SetGlobalBypass(true, "incident 42")
+	if !rt.GetPolicy(hash, "GET").Bypass() || !rt.GetPolicy("unknown", "GET").Bypass() {
+		t.Log("expected caching to be bypassed for every route")
+		t.FailNow()
+	}
+	if found, err := rt.Find(PrepareURL(t)); err != nil || found != hash {
+		t.Logf("expected %s to be found but found %s: %v", hash, found, err)
+		t.FailNow()
+	}
+	if ttl, ok := rt.GetPolicy(hash, "GET").TTL(); !ok || ttl.Seconds() != 10 {
+		t.Log("expected the config to be kept")
+		t.FailNow()
+	}
+	stats := rt.Stats()
+	if !stats.Bypass.On || stats.Bypass.Reason != "incident 42" || stats.Bypass.Since.IsZero() {
+		t.Logf("unexpected bypass %v", stats.Bypass)
+		t.FailNow()
+	}
+	rt.SetGlobalBypass(false, "")
+	if rt.GetPolicy(hash, "GET").Bypass() || rt.GlobalBypass().On {
+		t.Log("expected the bypass to be turned off")
+		t.FailNow()
+	}
+}
+
+func TestAdminGlobalBypass(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	rt.Register(template, map[string]any{"ttl": 10})
+	handler := NewAdminHandler(rt)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/bypass", strings.NewReader(`{"on": true, "reason": "incident 42"}`)))
+	if recorder.Code != http.StatusOK {
+		t.Logf("expected 200 but found %d: %s", recorder.Code, recorder.Body.String())
+		t.FailNow()
+	}
+	if !rt.GetPolicy(CreateHash(template), "GET").Bypass() {
+		t.Log("expected caching to be bypassed")
+		t.FailNow()
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	stats := Stats{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil || !stats.Bypass.On || stats.Bypass.Reason != "incident 42" {
+		t.Logf("unexpected stats %s", recorder.Body.String())
+		t.FailNow()
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/bypass", strings.NewReader(`{"on": false}`)))
+	bypass := GlobalBypass{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &bypass); err != nil || bypass.On {
+		t.Logf("unexpected bypass %s", recorder.Body.String())
+		t.FailNow()
+	}
+}
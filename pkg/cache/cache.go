@@ -0,0 +1,545 @@
+// Package cache implements an HTTP response cache driven by the
+// policies of a GTR route table
+package cache
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// The statuses cached when returned by the upstream (RFC 9111, 4.2.2)
+var _cacheableStatuses = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+const (
+	// The header reporting how a response was served
+	HEADER_CACHE = "X-Cache"
+	CACHE_HIT    = "HIT"
+	CACHE_MISS   = "MISS"
+	CACHE_STALE  = "STALE"
+	CACHE_BYPASS = "BYPASS"
+)
+
+// The number of responses kept in memory by default
+const _defaultCapacity = 10000
+
+// The StaleEvent struct describes a stale response served because the
+// upstream failed
+// Params:
+//   - Age: How long ago the stale response was stored
+//   - Status: The status returned by the upstream, or 0 if it panicked
+type StaleEvent struct {
+	Hash   string
+	URL    string
+	Age    time.Duration
+	Status int
+}
+
+// The StaleHook interface is notified about stale responses
+type StaleHook interface {
+	OnStale(event StaleEvent)
+}
+
+// The PanicEvent struct describes a panic of the upstream, which is
+// answered with a generic 500 response
+// Params:
+//   - Value: The value the upstream panicked with
+type PanicEvent struct {
+	Hash  string
+	URL   string
+	Value any
+}
+
+// The PanicHook interface is notified about panics of the upstream,
+// for example to log them
+type PanicHook interface {
+	OnPanic(event PanicEvent)
+}
+
+// The CachingHandler struct caches the responses of an upstream
+// handler for GET requests according to the policy of the matching
+// route, and serves HEAD requests from them. Requests of both methods
+// are matched against the routes registered for GET and for any
+// method (see gtr.RouteTable.FindMethod). Responses are stored for the
+// TTL of the policy.
+// Expired responses are served for up to the stale-while-revalidate
+// period of the policy or of the response while they are refreshed in
+// the background, and when the upstream fails (5xx or panic) for up to
+// the stale-if-error period (RFC 5861). Routes with an error budget
+// only serve stale responses on error once the upstream error rate
+// exceeds the budget. Concurrent requests missing the same cache key
+// share a single upstream request.
+// Responses are only stored and shared between requests if they may be
+// served to other clients (see storable), and responses listing request
+// headers in `Vary` are only served to requests with the same values of
+// these headers.
+type CachingHandler struct {
+	rt       *gtr.RouteTable
+	upstream http.Handler
+	store    CacheStore
+	hooks    []StaleHook
+	panics   []PanicHook
+	mutex    sync.Mutex
+	budgets  map[string]*gtr.ErrorBudget
+	// The upstream requests in progress, keyed by method and cache key
+	flights   map[string]*flight
+	refreshes sync.WaitGroup
+	now       func() time.Time
+}
+
+// The flight struct is an upstream request shared by the requests
+// missing the same cache key. The response is set before done is
+// closed, and is only handed to the other requests if it is shared.
+type flight struct {
+	done     chan struct{}
+	response *entry
+	shared   bool
+}
+
+// An Option configures a caching handler created through
+// NewCachingHandler
+type Option func(handler *CachingHandler)
+
+// Creates a caching handler in front of an upstream handler. Responses
+// are stored under the cache keys of their routes (see
+// gtr.RouteTable.CacheKey), that is the route hash and the values of
+// the route parameters, in an in-process LRU store unless another
+// store is given (see WithCapacity and WithStore).
+func NewCachingHandler(rt *gtr.RouteTable, upstream http.Handler, opts ...Option) *CachingHandler {
+	handler := &CachingHandler{
+		rt:       rt,
+		upstream: upstream,
+		store:    NewLRUStore(_defaultCapacity),
+		budgets:  make(map[string]*gtr.ErrorBudget),
+		flights:  make(map[string]*flight),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
+}
+
+// Sets the number of responses kept in memory
+func WithCapacity(entries int) Option {
+	return func(handler *CachingHandler) {
+		handler.store = NewLRUStore(entries)
+	}
+}
+
+// Sets the store of the responses, for example a store shared by
+// several nodes. Responses are kept for their TTL and the longer of
+// their stale-if-error and stale-while-revalidate periods. Errors of
+// the store are treated as cache misses, so that an unavailable store
+// never fails requests.
+func WithStore(store CacheStore) Option {
+	return func(handler *CachingHandler) {
+		handler.store = store
+	}
+}
+
+// Adds a hook notified about stale responses
+func (handler *CachingHandler) AddStaleHook(hook StaleHook) {
+	handler.hooks = append(handler.hooks, hook)
+}
+
+// Adds a hook notified about panics of the upstream
+func (handler *CachingHandler) AddPanicHook(hook PanicHook) {
+	handler.panics = append(handler.panics, hook)
+}
+
+func (handler *CachingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		handler.upstream.ServeHTTP(w, r)
+		return
+	}
+	// HEAD requests are served from the responses of GET requests, so
+	// they are matched like GET requests
+	target := gtr.RequestURL(r)
+	hash, err := handler.rt.FindMethod(http.MethodGet, target)
+	if err != nil {
+		handler.upstream.ServeHTTP(w, r)
+		return
+	}
+	policy := handler.rt.GetPolicy(hash, r.Method)
+	ttl, ok := policy.TTL()
+	if !ok || ttl <= 0 || policy.Bypass() {
+		w.Header().Set(HEADER_CACHE, CACHE_BYPASS)
+		handler.upstream.ServeHTTP(w, r)
+		return
+	}
+	key, err := handler.rt.CacheKeyMethod(http.MethodGet, target)
+	if err != nil {
+		handler.upstream.ServeHTTP(w, r)
+		return
+	}
+	// Only the responses of GET requests are stored, and HEAD requests
+	// are served from them
+	now := handler.now()
+	entry, found := handler.load(key, r)
+	if found && now.Before(entry.stored.Add(entry.ttl)) {
+		entry.write(w, r.Method, CACHE_HIT, now)
+		return
+	}
+	if found && entry.revalidatable(now, policy) {
+		entry.write(w, r.Method, CACHE_STALE, now)
+		handler.revalidate(key, hash, r)
+		return
+	}
+
+	call, leader := handler.join(r.Method, key)
+	response := handler.await(call, hash, leader, r)
+	failed := response.status == 0 || response.status >= http.StatusInternalServerError
+	budget := handler.budget(hash, policy)
+	if leader {
+		if budget != nil {
+			budget.Record(failed)
+		}
+		shared := !response.aborted && storable(r, response)
+		// The response is stored before the flight lands, so that later
+		// requests find it rather than calling the upstream again
+		if shared && !failed && _cacheableStatuses[response.status] && r.Method == http.MethodGet {
+			response.stored = now
+			response.ttl = ttl
+			handler.save(key, response, policy)
+		}
+		handler.land(r.Method, key, call, response, shared)
+	}
+	// The upstream aborted the response, which is left to the server
+	// once the requests that joined the flight are released
+	if response.aborted {
+		panic(http.ErrAbortHandler)
+	}
+	if failed && found && entry.servableOnError(now, policy) && (budget == nil || budget.Exhausted()) {
+		entry.write(w, r.Method, CACHE_STALE, now)
+		for _, hook := range handler.hooks {
+			hook.OnStale(StaleEvent{Hash: hash, URL: r.URL.String(), Age: now.Sub(entry.stored), Status: response.status})
+		}
+		return
+	}
+	response.write(w, r.Method, CACHE_MISS, now)
+}
+
+// Gets the error budget of a route, if its policy has one
+func (handler *CachingHandler) budget(hash string, policy gtr.Policy) *gtr.ErrorBudget {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	budget, ok := handler.budgets[hash]
+	if !ok {
+		budget = gtr.NewErrorBudget(policy)
+		handler.budgets[hash] = budget
+	}
+	return budget
+}
+
+// Waits for the responses being refreshed in the background, for
+// example before shutting down
+func (handler *CachingHandler) Wait() {
+	handler.refreshes.Wait()
+}
+
+// Joins the upstream request in progress for a cache key, or starts
+// one. Returns whether the caller started the request and so must
+// land it.
+func (handler *CachingHandler) join(method string, key string) (*flight, bool) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	if call, ok := handler.flights[method+" "+key]; ok {
+		return call, false
+	}
+	call := &flight{done: make(chan struct{})}
+	handler.flights[method+" "+key] = call
+	return call, true
+}
+
+// Gets the response of an upstream request, calling the upstream if the
+// caller started the request or if the response may not be shared
+func (handler *CachingHandler) await(call *flight, hash string, leader bool, r *http.Request) *entry {
+	if !leader {
+		<-call.done
+		if call.shared && call.response.matches(r) {
+			return call.response
+		}
+	}
+	return handler.fetch(hash, r)
+}
+
+// Completes an upstream request, handing its response to the requests
+// that joined it. Requests joining a flight whose response is not
+// shared call the upstream themselves.
+func (handler *CachingHandler) land(method string, key string, call *flight, response *entry, shared bool) {
+	handler.mutex.Lock()
+	delete(handler.flights, method+" "+key)
+	handler.mutex.Unlock()
+	call.response = response
+	call.shared = shared
+	close(call.done)
+}
+
+// Refreshes a stored response in the background through a GET request,
+// unless it is already being requested
+func (handler *CachingHandler) revalidate(key string, hash string, r *http.Request) {
+	call, leader := handler.join(http.MethodGet, key)
+	if !leader {
+		return
+	}
+	// The refresh outlives the request it was triggered by
+	request := r.Clone(context.Background())
+	request.Method = http.MethodGet
+	policy := handler.rt.GetPolicy(hash, http.MethodGet)
+	ttl, ok := policy.TTL()
+	handler.refreshes.Add(1)
+	go func() {
+		defer handler.refreshes.Done()
+		response := handler.fetch(hash, request)
+		failed := response.status == 0 || response.status >= http.StatusInternalServerError
+		if budget := handler.budget(hash, policy); budget != nil {
+			budget.Record(failed)
+		}
+		shared := !response.aborted && storable(request, response)
+		if shared && ok && ttl > 0 && !policy.Bypass() && !failed && _cacheableStatuses[response.status] {
+			response.stored = handler.now()
+			response.ttl = ttl
+			handler.save(key, response, policy)
+		}
+		handler.land(http.MethodGet, key, call, response, shared)
+	}()
+}
+
+// Gets the stored response of a request, if any
+func (handler *CachingHandler) load(key string, r *http.Request) (*entry, bool) {
+	data, ok, err := handler.store.Get(key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	entry, err := decodeEntry(data)
+	if err != nil || !entry.matches(r) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Checks whether the response of a request may be stored and served to
+// other clients (RFC 9111, 3). Responses marked `no-store` or `private`,
+// responses setting cookies, and responses varying on `*` are never
+// stored. Responses of requests carrying credentials are only stored
+// if they are marked `public`. The values of the request headers the
+// response varies on are recorded, so that the response is only served
+// to requests with the same values.
+func storable(r *http.Request, response *entry) bool {
+	if hasDirective(response.header, "no-store") || hasDirective(response.header, "private") {
+		return false
+	}
+	if len(response.header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	if len(r.Header.Values("Authorization")) > 0 && !hasDirective(response.header, "public") {
+		return false
+	}
+	response.vary = make(map[string]string)
+	for _, value := range response.header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return false
+			}
+			if len(name) > 0 {
+				name = http.CanonicalHeaderKey(name)
+				response.vary[name] = strings.Join(r.Header.Values(name), ",")
+			}
+		}
+	}
+	return true
+}
+
+// Checks whether a Cache-Control directive is set, with or without
+// arguments
+func hasDirective(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if strings.EqualFold(name, directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Stores a response for as long as it may be served
+func (handler *CachingHandler) save(key string, entry *entry, policy gtr.Policy) {
+	data, err := entry.encode()
+	if err != nil {
+		return
+	}
+	stale, _ := entry.staleIfError(policy)
+	if revalidate, ok := entry.staleWhileRevalidate(policy); ok && revalidate > stale {
+		stale = revalidate
+	}
+	retention := entry.ttl + stale
+	handler.store.Set(key, data, retention)
+}
+
+// Calls the upstream and records its response. A panicking upstream
+// is recorded as a response with status 0 and a generic body, since
+// the value it panicked with may hold internal data, and the value is
+// handed to the panic hooks instead. Panics with http.ErrAbortHandler
+// are recorded as aborted rather than reported.
+func (handler *CachingHandler) fetch(hash string, r *http.Request) (response *entry) {
+	recorder := newRecorder()
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		response = &entry{header: http.Header{}, body: []byte(http.StatusText(http.StatusInternalServerError))}
+		if recovered == http.ErrAbortHandler {
+			response.aborted = true
+			return
+		}
+		for _, hook := range handler.panics {
+			hook.OnPanic(PanicEvent{Hash: hash, URL: r.URL.String(), Value: recovered})
+		}
+	}()
+	handler.upstream.ServeHTTP(recorder, r)
+	return recorder.entry()
+}
+
+// The entry struct is a stored response
+type entry struct {
+	status int
+	header http.Header
+	body   []byte
+	stored time.Time
+	ttl    time.Duration
+	// The values of the request headers the response varies on
+	vary map[string]string
+	// Whether the upstream panicked with http.ErrAbortHandler
+	aborted bool
+}
+
+// Checks whether a request has the values of the request headers the
+// response varies on
+func (entry *entry) matches(r *http.Request) bool {
+	for name, value := range entry.vary {
+		if strings.Join(r.Header.Values(name), ",") != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks whether an expired entry may be served because the upstream
+// failed. The stale-if-error period of the policy takes precedence
+// over the one of the response.
+func (entry *entry) servableOnError(now time.Time, policy gtr.Policy) bool {
+	stale, ok := entry.staleIfError(policy)
+	return ok && now.Before(entry.stored.Add(entry.ttl+stale))
+}
+
+// Checks whether an expired entry may be served while it is refreshed
+// in the background. The stale-while-revalidate period of the policy
+// takes precedence over the one of the response.
+func (entry *entry) revalidatable(now time.Time, policy gtr.Policy) bool {
+	stale, ok := entry.staleWhileRevalidate(policy)
+	return ok && now.Before(entry.stored.Add(entry.ttl+stale))
+}
+
+// Gets how long an expired entry may be served while it is refreshed
+func (entry *entry) staleWhileRevalidate(policy gtr.Policy) (time.Duration, bool) {
+	if stale, ok := policy.StaleWhileRevalidate(); ok {
+		return stale, true
+	}
+	return cacheControlSeconds(entry.header.Get("Cache-Control"), "stale-while-revalidate")
+}
+
+// Gets how long an expired entry may be served because the upstream
+// failed
+func (entry *entry) staleIfError(policy gtr.Policy) (time.Duration, bool) {
+	if stale, ok := policy.StaleIfError(); ok {
+		return stale, true
+	}
+	return cacheControlSeconds(entry.header.Get("Cache-Control"), "stale-if-error")
+}
+
+func (entry *entry) write(w http.ResponseWriter, method string, state string, now time.Time) {
+	header := w.Header()
+	for key, values := range entry.header {
+		header[key] = append([]string(nil), values...)
+	}
+	header.Set(HEADER_CACHE, state)
+	if state != CACHE_MISS {
+		header.Set("Age", strconv.Itoa(int(now.Sub(entry.stored).Seconds())))
+	}
+	status := entry.status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.WriteHeader(status)
+	if method != http.MethodHead {
+		w.Write(entry.body)
+	}
+}
+
+// Gets the seconds of a Cache-Control directive such as `stale-if-error=60`
+func cacheControlSeconds(cacheControl string, directive string) (time.Duration, bool) {
+	for _, part := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.EqualFold(name, directive) {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
+
+// The recorder struct captures the response of the upstream
+type recorder struct {
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}}
+}
+
+func (recorder *recorder) Header() http.Header {
+	return recorder.header
+}
+
+func (recorder *recorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+}
+
+func (recorder *recorder) Write(data []byte) (int, error) {
+	recorder.WriteHeader(http.StatusOK)
+	return recorder.body.Write(data)
+}
+
+func (recorder *recorder) entry() *entry {
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &entry{status: status, header: recorder.header, body: recorder.body.Bytes()}
+}
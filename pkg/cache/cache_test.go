@@ -0,0 +1,464 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+type recordingHook struct {
+	events []StaleEvent
+}
+
+func (hook *recordingHook) OnStale(event StaleEvent) {
+	hook.events = append(hook.events, event)
+}
+
+type recordingPanicHook struct {
+	events []PanicEvent
+}
+
+func (hook *recordingPanicHook) OnPanic(event PanicEvent) {
+	hook.events = append(hook.events, event)
+}
+
+func PrepareHandler(t *testing.T, conf map[string]any, upstream http.Handler) (*CachingHandler, *time.Time) {
+	rt := gtr.NewMux().Table()
+	template, err := url.Parse("/api/users/:id")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(template, conf); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	now := time.Now()
+	handler := NewCachingHandler(rt, upstream)
+	handler.now = func() time.Time { return now }
+	return handler, &now
+}
+
+func Serve(handler http.Handler, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+func TestCachingHandler(t *testing.T) {
+	calls := 0
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(r.URL.Path))
+	}))
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || response.Body.String() != "/api/users/1" {
+		t.Logf("unexpected response %s %s", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+	*now = now.Add(30 * time.Second)
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_HIT || response.Header().Get("Age") != "30" || calls != 1 {
+		t.Logf("expected a cache hit but found %s after %d calls", response.Header().Get(HEADER_CACHE), calls)
+		t.FailNow()
+	}
+	*now = now.Add(time.Minute)
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || calls != 2 {
+		t.Log("expected the expired entry to be refreshed")
+		t.FailNow()
+	}
+}
+
+func TestCachingHandlerHead(t *testing.T) {
+	calls := 0
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/plain")
+		if r.Method != http.MethodHead {
+			w.Write([]byte("body"))
+		}
+	}))
+	head := httptest.NewRecorder()
+	handler.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/api/users/1", nil))
+	if head.Header().Get(HEADER_CACHE) != CACHE_MISS || head.Body.Len() != 0 {
+		t.Logf("unexpected HEAD response %s %q", head.Header().Get(HEADER_CACHE), head.Body.String())
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || response.Body.String() != "body" {
+		t.Logf("expected the HEAD response not to be served to GET but found %s %q", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+	head = httptest.NewRecorder()
+	handler.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/api/users/1", nil))
+	if head.Header().Get(HEADER_CACHE) != CACHE_HIT || head.Header().Get("Content-Type") != "text/plain" || head.Body.Len() != 0 || calls != 2 {
+		t.Logf("expected HEAD to be served from the GET response but found %s %q", head.Header().Get(HEADER_CACHE), head.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestCachingHandlerMethodRoute(t *testing.T) {
+	calls := 0
+	rt := gtr.NewMux().Table()
+	template, _ := url.Parse("/api/posts/:id")
+	if err := rt.RegisterMethod(http.MethodGet, template, map[string]any{"ttl": "1m"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	handler := NewCachingHandler(rt, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(r.URL.Path))
+	}))
+	Serve(handler, "/api/posts/1")
+	if response := Serve(handler, "/api/posts/1"); response.Header().Get(HEADER_CACHE) != CACHE_HIT || calls != 1 {
+		t.Logf("expected the GET route to be cached but found %s after %d calls", response.Header().Get(HEADER_CACHE), calls)
+		t.FailNow()
+	}
+	if err := handler.Invalidate("/api/posts/:id", map[string]string{"id": "1"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/posts/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || calls != 2 {
+		t.Log("expected the response of the GET route to be invalidated")
+		t.FailNow()
+	}
+}
+
+func TestCachingHandlerPrivateResponses(t *testing.T) {
+	cases := []struct {
+		name          string
+		header        http.Header
+		authorization string
+		stored        bool
+	}{
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}},
+		{name: "private", header: http.Header{"Cache-Control": {"max-age=60, private"}}},
+		{name: "cookie", header: http.Header{"Set-Cookie": {"session=user1"}}},
+		{name: "authorization", authorization: "Bearer alice"},
+		{name: "public authorization", header: http.Header{"Cache-Control": {"public"}}, authorization: "Bearer alice", stored: true},
+		{name: "vary all", header: http.Header{"Vary": {"*"}}},
+	}
+	for _, c := range cases {
+		calls := 0
+		handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			// Only the response of the first client is private
+			if calls == 1 {
+				for key, values := range c.header {
+					w.Header()[key] = values
+				}
+			}
+			w.Write([]byte("secret for " + r.Header.Get("Authorization")))
+		}))
+		request := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+		if len(c.authorization) > 0 {
+			request.Header.Set("Authorization", c.authorization)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		response := Serve(handler, "/api/users/1")
+		if hit := response.Header().Get(HEADER_CACHE) == CACHE_HIT; hit != c.stored {
+			t.Logf("%s: expected stored to be %v but found %s after %d calls", c.name, c.stored, response.Header().Get(HEADER_CACHE), calls)
+			t.FailNow()
+		}
+		if !c.stored && (response.Body.String() != "secret for " || response.Header().Get("Set-Cookie") != "") {
+			t.Logf("%s: expected the response of another client not to be served but found %q", c.name, response.Body.String())
+			t.FailNow()
+		}
+	}
+}
+
+func TestCachingHandlerVary(t *testing.T) {
+	calls := 0
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	serve := func(language string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+		request.Header.Set("Accept-Language", language)
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	serve("en")
+	if response := serve("en"); response.Header().Get(HEADER_CACHE) != CACHE_HIT || response.Body.String() != "en" {
+		t.Logf("expected a cache hit for the same language but found %s %q", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+	if response := serve("fr"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || response.Body.String() != "fr" || calls != 2 {
+		t.Logf("expected a cache miss for another language but found %s %q", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestCachingHandlerOpenAPI(t *testing.T) {
+	calls := 0
+	rt := gtr.NewMux().Table()
+	doc := `{"paths": {"/api/posts/{id}": {"get": {"x-cache-ttl": "1m"}, "delete": {}}}}`
+	if err := rt.ImportOpenAPI([]byte(doc)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	handler := NewCachingHandler(rt, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	Serve(handler, "/api/posts/1")
+	if response := Serve(handler, "/api/posts/1"); response.Header().Get(HEADER_CACHE) != CACHE_HIT || calls != 1 {
+		t.Logf("expected the imported GET operation to be cached but found %s after %d calls", response.Header().Get(HEADER_CACHE), calls)
+		t.FailNow()
+	}
+}
+
+func TestStaleIfError(t *testing.T) {
+	failing := false
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m", "stale_if_error": "1h"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			panic("upstream down")
+		}
+		w.Write([]byte("fresh"))
+	}))
+	hook := recordingHook{}
+	handler.AddStaleHook(&hook)
+	Serve(handler, "/api/users/1")
+	failing = true
+	*now = now.Add(30 * time.Minute)
+	response := Serve(handler, "/api/users/1")
+	if response.Code != http.StatusOK || response.Header().Get(HEADER_CACHE) != CACHE_STALE || response.Body.String() != "fresh" {
+		t.Logf("expected a stale response but found %d %s", response.Code, response.Header().Get(HEADER_CACHE))
+		t.FailNow()
+	}
+	if len(hook.events) != 1 || hook.events[0].Age != 30*time.Minute || hook.events[0].Status != 0 {
+		t.Logf("unexpected stale events %v", hook.events)
+		t.FailNow()
+	}
+	*now = now.Add(time.Hour)
+	if response := Serve(handler, "/api/users/1"); response.Code != http.StatusInternalServerError {
+		t.Logf("expected the upstream error past the stale period but found %d", response.Code)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/2"); response.Code != http.StatusInternalServerError {
+		t.Logf("expected the upstream error without a cached entry but found %d", response.Code)
+		t.FailNow()
+	}
+}
+
+func TestUpstreamPanic(t *testing.T) {
+	value := any("db password=hunter2")
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(value)
+	}))
+	hook := recordingPanicHook{}
+	handler.AddPanicHook(&hook)
+	response := Serve(handler, "/api/users/1")
+	if response.Code != http.StatusInternalServerError || response.Body.String() != http.StatusText(http.StatusInternalServerError) {
+		t.Logf("expected a generic error but found %d %q", response.Code, response.Body.String())
+		t.FailNow()
+	}
+	if len(hook.events) != 1 || hook.events[0].Value != value || hook.events[0].URL != "/api/users/1" {
+		t.Logf("unexpected panic events %v", hook.events)
+		t.FailNow()
+	}
+
+	value = http.ErrAbortHandler
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler || len(hook.events) != 1 {
+			t.Logf("expected http.ErrAbortHandler to be panicked again but found %v", recovered)
+			t.FailNow()
+		}
+	}()
+	Serve(handler, "/api/users/1")
+	t.Log("expected http.ErrAbortHandler to be panicked again")
+	t.FailNow()
+}
+
+func TestStaleIfErrorDirective(t *testing.T) {
+	status := http.StatusOK
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60, stale-if-error=600")
+		w.WriteHeader(status)
+	}))
+	Serve(handler, "/api/users/1")
+	status = http.StatusBadGateway
+	*now = now.Add(5 * time.Minute)
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_STALE {
+		t.Logf("expected a stale response but found %d", response.Code)
+		t.FailNow()
+	}
+	*now = now.Add(10 * time.Minute)
+	if response := Serve(handler, "/api/users/1"); response.Code != http.StatusBadGateway {
+		t.Logf("expected the upstream error but found %d", response.Code)
+		t.FailNow()
+	}
+}
+
+func TestStaleIfErrorBudget(t *testing.T) {
+	status := http.StatusOK
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m", "stale_if_error": "1h", "error_budget": 0.5, "error_min_requests": 3}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	Serve(handler, "/api/users/1")
+	*now = now.Add(2 * time.Minute)
+	status = http.StatusServiceUnavailable
+	if response := Serve(handler, "/api/users/1"); response.Code != http.StatusServiceUnavailable {
+		t.Logf("expected the upstream error within the budget but found %d", response.Code)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/1"); response.Code != http.StatusOK || response.Header().Get(HEADER_CACHE) != CACHE_STALE {
+		t.Logf("expected a stale response once the budget is exhausted but found %d", response.Code)
+		t.FailNow()
+	}
+}
+
+func TestCachingHandlerBypass(t *testing.T) {
+	calls := 0
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m", "bypass": true}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	Serve(handler, "/api/users/1")
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_BYPASS || calls != 2 {
+		t.Log("expected the cache to be bypassed")
+		t.FailNow()
+	}
+}
+
+// A store counting the lookups of cache keys
+type countingStore struct {
+	*LRUStore
+	gets int32
+}
+
+func (store *countingStore) Get(key string) ([]byte, bool, error) {
+	atomic.AddInt32(&store.gets, 1)
+	return store.LRUStore.Get(key)
+}
+
+func TestCachingHandlerSharesMisses(t *testing.T) {
+	calls := int32(0)
+	release := make(chan struct{})
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte("shared"))
+	}))
+	store := &countingStore{LRUStore: NewLRUStore(10)}
+	handler.store = store
+	responses := make([]*httptest.ResponseRecorder, 5)
+	wg := sync.WaitGroup{}
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = Serve(handler, "/api/users/1")
+		}(i)
+	}
+	// The upstream is released once every request missed the cache
+	for atomic.LoadInt32(&store.gets) < int32(len(responses)) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Logf("expected a single upstream request but found %d", calls)
+		t.FailNow()
+	}
+	for _, response := range responses {
+		if response.Header().Get(HEADER_CACHE) != CACHE_MISS || response.Body.String() != "shared" {
+			t.Logf("unexpected shared response %s %q", response.Header().Get(HEADER_CACHE), response.Body.String())
+			t.FailNow()
+		}
+	}
+}
+
+func TestCachingHandlerPrivateMisses(t *testing.T) {
+	calls := int32(0)
+	release := make(chan struct{})
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Cache-Control", "private")
+		w.Write([]byte(strconv.Itoa(int(call))))
+	}))
+	store := &countingStore{LRUStore: NewLRUStore(10)}
+	handler.store = store
+	responses := make([]*httptest.ResponseRecorder, 2)
+	wg := sync.WaitGroup{}
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = Serve(handler, "/api/users/1")
+		}(i)
+	}
+	for atomic.LoadInt32(&store.gets) < int32(len(responses)) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if calls := atomic.LoadInt32(&calls); calls != 2 || responses[0].Body.String() == responses[1].Body.String() {
+		t.Logf("expected private responses not to be shared but found %d upstream requests", calls)
+		t.FailNow()
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	calls := int32(0)
+	release := make(chan struct{})
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m", "stale_while_revalidate": "10m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			<-release
+		}
+		fmt.Fprintf(w, "v%d", atomic.LoadInt32(&calls))
+	}))
+	Serve(handler, "/api/users/1")
+	*now = now.Add(5 * time.Minute)
+	for i := 0; i < 5; i++ {
+		response := Serve(handler, "/api/users/1")
+		if response.Header().Get(HEADER_CACHE) != CACHE_STALE || response.Body.String() != "v1" {
+			t.Logf("expected a stale response but found %s %s", response.Header().Get(HEADER_CACHE), response.Body.String())
+			t.FailNow()
+		}
+	}
+	close(release)
+	handler.Wait()
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Logf("expected a single refresh but found %d", calls-1)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_HIT || response.Body.String() != "v2" {
+		t.Logf("expected the refreshed response but found %s %s", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+	*now = now.Add(time.Hour)
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS {
+		t.Logf("expected a miss past the stale period but found %s", response.Header().Get(HEADER_CACHE))
+		t.FailNow()
+	}
+}
+
+func TestStaleWhileRevalidateDirective(t *testing.T) {
+	status := http.StatusOK
+	handler, now := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=600")
+		w.WriteHeader(status)
+		w.Write([]byte(strconv.Itoa(status)))
+	}))
+	Serve(handler, "/api/users/1")
+	status = http.StatusBadGateway
+	*now = now.Add(5 * time.Minute)
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_STALE {
+		t.Logf("expected a stale response but found %s", response.Header().Get(HEADER_CACHE))
+		t.FailNow()
+	}
+	handler.Wait()
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_STALE || response.Body.String() != "200" {
+		t.Logf("expected the failed refresh to keep the stale response but found %s", response.Body.String())
+		t.FailNow()
+	}
+	handler.Wait()
+}
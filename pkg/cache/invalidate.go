@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// Purges the stored response of the URL built from a route template
+// and the values of its parameters (see gtr.Route.Build), for example
+// `/users/:username/details` and {"username": "ken"}. Responses of the
+// URL with query params that are part of the cache key are left to
+// expire, see InvalidateAll.
+func (handler *CachingHandler) Invalidate(template string, params map[string]string) error {
+	route, err := handler.route(template)
+	if err != nil {
+		return err
+	}
+	url, err := route.Build(params, nil)
+	if err != nil {
+		return err
+	}
+	key, err := handler.rt.CacheKeyMethod(http.MethodGet, url)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	return handler.store.Delete(key)
+}
+
+// Purges every stored response of a route template by bumping the key
+// version of the route (see gtr.RouteTable.BumpKeyVersion), since
+// stores cannot list the keys of a route. The previous responses are
+// no longer served and expire with their TTL. The route table must not
+// be frozen.
+func (handler *CachingHandler) InvalidateAll(template string) error {
+	route, err := handler.route(template)
+	if err != nil {
+		return err
+	}
+	_, err = handler.rt.BumpKeyVersion(route.Hash())
+	return err
+}
+
+// Gets the route of a template whose responses are stored, that is the
+// route registered for GET requests, or else for any method
+func (handler *CachingHandler) route(template string) (*gtr.Route, error) {
+	route, err := handler.rt.LookupTemplateMethod(http.MethodGet, template)
+	if errors.Is(err, gtr.HASH_NOT_REGISTERED) {
+		return handler.rt.LookupTemplate(template)
+	}
+	return route, err
+}
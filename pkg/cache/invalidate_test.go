@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+func TestInvalidate(t *testing.T) {
+	calls := 0
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	Serve(handler, "/api/users/1")
+	Serve(handler, "/api/users/2")
+	if err := handler.Invalidate("/api/users/:id", map[string]string{"id": "1"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_MISS || calls != 3 {
+		t.Log("expected the invalidated response to be purged")
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/2"); response.Header().Get(HEADER_CACHE) != CACHE_HIT {
+		t.Log("expected the other responses to be kept")
+		t.FailNow()
+	}
+	if err := handler.Invalidate("/api/posts/:id", nil); !errors.Is(err, gtr.HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if err := handler.Invalidate("/api/users/:id", map[string]string{"name": "ken"}); !errors.Is(err, gtr.UNKNOWN_PARAMETER) {
+		t.Logf("expected UNKNOWN_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestInvalidateAll(t *testing.T) {
+	calls := 0
+	handler, _ := PrepareHandler(t, map[string]any{"ttl": "1m"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	Serve(handler, "/api/users/1")
+	Serve(handler, "/api/users/2?page=1")
+	if err := handler.InvalidateAll("/api/users/:id"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	for _, path := range []string{"/api/users/1", "/api/users/2?page=1"} {
+		if response := Serve(handler, path); response.Header().Get(HEADER_CACHE) != CACHE_MISS {
+			t.Logf("expected %s to be purged", path)
+			t.FailNow()
+		}
+	}
+	if calls != 4 {
+		t.Logf("expected 4 upstream calls but found %d", calls)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// The RedisOptions struct configures a Redis store
+// Params:
+//   - Password: Authenticates the connections through AUTH, if set
+//   - DB: The database selected through SELECT
+//   - Prefix: Prepended to every key, so that several caches may share
+//     a database
+//   - PoolSize: The number of idle connections kept open (8 if zero)
+//   - Timeout: The timeout of dialing and of every command (5 seconds
+//     if zero)
+type RedisOptions struct {
+	Password string
+	DB       int
+	Prefix   string
+	PoolSize int
+	Timeout  time.Duration
+}
+
+// The RedisStore struct stores values in Redis, so that the caching
+// handlers of several nodes share their responses. It speaks the Redis
+// protocol (RESP) itself and needs no client library.
+type RedisStore struct {
+	addr    string
+	options RedisOptions
+	idle    chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// The error replied by Redis to a command
+type redisError string
+
+func (err redisError) Error() string {
+	return "redis: " + string(err)
+}
+
+// Creates a store backed by the Redis server listening on an address,
+// for example `localhost:6379`. Connections are opened on demand.
+func NewRedisStore(addr string, options RedisOptions) *RedisStore {
+	if options.PoolSize <= 0 {
+		options.PoolSize = 8
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Second
+	}
+	return &RedisStore{
+		addr:    addr,
+		options: options,
+		idle:    make(chan *redisConn, options.PoolSize),
+	}
+}
+
+func (store *RedisStore) Get(key string) ([]byte, bool, error) {
+	reply, err := store.do("GET", store.options.Prefix+key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected reply %v", reply)
+	}
+	return value, true, nil
+}
+
+func (store *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", store.options.Prefix + key, value}
+	if ttl > 0 {
+		milliseconds := ttl.Milliseconds()
+		if milliseconds == 0 {
+			milliseconds = 1
+		}
+		args = append(args, "PX", strconv.FormatInt(milliseconds, 10))
+	}
+	_, err := store.do(args...)
+	return err
+}
+
+func (store *RedisStore) Delete(key string) error {
+	_, err := store.do("DEL", store.options.Prefix+key)
+	return err
+}
+
+// Closes the idle connections of the store
+func (store *RedisStore) Close() error {
+	for {
+		select {
+		case conn := <-store.idle:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Sends a command and reads its reply. Connections are only reused
+// once their reply has been read in full.
+func (store *RedisStore) do(args ...any) (any, error) {
+	conn, err := store.acquire()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(store.options.Timeout, args...)
+	var replied redisError
+	if err != nil && !errors.As(err, &replied) {
+		conn.conn.Close()
+		return nil, err
+	}
+	store.release(conn)
+	return reply, err
+}
+
+func (store *RedisStore) acquire() (*redisConn, error) {
+	select {
+	case conn := <-store.idle:
+		return conn, nil
+	default:
+	}
+	conn, err := net.DialTimeout("tcp", store.addr, store.options.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	redis := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if len(store.options.Password) > 0 {
+		if _, err := redis.do(store.options.Timeout, "AUTH", store.options.Password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if store.options.DB != 0 {
+		if _, err := redis.do(store.options.Timeout, "SELECT", strconv.Itoa(store.options.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return redis, nil
+}
+
+func (store *RedisStore) release(conn *redisConn) {
+	select {
+	case store.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+// Writes a command as an array of bulk strings and reads its reply
+func (conn *redisConn) do(timeout time.Duration, args ...any) (any, error) {
+	conn.conn.SetDeadline(time.Now().Add(timeout))
+	buffer := make([]byte, 0, 64)
+	buffer = append(buffer, '*')
+	buffer = strconv.AppendInt(buffer, int64(len(args)), 10)
+	buffer = append(buffer, '\r', '\n')
+	for _, arg := range args {
+		var value []byte
+		switch arg := arg.(type) {
+		case string:
+			value = []byte(arg)
+		case []byte:
+			value = arg
+		}
+		buffer = append(buffer, '$')
+		buffer = strconv.AppendInt(buffer, int64(len(value)), 10)
+		buffer = append(buffer, '\r', '\n')
+		buffer = append(buffer, value...)
+		buffer = append(buffer, '\r', '\n')
+	}
+	if _, err := conn.conn.Write(buffer); err != nil {
+		return nil, err
+	}
+	return readReply(conn.reader)
+}
+
+// Reads a reply of the Redis protocol. Bulk strings are read as byte
+// slices, and nil bulk strings and arrays as nil.
+func readReply(reader *bufio.Reader) (any, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(payload)
+		if err != nil || length < 0 {
+			return nil, err
+		}
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+		return value[:length], nil
+	case '*':
+		length, err := strconv.Atoi(payload)
+		if err != nil || length < 0 {
+			return nil, err
+		}
+		values := make([]any, length)
+		for i := range values {
+			if values[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("redis: malformed reply %q", line)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Serves GET, SET, and DEL over the Redis protocol from a map
+func PrepareRedis(t *testing.T) (string, map[string]string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	t.Cleanup(func() { listener.Close() })
+	mutex := sync.Mutex{}
+	values := make(map[string]string)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					reply, err := readReply(reader)
+					if err != nil {
+						return
+					}
+					args := make([]string, 0)
+					for _, arg := range reply.([]any) {
+						args = append(args, string(arg.([]byte)))
+					}
+					mutex.Lock()
+					switch strings.ToUpper(args[0]) {
+					case "GET":
+						value, ok := values[args[1]]
+						if !ok {
+							conn.Write([]byte("$-1\r\n"))
+							break
+						}
+						conn.Write([]byte("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"))
+					case "SET":
+						values[args[1]] = value(args[2:])
+						conn.Write([]byte("+OK\r\n"))
+					case "DEL":
+						delete(values, args[1])
+						conn.Write([]byte(":1\r\n"))
+					default:
+						conn.Write([]byte("-ERR unknown command\r\n"))
+					}
+					mutex.Unlock()
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), values
+}
+
+func value(args []string) string {
+	return strings.Join(args, " ")
+}
+
+func TestRedisStore(t *testing.T) {
+	addr, values := PrepareRedis(t)
+	store := NewRedisStore(addr, RedisOptions{Prefix: "gtr:"})
+	defer store.Close()
+	if _, ok, err := store.Get("a"); ok || err != nil {
+		t.Logf("expected a miss but found %v", err)
+		t.FailNow()
+	}
+	if err := store.Set("a", []byte("1"), 1500*time.Microsecond); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if values["gtr:a"] != "1 PX 1" {
+		t.Logf("unexpected command %q", values["gtr:a"])
+		t.FailNow()
+	}
+	store.Set("a", []byte("1"), 0)
+	if value, ok, err := store.Get("a"); !ok || err != nil || string(value) != "1" {
+		t.Logf("expected the stored value but found %q %v", value, err)
+		t.FailNow()
+	}
+	store.Delete("a")
+	if _, ok, _ := store.Get("a"); ok {
+		t.Log("expected the value to be deleted")
+		t.FailNow()
+	}
+	if _, err := store.do("PING"); err == nil || err.Error() != "redis: ERR unknown command" {
+		t.Logf("expected the error reply but found %v", err)
+		t.FailNow()
+	}
+	if _, ok, err := store.Get("a"); ok || err != nil {
+		t.Logf("expected the connection to be reused after an error reply: %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The CacheStore interface stores the responses of a caching handler,
+// so that the same route table can back an in-process cache (see
+// NewLRUStore) as well as a cache shared by several nodes (see
+// NewRedisStore). Stores may drop values before their TTL.
+type CacheStore interface {
+	// Gets a stored value. The second return value is false if the key
+	// is not stored.
+	Get(key string) ([]byte, bool, error)
+	// Stores a value for a TTL, forever if the TTL is zero
+	Set(key string, value []byte, ttl time.Duration) error
+	// Removes a stored value, if any
+	Delete(key string) error
+}
+
+// The storedEntry struct is the encoding of an entry in a store
+type storedEntry struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	Stored time.Time
+	TTL    time.Duration
+	Vary   map[string]string
+}
+
+func (entry *entry) encode() ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	err := gob.NewEncoder(buffer).Encode(storedEntry{
+		Status: entry.status,
+		Header: entry.header,
+		Body:   entry.body,
+		Stored: entry.stored,
+		TTL:    entry.ttl,
+		Vary:   entry.vary,
+	})
+	return buffer.Bytes(), err
+}
+
+func decodeEntry(data []byte) (*entry, error) {
+	stored := storedEntry{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&stored); err != nil {
+		return nil, err
+	}
+	if stored.Header == nil {
+		stored.Header = http.Header{}
+	}
+	return &entry{
+		status: stored.Status,
+		header: stored.Header,
+		body:   stored.Body,
+		stored: stored.Stored,
+		ttl:    stored.TTL,
+		vary:   stored.Vary,
+	}, nil
+}
+
+// The LRUStore struct keeps values in memory, evicting the least
+// recently used value once it holds as many values as its capacity
+type LRUStore struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// The keys of the values, the most recently used first
+	order *list.List
+	now   func() time.Time
+}
+
+type lruItem struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Creates an in-process store holding up to capacity values
+func NewLRUStore(capacity int) *LRUStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (store *LRUStore) Get(key string) ([]byte, bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	element, ok := store.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := element.Value.(*lruItem)
+	if !item.expires.IsZero() && !store.now().Before(item.expires) {
+		store.remove(element)
+		return nil, false, nil
+	}
+	store.order.MoveToFront(element)
+	return item.value, true, nil
+}
+
+func (store *LRUStore) Set(key string, value []byte, ttl time.Duration) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	expires := time.Time{}
+	if ttl > 0 {
+		expires = store.now().Add(ttl)
+	}
+	if element, ok := store.entries[key]; ok {
+		item := element.Value.(*lruItem)
+		item.value, item.expires = value, expires
+		store.order.MoveToFront(element)
+		return nil
+	}
+	store.entries[key] = store.order.PushFront(&lruItem{key: key, value: value, expires: expires})
+	for store.order.Len() > store.capacity {
+		store.remove(store.order.Back())
+	}
+	return nil
+}
+
+func (store *LRUStore) Delete(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if element, ok := store.entries[key]; ok {
+		store.remove(element)
+	}
+	return nil
+}
+
+func (store *LRUStore) remove(element *list.Element) {
+	store.order.Remove(element)
+	delete(store.entries, element.Value.(*lruItem).key)
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+func TestLRUStore(t *testing.T) {
+	store := NewLRUStore(2)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	store.Set("a", []byte("a"), 0)
+	store.Set("b", []byte("b"), 0)
+	store.Get("a")
+	store.Set("c", []byte("c"), time.Minute)
+	if _, ok, _ := store.Get("b"); ok {
+		t.Log("expected the least recently used value to be evicted")
+		t.FailNow()
+	}
+	for _, key := range []string{"a", "c"} {
+		if value, ok, _ := store.Get(key); !ok || string(value) != key {
+			t.Logf("expected %s to be kept", key)
+			t.FailNow()
+		}
+	}
+	now = now.Add(time.Minute)
+	if _, ok, _ := store.Get("c"); ok {
+		t.Log("expected the value to expire after its TTL")
+		t.FailNow()
+	}
+	store.Delete("a")
+	if _, ok, _ := store.Get("a"); ok || store.order.Len() != 0 {
+		t.Log("expected the value to be deleted")
+		t.FailNow()
+	}
+}
+
+func TestWithCapacity(t *testing.T) {
+	calls := 0
+	rt := gtr.NewRouteTable()
+	template, _ := url.Parse("/api/users/:id")
+	rt.Register(template, map[string]any{"ttl": "1m"})
+	handler := NewCachingHandler(rt, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}), WithCapacity(1))
+	for _, path := range []string{"/api/users/1", "/api/users/2", "/api/users/1"} {
+		Serve(handler, path)
+	}
+	if calls != 3 {
+		t.Logf("expected the first response to be evicted but found %d calls", calls)
+		t.FailNow()
+	}
+	if response := Serve(handler, "/api/users/1"); response.Header().Get(HEADER_CACHE) != CACHE_HIT {
+		t.Log("expected the most recent response to be kept")
+		t.FailNow()
+	}
+}
+
+func TestWithStore(t *testing.T) {
+	rt := gtr.NewRouteTable()
+	template, _ := url.Parse("/api/users/:id")
+	rt.Register(template, map[string]any{"ttl": "1m", "stale_if_error": "1h"})
+	store := NewLRUStore(16)
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("shared"))
+	})
+	Serve(NewCachingHandler(rt, upstream, WithStore(store)), "/api/users/1")
+	// Another node sharing the store serves the stored response
+	response := Serve(NewCachingHandler(rt, upstream, WithStore(store)), "/api/users/1")
+	if response.Header().Get(HEADER_CACHE) != CACHE_HIT || response.Body.String() != "shared" || response.Header().Get("Content-Type") != "text/plain" {
+		t.Logf("expected the shared response but found %s %q", response.Header().Get(HEADER_CACHE), response.Body.String())
+		t.FailNow()
+	}
+	item := store.entries[store.order.Front().Value.(*lruItem).key].Value.(*lruItem)
+	if remaining := time.Until(item.expires); remaining < time.Hour || remaining > time.Hour+time.Minute {
+		t.Logf("expected the response to be kept for its TTL and stale period but found %v", remaining)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,214 @@
+package gtr
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// The Stats struct describes the state of the route table and the
+// traffic it has matched
+// Params:
+//   - Window: The length of the windows cardinalities are tracked over
+//   - Cardinality: The parameter cardinalities of every matched route,
+//     the route with the highest cardinality first. Empty unless
+//     TrackCardinality is enabled.
+//   - Bypass: The state of the emergency bypass (see SetGlobalBypass)
+//   - Groups: The lookups of every group of routes. Empty unless
+//     TrackGroups is enabled.
+type Stats struct {
+	Routes      int                `json:"routes"`
+	Window      time.Duration      `json:"window"`
+	Cardinality []RouteCardinality `json:"cardinality"`
+	Bypass      GlobalBypass       `json:"bypass"`
+	Groups      []GroupStats       `json:"groups"`
+}
+
+// The RouteCardinality struct reports the cardinality of the
+// parameters of a route
+type RouteCardinality struct {
+	Hash     string             `json:"hash"`
+	Template string             `json:"template"`
+	Matches  int                `json:"matches"`
+	Params   []ParamCardinality `json:"params"`
+}
+
+// The ParamCardinality struct reports the estimated number of distinct
+// values of a route parameter. High-cardinality parameters are the
+// main cause of poor cache hit ratios.
+// Params:
+//   - Total: Distinct values since tracking started
+//   - Current: Distinct values in the current window
+//   - History: Distinct values in each past window, the oldest first
+type ParamCardinality struct {
+	Name    string   `json:"name"`
+	Total   uint64   `json:"total"`
+	Current uint64   `json:"current"`
+	History []uint64 `json:"history"`
+}
+
+type cardinalityTracker struct {
+	mutex   sync.Mutex
+	window  time.Duration
+	windows int
+	start   time.Time
+	routes  map[*Route]*routeSketches
+	now     func() time.Time
+}
+
+type routeSketches struct {
+	matches int
+	params  map[string]*paramSketches
+}
+
+type paramSketches struct {
+	total   hyperLogLog
+	current hyperLogLog
+	history []uint64
+}
+
+// Enables or disables tracking of the number of distinct values of
+// every route parameter on lookups made through Find and Match. Each
+// parameter is tracked since tracking was enabled and per window, and
+// the estimates of the last windows are kept to show trends. A window
+// of zero or less disables tracking.
+// Params:
+//   - window: The length of a window, for example time.Hour
+//   - windows: The number of past windows kept
+func (rt *RouteTable) TrackCardinality(window time.Duration, windows int) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if window <= 0 {
+		rt.cardinality = nil
+		return
+	}
+	rt.cardinality = &cardinalityTracker{
+		window:  window,
+		windows: windows,
+		start:   time.Now(),
+		routes:  make(map[*Route]*routeSketches),
+		now:     time.Now,
+	}
+}
+
+// Gets the statistics of the route table
+func (rt *RouteTable) Stats() Stats {
+	rt.mutex.RLock()
+	stats := Stats{Routes: len(rt.index), Cardinality: make([]RouteCardinality, 0), Bypass: rt.bypass, Groups: make([]GroupStats, 0)}
+	if rt.groups != nil {
+		stats.Groups = rt.groups.report(rt.index)
+	}
+	tracker := rt.cardinality
+	rt.mutex.RUnlock()
+	if tracker == nil {
+		return stats
+	}
+	stats.Window = tracker.window
+	stats.Cardinality = tracker.report()
+	return stats
+}
+
+// Records the parameter values of a match
+func (tracker *cardinalityTracker) observe(route *Route, values map[string]string) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.advance()
+	sketches, ok := tracker.routes[route]
+	if !ok {
+		sketches = &routeSketches{params: make(map[string]*paramSketches)}
+		tracker.routes[route] = sketches
+	}
+	sketches.matches++
+	for name, value := range values {
+		param, ok := sketches.params[name]
+		if !ok {
+			param = &paramSketches{history: make([]uint64, 0, tracker.windows)}
+			sketches.params[name] = param
+		}
+		sum := hashValue(value)
+		param.total.addHash(sum)
+		param.current.addHash(sum)
+	}
+}
+
+// Drops the sketches of a route that was unregistered
+func (tracker *cardinalityTracker) forget(route *Route) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	delete(tracker.routes, route)
+}
+
+// Moves the sketches of a route to the route replacing it
+func (tracker *cardinalityTracker) replace(route *Route, replacement *Route) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if sketches, ok := tracker.routes[route]; ok {
+		delete(tracker.routes, route)
+		tracker.routes[replacement] = sketches
+	}
+}
+
+// Closes the windows that have ended. Windows without any match are
+// recorded with no distinct values.
+func (tracker *cardinalityTracker) advance() {
+	ended := int64(tracker.now().Sub(tracker.start) / tracker.window)
+	if ended <= 0 {
+		return
+	}
+	tracker.start = tracker.start.Add(time.Duration(ended) * tracker.window)
+	// Past the kept windows every closed window is empty
+	if ended > int64(tracker.windows)+1 {
+		ended = int64(tracker.windows) + 1
+	}
+	for ; ended > 0; ended-- {
+		for _, sketches := range tracker.routes {
+			for _, param := range sketches.params {
+				param.history = append(param.history, param.current.estimate())
+				if len(param.history) > tracker.windows {
+					param.history = param.history[1:]
+				}
+				param.current = hyperLogLog{}
+			}
+		}
+	}
+}
+
+func (tracker *cardinalityTracker) report() []RouteCardinality {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.advance()
+	report := make([]RouteCardinality, 0, len(tracker.routes))
+	highest := make(map[string]uint64, len(tracker.routes))
+	for route, sketches := range tracker.routes {
+		cardinality := RouteCardinality{
+			Hash:     route.hash,
+			Template: route.template,
+			Matches:  sketches.matches,
+			Params:   make([]ParamCardinality, 0, len(sketches.params)),
+		}
+		for _, name := range route.Params() {
+			param, ok := sketches.params[name]
+			if !ok {
+				continue
+			}
+			total := param.total.estimate()
+			cardinality.Params = append(cardinality.Params, ParamCardinality{
+				Name:    name,
+				Total:   total,
+				Current: param.current.estimate(),
+				History: append([]uint64(nil), param.history...),
+			})
+			if total > highest[route.hash] {
+				highest[route.hash] = total
+			}
+		}
+		report = append(report, cardinality)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if highest[report[i].Hash] != highest[report[j].Hash] {
+			return highest[report[i].Hash] > highest[report[j].Hash]
+		}
+		return report[i].Template < report[j].Template
+	})
+	return report
+}
@@ -0,0 +1,87 @@
+package gtr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCardinality(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/users/:username/posts/:id")
+	health := PrepareURLFrom(t, "http://www.abcdefg.com/health")
+	rt.Register(template, nil)
+	rt.Register(health, nil)
+	if stats := rt.Stats(); stats.Routes != 2 || len(stats.Cardinality) != 0 {
+		t.Log("expected no cardinality while tracking is disabled")
+		t.FailNow()
+	}
+	rt.TrackCardinality(time.Hour, 2)
+	now := time.Now()
+	rt.cardinality.start = now
+	rt.cardinality.now = func() time.Time { return now }
+	find := func(count int, users int) {
+		for i := 0; i < count; i++ {
+			rt.Find(PrepareURLFrom(t, fmt.Sprintf("http://www.abcdefg.com/users/user%d/posts/%d", i%users, i)))
+		}
+	}
+	find(100, 5)
+	rt.Find(health)
+	now = now.Add(time.Hour)
+	find(20, 2)
+
+	stats := rt.Stats()
+	if stats.Window != time.Hour || len(stats.Cardinality) != 2 {
+		t.Logf("unexpected stats %v", stats)
+		t.FailNow()
+	}
+	route := stats.Cardinality[0]
+	if route.Hash != CreateHash(template) || route.Matches != 120 || len(route.Params) != 2 {
+		t.Logf("unexpected route cardinality %v", route)
+		t.FailNow()
+	}
+	username, id := route.Params[0], route.Params[1]
+	if username.Name != "username" || username.Total != 5 || username.Current != 2 || len(username.History) != 1 || username.History[0] != 5 {
+		t.Logf("unexpected username cardinality %v", username)
+		t.FailNow()
+	}
+	if id.Name != "id" || id.Total < 90 || id.Total > 110 || id.Current != 20 {
+		t.Logf("unexpected id cardinality %v", id)
+		t.FailNow()
+	}
+	if len(stats.Cardinality[1].Params) != 0 {
+		t.Log("expected no params for a static route")
+		t.FailNow()
+	}
+
+	// Only the last windows are kept, and idle windows count no values
+	now = now.Add(5 * time.Hour)
+	username = rt.Stats().Cardinality[0].Params[0]
+	if len(username.History) != 2 || username.History[0] != 0 || username.History[1] != 0 || username.Current != 0 || username.Total != 5 {
+		t.Logf("unexpected history %v", username)
+		t.FailNow()
+	}
+}
+
+func TestCardinalityAfterModify(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/users/:id")
+	rt.Register(template, nil)
+	rt.TrackCardinality(time.Hour, 2)
+	rt.SampleTraffic(10)
+	rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/users/1"))
+	if err := rt.SetMethodOverlay(CreateHash(template), "POST", map[string]any{"bypass": true}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/users/2"))
+	stats := rt.Stats()
+	if len(stats.Cardinality) != 1 || stats.Cardinality[0].Matches != 2 || stats.Cardinality[0].Params[0].Total != 2 {
+		t.Logf("expected the route to be reported once with both matches but got %v", stats.Cardinality)
+		t.FailNow()
+	}
+	if traffic := rt.TrafficReport(0); len(traffic.Routes) != 1 || traffic.Routes[0].Matches != 2 {
+		t.Logf("expected the traffic of the route to be reported once but got %v", traffic.Routes)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,59 @@
+package gtr
+
+import "fmt"
+
+// The RouteClass determines the precedence of a route. When routes
+// of different classes match a URL, the route of the higher class
+// wins regardless of its rank.
+type RouteClass int
+
+const (
+	// Routes learned automatically, for example from access logs
+	CLASS_LEARNED RouteClass = iota
+	// Routes registered by tenants through RegisterFrom
+	CLASS_USER
+	// Routes registered by operators (the default)
+	CLASS_SYSTEM
+)
+
+// Gets the name of the class
+func (class RouteClass) String() string {
+	switch class {
+	case CLASS_LEARNED:
+		return "learned"
+	case CLASS_USER:
+		return "user"
+	case CLASS_SYSTEM:
+		return "system"
+	}
+	return "unknown"
+}
+
+// Sets the class of a route
+func WithClass(class RouteClass) RouteOption {
+	return func(route *Route) error {
+		route.class = class
+		return nil
+	}
+}
+
+// Gets the class of the route
+func (route *Route) Class() RouteClass {
+	return route.class
+}
+
+// Encodes the class by its name
+func (class RouteClass) MarshalText() ([]byte, error) {
+	return []byte(class.String()), nil
+}
+
+// Decodes a class from its name
+func (class *RouteClass) UnmarshalText(text []byte) error {
+	for _, candidate := range []RouteClass{CLASS_LEARNED, CLASS_USER, CLASS_SYSTEM} {
+		if candidate.String() == string(text) {
+			*class = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown class %s", INVALID_VALUE, text)
+}
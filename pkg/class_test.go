@@ -0,0 +1,30 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRouteClassPrecedence(t *testing.T) {
+	rt := newRouteTable()
+	learned, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/details")
+	user, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:id/details")
+	system, _ := url.Parse("http://www.abcdefg.com/api/v1/:resource/:id/details")
+	rt.Register(learned, nil, WithClass(CLASS_LEARNED))
+	if err := rt.RegisterFrom("tenant", user, nil, WithClass(CLASS_SYSTEM)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/details")
+	hash, _ := rt.Find(url)
+	if hash != CreateHash(user) {
+		t.Log("user route should beat a more specific learned route")
+		t.FailNow()
+	}
+	rt.Register(system, nil)
+	hash, _ = rt.Find(url)
+	if hash != CreateHash(system) {
+		t.Log("system route should beat a more specific user route")
+		t.FailNow()
+	}
+}
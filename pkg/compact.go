@@ -0,0 +1,73 @@
+package gtr
+
+import "unsafe"
+
+// The CompactReport struct describes the outcome of compacting a table
+// Params:
+//   - Routes: The number of routes left in the table
+//   - Buckets: The number of buckets left in the table
+//   - RemovedEntries: Bucket entries that were nil or no longer registered
+//   - RemovedConfigs: Configs that no longer belonged to a route
+//   - ReclaimedBytes: An estimate of the memory released by the buckets
+type CompactReport struct {
+	Routes         int `json:"routes"`
+	Buckets        int `json:"buckets"`
+	RemovedEntries int `json:"removedEntries"`
+	RemovedConfigs int `json:"removedConfigs"`
+	ReclaimedBytes int `json:"reclaimedBytes"`
+}
+
+// Rebuilds the internal indices of the route table. Buckets are
+// reallocated to their exact size, entries that are nil or no longer
+// registered are dropped, and the hash maps are recreated so that
+// memory held by deleted keys is released.
+func (rt *RouteTable) Compact() *CompactReport {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	report := CompactReport{}
+	pointer := int(unsafe.Sizeof(uintptr(0)))
+	routes := make(map[int][]*Route, len(rt.routes))
+	for segments, bucket := range rt.routes {
+		compacted := make([]*Route, 0, len(bucket))
+		for _, route := range bucket {
+			if route == nil || rt.index[route.hash] != route {
+				report.RemovedEntries++
+				continue
+			}
+			compacted = append(compacted, route)
+		}
+		report.ReclaimedBytes += (cap(bucket) - len(compacted)) * pointer
+		if len(compacted) == 0 {
+			continue
+		}
+		exact := make([]*Route, len(compacted))
+		copy(exact, compacted)
+		routes[segments] = exact
+	}
+	index := make(map[string]*Route, len(rt.index))
+	for hash, route := range rt.index {
+		index[hash] = route
+	}
+	configs := make(map[string]map[string]any, len(rt.configs))
+	for hash, conf := range rt.configs {
+		if _, ok := index[hash]; !ok {
+			report.RemovedConfigs++
+			continue
+		}
+		configs[hash] = conf
+	}
+	typed := make(map[string]any, len(rt.typed))
+	for hash, conf := range rt.typed {
+		if _, ok := index[hash]; ok {
+			typed[hash] = conf
+		}
+	}
+	rt.routes = routes
+	rt.index = index
+	rt.configs = configs
+	rt.typed = typed
+	rt.reindex()
+	report.Routes = len(index)
+	report.Buckets = len(routes)
+	return &report
+}
@@ -0,0 +1,37 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	rt := newRouteTable()
+	for i := 0; i < 10; i++ {
+		url, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/users/u%d/:id", i))
+		rt.Register(url, map[string]any{"ttl": i})
+	}
+	bucket := rt.routes[3]
+	rt.routes[3] = append(bucket, nil)
+	delete(rt.index, bucket[0].hash)
+	report := rt.Compact()
+	if report.Routes != 9 || report.Buckets != 1 || report.RemovedEntries != 2 || report.RemovedConfigs != 1 {
+		t.Logf("unexpected report %+v", report)
+		t.FailNow()
+	}
+	if report.ReclaimedBytes <= 0 {
+		t.Log("expected memory to be reclaimed")
+		t.FailNow()
+	}
+	if len(rt.routes[3]) != cap(rt.routes[3]) {
+		t.Log("bucket was not reallocated")
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/users/u5/1")
+	hash, err := rt.Find(url)
+	if err != nil || rt.GetConfig(hash)["ttl"] != 5 {
+		t.Log("compaction broke lookups")
+		t.FailNow()
+	}
+}
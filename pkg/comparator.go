@@ -0,0 +1,63 @@
+package gtr
+
+// A Comparator ranks how well a URL matches a route template. A rank
+// of 0 means no match, and higher ranks are preferred over lower ones.
+// Only templates with as many path segments as the URL are compared.
+type Comparator interface {
+	// Params:
+	//   - template: The registered route template
+	//   - route: The route parsed from the URL, which must not be retained
+	Compare(template *Route, route *Route) int
+}
+
+// The ComparatorFunc type adapts a function to the Comparator interface
+type ComparatorFunc func(template *Route, route *Route) int
+
+// Calls the function
+func (comparator ComparatorFunc) Compare(template *Route, route *Route) int {
+	return comparator(template, route)
+}
+
+// The comparator used by route tables unless another one is set
+var DefaultComparator Comparator = ComparatorFunc(RouteCompare)
+
+// Replaces the comparator used to match URLs against route templates.
+// A nil comparator restores the default one. Class precedence still
+// applies on top of the ranks returned by the comparator.
+func (rt *RouteTable) SetComparator(comparator Comparator) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.comparator = comparator
+}
+
+// Ranks a URL against a route template using the comparator of the
+// route table
+func (rt *RouteTable) rank(template *Route, route *Route) int {
+	if rt.strictQuery {
+		if _, ok := rt.unexpected(template, route); ok {
+			return 0
+		}
+	}
+	if rt.comparator != nil {
+		if !template.acceptsHost(route.host) {
+			return 0
+		}
+		return rt.comparator.Compare(template, route)
+	}
+	return RouteCompare(template, route)
+}
+
+// Gets the path segments of the route in order. Parameters are
+// written as `:name`.
+func (route *Route) Segments() []string {
+	segments := make([]string, 0, len(route.segments))
+	for _, segment := range route.segments {
+		segments = append(segments, segment.pattern())
+	}
+	return segments
+}
+
+// Gets the query params of the route
+func (route *Route) Query() map[string]string {
+	return copyMap(route.queryParams)
+}
@@ -0,0 +1,64 @@
+package gtr
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Tolerates a single mistyped literal segment
+func typoComparator(template *Route, route *Route) int {
+	if rank := RouteCompare(template, route); rank != 0 {
+		return rank
+	}
+	expected, actual := template.Segments(), route.Segments()
+	typos := 0
+	for index, segment := range expected {
+		if segment[0] != ':' && segment != actual[index] {
+			typos++
+		}
+	}
+	if typos > 1 {
+		return 0
+	}
+	return 1
+}
+
+func TestComparator(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": nil,
+	})
+	typo, _ := url.Parse("http://www.abcdefg.com/api/v1/usres/ken/details")
+	if _, err := rt.Find(typo); err == nil {
+		t.Log("expected the default comparator to reject the typo")
+		t.FailNow()
+	}
+	rt.SetComparator(ComparatorFunc(typoComparator))
+	match, err := rt.Match(typo)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Params["username"] != "ken" || match.Rank != 1 {
+		t.Logf("unexpected match %v", match)
+		t.FailNow()
+	}
+	if explanation := rt.Explain(typo); len(explanation.Selected) == 0 {
+		t.Log("expected the explanation to use the comparator")
+		t.FailNow()
+	}
+	rt.SetComparator(nil)
+	if _, err := rt.Find(typo); err == nil {
+		t.Log("expected the default comparator to be restored")
+		t.FailNow()
+	}
+}
+
+func TestSegments(t *testing.T) {
+	route := ParseRoute(PrepareURLTemplate(t))
+	expected := []string{"api", "v1", "users", ":username", "details"}
+	if !reflect.DeepEqual(route.Segments(), expected) || route.Query()["type"] != "cache" {
+		t.Logf("unexpected segments %v", route.Segments())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,245 @@
+package gtr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The BreakingChangeKind describes how a route changed in a way that
+// affects the requests it serves or the cache keys it produces
+type BreakingChangeKind string
+
+const (
+	// No route of the new table matches the URLs of the old route
+	CHANGE_REMOVED BreakingChangeKind = "removed"
+	// The new route rejects URLs the old route accepted, for example
+	// because a parameter constraint or a query param was added
+	CHANGE_NARROWED BreakingChangeKind = "narrowed"
+	// The cache keys of the route vary by different parameters, query
+	// params, or body keys, so cached entries are no longer shared
+	CHANGE_VARY BreakingChangeKind = "vary"
+)
+
+// The BreakingChange struct describes a single breaking change
+// Params:
+//   - Template: The template of the route in the old table
+//   - Replacement: The template of the route in the new table that
+//     serves the URLs of the old route, if any
+//   - Ownership: The owners of the route in the old table, if any
+type BreakingChange struct {
+	Kind        BreakingChangeKind `json:"kind"`
+	Method      string             `json:"method,omitempty"`
+	Template    string             `json:"template"`
+	Replacement string             `json:"replacement,omitempty"`
+	Detail      string             `json:"detail"`
+	Ownership   *Ownership         `json:"ownership,omitempty"`
+}
+
+// Compares two versions of a route table and lists the changes that
+// would break clients or caches relying on the old one: removed
+// templates, narrowed constraints, and changed vary-by specs. A route
+// of the new table replaces a route of the old table if both have the
+// same method and path segments, regardless of parameter names and
+// constraints.
+func CompatibleWith(old *RouteTable, new *RouteTable) []BreakingChange {
+	oldRoutes, oldIgnored := old.snapshot()
+	newRoutes, newIgnored := new.snapshot()
+	templates := make(map[string]*Route, len(newRoutes))
+	paths := make(map[string][]*Route)
+	for _, route := range newRoutes {
+		templates[route.method+" "+route.template] = route
+		paths[route.pathKey()] = append(paths[route.pathKey()], route)
+	}
+	changes := make([]BreakingChange, 0)
+	for _, route := range oldRoutes {
+		replacement, ok := templates[route.method+" "+route.template]
+		narrowings := []string(nil)
+		if ok {
+			// Routes of the same template may still differ by query mode
+			narrowings = narrowed(route, replacement)
+		} else {
+			for _, candidate := range paths[route.pathKey()] {
+				found := narrowed(route, candidate)
+				if replacement == nil || len(found) < len(narrowings) {
+					replacement = candidate
+					narrowings = found
+				}
+			}
+		}
+		if replacement == nil {
+			changes = append(changes, BreakingChange{
+				Kind:      CHANGE_REMOVED,
+				Method:    route.method,
+				Template:  route.template,
+				Ownership: route.owners(),
+				Detail:    "no route matches the URLs of the template",
+			})
+			continue
+		}
+		for _, detail := range narrowings {
+			changes = append(changes, BreakingChange{
+				Kind:        CHANGE_NARROWED,
+				Method:      route.method,
+				Template:    route.template,
+				Ownership:   route.owners(),
+				Replacement: replacement.template,
+				Detail:      detail,
+			})
+		}
+		for _, detail := range route.vary(oldIgnored).diff(replacement.vary(newIgnored)) {
+			changes = append(changes, BreakingChange{
+				Kind:        CHANGE_VARY,
+				Method:      route.method,
+				Template:    route.template,
+				Ownership:   route.owners(),
+				Replacement: replacement.template,
+				Detail:      detail,
+			})
+		}
+	}
+	return changes
+}
+
+// Gets the routes of the table ordered by their templates alongside
+// the query params ignored table-wide
+func (rt *RouteTable) snapshot() ([]*Route, []string) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.sorted(), append([]string(nil), rt.ignored...)
+}
+
+// Creates a key that is identical for routes with the same method
+// and path segments, regardless of parameter names and constraints
+func (route *Route) pathKey() string {
+	buffer := strings.Builder{}
+	buffer.WriteString(route.method)
+	buffer.WriteString(" ")
+	buffer.WriteString(strings.Join(route.hostLabels, "."))
+	for _, segment := range route.Segments() {
+		buffer.WriteString("/")
+		if strings.HasPrefix(segment, ":") {
+			segment = ":"
+		}
+		buffer.WriteString(segment)
+	}
+	return buffer.String()
+}
+
+// Lists how a route rejects URLs that another route with the same
+// path key accepted
+func narrowed(old *Route, new *Route) []string {
+	found := make([]string, 0)
+	for position, segment := range new.segments {
+		constraint, name := segment.constraint, segment.value
+		if !segment.param() || constraint == nil {
+			continue
+		}
+		previous := old.segments[position].constraint
+		if previous == nil {
+			found = append(found, fmt.Sprintf("parameter %s is now constrained by %s", name, constraint))
+			continue
+		}
+		for _, sample := range previous.samples() {
+			if previous.accepts(sample) && !constraint.accepts(sample) {
+				found = append(found, fmt.Sprintf("parameter %s no longer accepts %s", name, sample))
+				break
+			}
+		}
+	}
+	for key, value := range new.queryParams {
+		previous, ok := old.queryParams[key]
+		paramType, typed := new.queryTypes[key]
+		previousType, wasTyped := old.queryTypes[key]
+		_, named := new.queryNames[key]
+		constraint, constrained := new.queryConstraints[key]
+		_, wasNamed := old.queryNames[key]
+		previousConstraint, wasConstrained := old.queryConstraints[key]
+		switch {
+		case !ok:
+			found = append(found, fmt.Sprintf("query param %s is now required", key))
+		case named && constrained && wasNamed && (!wasConstrained || previousConstraint.String() != constraint.String()):
+			found = append(found, fmt.Sprintf("query param %s is now constrained by %s", key, constraint))
+		case named && constrained && !wasNamed && !wasTyped && !constraint.accepts(previous):
+			found = append(found, fmt.Sprintf("query param %s no longer accepts %s", key, previous))
+		case named:
+		case wasNamed:
+			found = append(found, fmt.Sprintf("query param %s now requires %s", key, value))
+		case typed && wasTyped && paramType != previousType && paramType != PARAM_STRING:
+			found = append(found, fmt.Sprintf("query param %s changed from %s to %s", key, previousType, paramType))
+		case typed && !wasTyped:
+			if _, err := paramType.Coerce(previous); err != nil {
+				found = append(found, fmt.Sprintf("query param %s no longer accepts %s", key, previous))
+			}
+		case !typed && (wasTyped || value != previous):
+			found = append(found, fmt.Sprintf("query param %s now requires %s", key, value))
+		}
+	}
+	for pattern, value := range new.queryWildcards {
+		if previous, ok := old.queryWildcards[pattern]; !ok || previous != value {
+			found = append(found, fmt.Sprintf("query params matching %s now require %s", pattern, value))
+		}
+	}
+	if new.noQuery && !old.noQuery {
+		found = append(found, "query params are no longer accepted")
+	}
+	// Query modes are ordered from the strictest to the most lenient
+	if new.queryMode < old.queryMode && len(new.queryParams)+len(new.queryWildcards) > 0 {
+		found = append(found, fmt.Sprintf("query params are now matched in %s mode", new.queryMode))
+	}
+	sort.Strings(found)
+	return found
+}
+
+// The varySpec struct lists what the cache keys of a route vary by
+type varySpec struct {
+	params    []string
+	query     []string
+	wildcards []string
+	ignored   []string
+	kept      []string
+	body      []string
+}
+
+// Gets what the cache keys of a route vary by
+// Params:
+//   - ignored: The query params ignored table-wide
+func (route *Route) vary(ignored []string) varySpec {
+	spec := varySpec{
+		params:    route.Params(),
+		query:     make([]string, 0, len(route.queryParams)),
+		wildcards: make([]string, 0, len(route.queryWildcards)),
+		ignored:   append(append([]string(nil), ignored...), route.ignore...),
+		kept:      append([]string(nil), route.keep...),
+		body:      append([]string(nil), route.bodyKeys...),
+	}
+	for key := range route.queryParams {
+		spec.query = append(spec.query, key)
+	}
+	for pattern := range route.queryWildcards {
+		spec.wildcards = append(spec.wildcards, pattern)
+	}
+	sort.Strings(spec.query)
+	sort.Strings(spec.wildcards)
+	sort.Strings(spec.ignored)
+	sort.Strings(spec.kept)
+	return spec
+}
+
+// Describes every difference between two vary-by specs
+func (spec varySpec) diff(other varySpec) []string {
+	found := make([]string, 0)
+	compare := func(what string, old []string, new []string) {
+		if !reflect.DeepEqual(old, new) {
+			found = append(found, fmt.Sprintf("%s changed from %v to %v", what, old, new))
+		}
+	}
+	compare("parameters", spec.params, other.params)
+	compare("query params", spec.query, other.query)
+	compare("wildcard query params", spec.wildcards, other.wildcards)
+	compare("ignored query params", spec.ignored, other.ignored)
+	compare("kept query params", spec.kept, other.kept)
+	compare("body keys", spec.body, other.body)
+	return found
+}
@@ -0,0 +1,73 @@
+package gtr
+
+import "testing"
+
+func TestCompatibleWith(t *testing.T) {
+	old := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/users/:username/details?type=cache": nil,
+		"http://www.abcdefg.com/api/:version/items":                     nil,
+		"http://www.abcdefg.com/api/posts/:id":                          nil,
+		"http://www.abcdefg.com/api/health":                             nil,
+	})
+	if changes := CompatibleWith(old, old); len(changes) != 0 {
+		t.Logf("expected a table to be compatible with itself but found %v", changes)
+		t.FailNow()
+	}
+	new := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/users/:username/details?type=cache": nil,
+		`http://www.abcdefg.com/api/:version<semver-range ">=2">/items`: nil,
+		"http://www.abcdefg.com/api/posts/:postId":                      nil,
+	})
+	new.SetIgnoredQuery("utm_*")
+	changes := CompatibleWith(old, new)
+	found := make(map[string]bool)
+	for _, change := range changes {
+		found[string(change.Kind)+" "+change.Template] = true
+	}
+	for _, expected := range []string{
+		"removed http://www.abcdefg.com/api/health",
+		"narrowed http://www.abcdefg.com/api/:version/items",
+		"vary http://www.abcdefg.com/api/posts/:id",
+	} {
+		if !found[expected] {
+			t.Logf("expected %s in %v", expected, changes)
+			t.FailNow()
+		}
+	}
+	// Every route varies by the new table-wide ignored query params
+	if len(changes) != 6 {
+		t.Logf("expected 6 changes but found %d: %v", len(changes), changes)
+		t.FailNow()
+	}
+}
+
+func TestNarrowedConstraints(t *testing.T) {
+	prepare := func(template string) *Route {
+		route, err := newRouteTable().prepare("", PrepareURLFrom(t, template))
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		return route
+	}
+	tests := []struct {
+		old      string
+		new      string
+		narrowed bool
+	}{
+		{`/api/:v<semver-range ">=1">`, `/api/:v<semver-range ">=2">`, true},
+		{`/api/:v<semver-range ">=2">`, `/api/:v<semver-range ">=1">`, false},
+		{`/api/:v<semver-range ">=1 <3">`, `/api/:v<semver-range ">=1 <3 || >=5">`, false},
+		{`/api/:v`, `/api/:v?page=<int>`, true},
+		{`/api/:v?page=2`, `/api/:v?page=<int>`, false},
+		{`/api/:v?page=<int>`, `/api/:v?page=<string>`, false},
+		{`/api/:v?page=<string>`, `/api/:v?page=<int>`, true},
+	}
+	for _, test := range tests {
+		found := narrowed(prepare(test.old), prepare(test.new))
+		if (len(found) > 0) != test.narrowed {
+			t.Logf("%s to %s: expected narrowed %v but found %v", test.old, test.new, test.narrowed, found)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gtr
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentUse(t *testing.T) {
+	rt := newRouteTable()
+	rt.TrackUncoveredQuery(true)
+	rt.SampleTraffic(10)
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 1.0}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				url := PrepareURLFrom(t, fmt.Sprintf("http://www.abcdefg.com/api/v%d/items/:id%d", i, j))
+				if err := rt.Register(url, nil); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := rt.Match(PrepareURL(t)); err != nil {
+					t.Error(err)
+					return
+				}
+				rt.GetPolicy(hash, "GET")
+				rt.Routes()
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := rt.PatchConfig(hash, []byte(fmt.Sprintf(`{"writer%d": %d}`, i, j))); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(rt.Routes()) != 401 || rt.GetConfig(hash)["writer0"] != 49.0 {
+		t.Logf("unexpected state after concurrent use: %d routes", len(rt.Routes()))
+		t.FailNow()
+	}
+}
+
+func TestConcurrentExportRules(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 1.0}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if err := rt.SetMethodOverlay(hash, fmt.Sprintf("M%d", i), map[string]any{"ttl": i}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if err := rt.ExportRules(io.Discard); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+type registeringHook struct {
+	rt *RouteTable
+}
+
+func (hook registeringHook) OnMiss(event MissEvent) {
+	// Hooks are called without holding the lock, so they may mutate the table
+	hook.rt.Register(event.URL, nil)
+}
+
+func TestHooksMayUseTable(t *testing.T) {
+	rt := newRouteTable()
+	rt.AddMissHook(registeringHook{rt: rt})
+	url := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/items")
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api"), nil)
+	if _, err := rt.Find(url); err == nil {
+		t.Log("expected a miss")
+		t.FailNow()
+	}
+	if _, err := rt.Find(url); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
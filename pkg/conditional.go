@@ -0,0 +1,147 @@
+package gtr
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// The conditionalRoute struct is a route registered through
+// RegisterIf, which is only active while its probe passes
+type conditionalRoute struct {
+	url   *url.URL
+	opts  []RouteOption
+	probe func() bool
+	// The config the route is registered with, which keeps the updates
+	// made while the route was active
+	conf map[string]any
+	// Whether the route was registered by the condition, since only
+	// those routes are unregistered when the probe fails
+	owned bool
+}
+
+// Registers a route that is only active while a probe passes, for
+// example a route depending on an optional feature of its upstream.
+// The probe is evaluated right away, and again whenever the conditions
+// of the table are evaluated (see EvaluateConditions and
+// WatchConditions), which registers the route when the probe starts
+// passing and unregisters it when the probe stops passing. Routes
+// registered otherwise, for example through Register, are never
+// unregistered by a condition. The template and options are validated
+// even if the probe fails.
+func (rt *RouteTable) RegisterIf(url *url.URL, conf map[string]any, probe func() bool, opts ...RouteOption) error {
+	rt.mutex.RLock()
+	err := rt.checkFrozen()
+	if err == nil {
+		err = rt.validate(url, opts)
+	}
+	rt.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	condition := &conditionalRoute{url: url, opts: opts, probe: probe, conf: conf}
+	rt.mutex.Lock()
+	rt.conditions = append(rt.conditions, condition)
+	rt.mutex.Unlock()
+	return rt.evaluate(condition, probe())
+}
+
+// Evaluates the probes of the routes registered through RegisterIf,
+// registering the routes whose probes pass and unregistering the
+// routes whose probes fail. Probes are called without holding the lock
+// of the table. Frozen tables keep their routes, so their conditions are
+// not evaluated.
+func (rt *RouteTable) EvaluateConditions() error {
+	rt.mutex.RLock()
+	conditions := append([]*conditionalRoute(nil), rt.conditions...)
+	frozen := rt.frozen
+	rt.mutex.RUnlock()
+	if frozen {
+		return nil
+	}
+	for _, condition := range conditions {
+		if err := rt.evaluate(condition, condition.probe()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Evaluates the conditions of the table periodically (see
+// EvaluateConditions). The returned function stops evaluating, and
+// evaluation stops by itself once the table is frozen.
+// Params:
+//   - interval: How often the probes are evaluated
+//   - callback: Called with the error of every failed evaluation. May
+//     be nil.
+func (rt *RouteTable) WatchConditions(interval time.Duration, callback func(err error)) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if rt.Frozen() {
+					return
+				}
+				if err := rt.EvaluateConditions(); err != nil && callback != nil {
+					callback(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// Registers or unregisters a conditional route depending on the
+// outcome of its probe
+func (rt *RouteTable) evaluate(condition *conditionalRoute, passed bool) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	route, err := rt.prepare("", condition.url)
+	if err != nil {
+		return err
+	}
+	_, registered := rt.index[route.hash]
+	switch {
+	case passed && !registered:
+		if _, err := rt.register("", condition.url, condition.conf, condition.opts...); err != nil {
+			return err
+		}
+		condition.owned = true
+	case !passed && registered && condition.owned:
+		condition.conf = rt.configs[route.hash]
+		condition.owned = false
+		return rt.unregister(route.hash)
+	case !registered:
+		// The route was unregistered by someone else
+		condition.owned = false
+	}
+	return nil
+}
+
+// Checks whether a template would register with the given options
+// Must be called with the read lock held
+func (rt *RouteTable) validate(url *url.URL, opts []RouteOption) error {
+	route, err := rt.prepare("", url)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		if err := opt(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
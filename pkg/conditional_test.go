@@ -0,0 +1,102 @@
+package gtr
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegisterIf(t *testing.T) {
+	rt := newRouteTable()
+	available := true
+	if err := rt.RegisterIf(PrepareURLTemplate(t), map[string]any{"ttl": 5}, func() bool { return available }); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if found, err := rt.Find(PrepareURL(t)); err != nil || found != hash {
+		t.Logf("expected the route of a passing probe to be active: %v", err)
+		t.FailNow()
+	}
+	rt.UpdateConfig(hash, map[string]any{"ttl": 10})
+	available = false
+	if err := rt.EvaluateConditions(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err == nil {
+		t.Log("expected the route of a failing probe to be inactive")
+		t.FailNow()
+	}
+	available = true
+	rt.EvaluateConditions()
+	if rt.GetConfig(hash)["ttl"] != 10 {
+		t.Log("expected the route to keep the config it was updated with")
+		t.FailNow()
+	}
+	if err := rt.RegisterIf(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id/:id"), nil, func() bool { return false }); !errors.Is(err, DUPLICATE_PARAMETER) {
+		t.Logf("expected DUPLICATE_PARAMETER for a failing probe but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestRegisterIfKeepsRegisteredRoutes(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.Register(PrepareURLTemplate(t), map[string]any{"ttl": 5}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.RegisterIf(PrepareURLTemplate(t), nil, func() bool { return false }); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.EvaluateConditions(); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err != nil {
+		t.Log("expected a failing probe to leave a route it did not register")
+		t.FailNow()
+	}
+}
+
+func TestWatchConditions(t *testing.T) {
+	rt := newRouteTable()
+	available := atomic.Bool{}
+	rt.RegisterIf(PrepareURLTemplate(t), nil, available.Load)
+	stop := rt.WatchConditions(5*time.Millisecond, nil)
+	defer stop()
+	available.Store(true)
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if _, err := rt.Find(PrepareURL(t)); err == nil {
+			return
+		}
+	}
+	t.Log("expected the route to be activated once its probe passes")
+	t.FailNow()
+}
+
+func TestConditionsOfFrozenTable(t *testing.T) {
+	rt := newRouteTable()
+	probes := int32(0)
+	rt.RegisterIf(PrepareURLTemplate(t), nil, func() bool {
+		atomic.AddInt32(&probes, 1)
+		return false
+	})
+	rt.Freeze()
+	if err := rt.EvaluateConditions(); err != nil {
+		t.Logf("expected the conditions of a frozen table to be skipped but found %v", err)
+		t.FailNow()
+	}
+	failures := int32(0)
+	stop := rt.WatchConditions(time.Millisecond, func(err error) {
+		atomic.AddInt32(&failures, 1)
+	})
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+	if failures, probes := atomic.LoadInt32(&failures), atomic.LoadInt32(&probes); failures != 0 || probes != 1 {
+		t.Logf("expected no evaluation of a frozen table but found %d failures and %d probes", failures, probes)
+		t.FailNow()
+	}
+}
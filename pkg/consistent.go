@@ -0,0 +1,107 @@
+package gtr
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Selects a shard for a key (typically a route hash or cache key)
+// using jump consistent hashing. Growing the number of shards only
+// moves the keys that land on the new shards.
+// Params:
+//   - key: The key to place
+//   - shards: The number of shards (values below 1 are treated as 1)
+func ShardFor(key string, shards int) int {
+	if shards < 1 {
+		return 0
+	}
+	hasher := fnv.New64a()
+	hasher.Write([]byte(key))
+	state := hasher.Sum64()
+	bucket, next := int64(-1), int64(0)
+	for next < int64(shards) {
+		bucket = next
+		state = state*2862933555777941757 + 1
+		next = int64(float64(bucket+1) * (float64(int64(1)<<31) / float64((state>>33)+1)))
+	}
+	return int(bucket)
+}
+
+// The HashRing struct places keys on named nodes using a consistent
+// hash ring with virtual nodes. Unlike ShardFor, nodes can be removed
+// from the middle of the ring.
+type HashRing struct {
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+}
+
+// Creates a new hash ring
+// Params:
+//   - nodes: The names of the nodes
+//   - replicas: The number of virtual nodes per node (values below 1 are treated as 1)
+func NewHashRing(nodes []string, replicas int) *HashRing {
+	if replicas < 1 {
+		replicas = 1
+	}
+	ring := HashRing{
+		replicas: replicas,
+		points:   make([]uint32, 0, len(nodes)*replicas),
+		owners:   make(map[uint32]string),
+	}
+	for _, node := range nodes {
+		ring.Add(node)
+	}
+	return &ring
+}
+
+// Adds a node to the ring
+func (ring *HashRing) Add(node string) {
+	for i := 0; i < ring.replicas; i++ {
+		point := ringPoint(node + "#" + strconv.Itoa(i))
+		if _, ok := ring.owners[point]; ok {
+			continue
+		}
+		ring.owners[point] = node
+		ring.points = append(ring.points, point)
+	}
+	sort.Slice(ring.points, func(i, j int) bool {
+		return ring.points[i] < ring.points[j]
+	})
+}
+
+// Removes a node from the ring
+func (ring *HashRing) Remove(node string) {
+	points := ring.points[:0]
+	for _, point := range ring.points {
+		if ring.owners[point] == node {
+			delete(ring.owners, point)
+			continue
+		}
+		points = append(points, point)
+	}
+	ring.points = points
+}
+
+// Gets the node responsible for a key
+// An empty string is returned if the ring has no nodes
+func (ring *HashRing) Get(key string) string {
+	if len(ring.points) == 0 {
+		return ""
+	}
+	point := ringPoint(key)
+	index := sort.Search(len(ring.points), func(i int) bool {
+		return ring.points[i] >= point
+	})
+	if index == len(ring.points) {
+		index = 0
+	}
+	return ring.owners[ring.points[index]]
+}
+
+func ringPoint(key string) uint32 {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(key))
+	return hasher.Sum32()
+}
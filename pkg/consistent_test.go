@@ -0,0 +1,56 @@
+package gtr
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardFor(t *testing.T) {
+	moved := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		before := ShardFor(key, 10)
+		after := ShardFor(key, 11)
+		if before < 0 || before >= 10 || after < 0 || after >= 11 {
+			t.Log("shard out of range")
+			t.FailNow()
+		}
+		if before != after {
+			if after != 10 {
+				t.Log("keys should only move to the new shard")
+				t.FailNow()
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 200 {
+		t.Logf("unexpected number of moved keys %d", moved)
+		t.FailNow()
+	}
+}
+
+func TestHashRing(t *testing.T) {
+	ring := NewHashRing([]string{"a", "b", "c"}, 64)
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		owners[key] = ring.Get(key)
+		counts[owners[key]]++
+	}
+	if len(counts) != 3 {
+		t.Log("keys were not spread across nodes")
+		t.FailNow()
+	}
+	ring.Remove("b")
+	for key, owner := range owners {
+		if owner != "b" && ring.Get(key) != owner {
+			t.Log("removing a node moved keys of other nodes")
+			t.FailNow()
+		}
+		if ring.Get(key) == "b" {
+			t.Log("removed node still owns keys")
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A paramConstraint restricts the values accepted by a route parameter.
+// Constraints are written after the parameter name, for example
+// `:version<semver-range "≥1 <3">` or `:id<int>`.
+type paramConstraint interface {
+	// Checks whether a concrete parameter value satisfies the constraint
+	accepts(value string) bool
+	// Gets a value satisfying the constraint
+	example() string
+	// Gets values around the bounds of the constraint, used to compare
+	// what two constraints accept
+	samples() []string
+	// Gets the constraint as written in the template
+	String() string
+}
+
+// Splits a parameter segment into the parameter name and its
+// constraint, if any
+func parseParam(segment string) (string, paramConstraint, error) {
+	name := strings.TrimPrefix(segment, ":")
+	open := strings.Index(name, "<")
+	if open < 0 {
+		return name, nil, nil
+	}
+	if !strings.HasSuffix(name, ">") {
+		return "", nil, fmt.Errorf("%w: unterminated constraint %s", INVALID_VALUE, segment)
+	}
+	spec := name[open+1 : len(name)-1]
+	name = name[:open]
+	kind, argument, _ := strings.Cut(spec, " ")
+	if paramType, ok := parseParamType("<" + kind + ">"); ok {
+		if len(strings.TrimSpace(argument)) > 0 {
+			return "", nil, fmt.Errorf("%w: %s takes no argument in %s", INVALID_VALUE, kind, segment)
+		}
+		return name, typeConstraint{paramType: paramType}, nil
+	}
+	switch kind {
+	case "semver-range":
+		expression, err := strconv.Unquote(strings.TrimSpace(argument))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: semver-range expects a quoted range in %s", INVALID_VALUE, segment)
+		}
+		constraint, err := parseSemverRange(expression)
+		if err != nil {
+			return "", nil, err
+		}
+		return name, constraint, nil
+	}
+	return "", nil, fmt.Errorf("%w: unknown constraint %s", INVALID_VALUE, kind)
+}
+
+// Validates the constraints of all parameters of a template
+func validateParams(template *url.URL) error {
+	hostname, _ := splitHost(template.Host)
+	for _, label := range strings.Split(hostname, ".") {
+		if !strings.HasPrefix(label, ":") {
+			continue
+		}
+		if _, _, err := parseParam(label); err != nil {
+			return err
+		}
+	}
+	for _, values := range template.Query() {
+		for _, value := range values {
+			if name, ok := placeholder(value); ok {
+				if _, _, err := parseParam(":" + name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	segments := strings.Split(strings.TrimRight(routePath(template), "/"), "/")
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		_, _, extension, err := parseSegmentParam(segment)
+		if err != nil {
+			return err
+		}
+		if len(extension) > 0 && index != len(segments)-1 {
+			return fmt.Errorf("%w: only the last path segment may have an extension parameter in %s", INVALID_VALUE, segment)
+		}
+	}
+	return nil
+}
+
+// A version is a `major.minor.patch` triple. Missing components are zero.
+type version [3]int
+
+// Parses versions such as `2`, `v2.1` or `2.1.3`. Pre-release and
+// build suffixes are ignored.
+func parseVersion(value string) (version, bool) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V")
+	if end := strings.IndexAny(value, "-+"); end >= 0 {
+		value = value[:end]
+	}
+	parts := strings.Split(value, ".")
+	if len(value) == 0 || len(parts) > 3 {
+		return version{}, false
+	}
+	result := version{}
+	for index, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return version{}, false
+		}
+		result[index] = number
+	}
+	return result, true
+}
+
+func (v version) compare(other version) int {
+	for index := range v {
+		if v[index] != other[index] {
+			if v[index] < other[index] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// A comparator is a single bound of a semver range such as `≥1`
+type comparator struct {
+	operator string
+	version  version
+}
+
+var _operators = []struct {
+	token    string
+	operator string
+}{
+	{">=", ">="}, {"≥", ">="}, {"<=", "<="}, {"≤", "<="}, {"==", "="},
+	{">", ">"}, {"<", "<"}, {"=", "="},
+}
+
+func (c comparator) accepts(v version) bool {
+	result := v.compare(c.version)
+	switch c.operator {
+	case ">=":
+		return result >= 0
+	case ">":
+		return result > 0
+	case "<=":
+		return result <= 0
+	case "<":
+		return result < 0
+	}
+	return result == 0
+}
+
+// A semverRange is a set of alternatives separated by `||`, each of
+// which is a list of comparators that must all hold
+type semverRange struct {
+	expression   string
+	alternatives [][]comparator
+}
+
+func parseSemverRange(expression string) (*semverRange, error) {
+	rng := &semverRange{expression: expression}
+	for _, alternative := range strings.Split(expression, "||") {
+		comparators := make([]comparator, 0)
+		fields := strings.Fields(alternative)
+		for index := 0; index < len(fields); index++ {
+			field := fields[index]
+			operator := "="
+			for _, candidate := range _operators {
+				if strings.HasPrefix(field, candidate.token) {
+					operator = candidate.operator
+					field = field[len(candidate.token):]
+					break
+				}
+			}
+			// Allows a space between the operator and the version
+			if len(field) == 0 && index+1 < len(fields) {
+				index++
+				field = fields[index]
+			}
+			version, ok := parseVersion(field)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid semver range %q", INVALID_VALUE, expression)
+			}
+			comparators = append(comparators, comparator{operator: operator, version: version})
+		}
+		if len(comparators) == 0 {
+			return nil, fmt.Errorf("%w: invalid semver range %q", INVALID_VALUE, expression)
+		}
+		rng.alternatives = append(rng.alternatives, comparators)
+	}
+	return rng, nil
+}
+
+func (rng *semverRange) accepts(value string) bool {
+	version, ok := parseVersion(value)
+	if !ok {
+		return false
+	}
+	for _, alternative := range rng.alternatives {
+		matched := true
+		for _, comparator := range alternative {
+			if !comparator.accepts(version) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets the lowest version satisfying the lower bounds of the first
+// satisfiable alternative
+func (rng *semverRange) example() string {
+	for _, alternative := range rng.alternatives {
+		candidate := version{1, 0, 0}
+		for _, comparator := range alternative {
+			bound := comparator.version
+			if comparator.operator == ">" {
+				bound[2]++
+			}
+			if comparator.operator != "<" && comparator.operator != "<=" && bound.compare(candidate) > 0 || comparator.operator == "=" {
+				candidate = bound
+			}
+		}
+		if rng.accepts(candidate.String()) {
+			return candidate.String()
+		}
+	}
+	return version{}.String()
+}
+
+func (rng *semverRange) samples() []string {
+	samples := []string{rng.example()}
+	for _, alternative := range rng.alternatives {
+		for _, comparator := range alternative {
+			for component := range comparator.version {
+				for _, delta := range []int{-1, 0, 1} {
+					sample := comparator.version
+					sample[component] += delta
+					if sample[component] >= 0 {
+						samples = append(samples, sample.String())
+					}
+				}
+			}
+		}
+	}
+	return samples
+}
+
+func (rng *semverRange) String() string {
+	return "semver-range " + strconv.Quote(rng.expression)
+}
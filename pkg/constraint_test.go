@@ -0,0 +1,127 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestSemverRangeConstraint(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		`http://www.abcdefg.com/api/:version<semver-range "≥1 <3">/users/:id`: {"ttl": 10},
+	})
+	tests := map[string]bool{
+		"http://www.abcdefg.com/api/v1/users/ken":     true,
+		"http://www.abcdefg.com/api/2.4.1/users/ken":  true,
+		"http://www.abcdefg.com/api/v3/users/ken":     false,
+		"http://www.abcdefg.com/api/0.9/users/ken":    false,
+		"http://www.abcdefg.com/api/latest/users/ken": false,
+	}
+	for raw, expected := range tests {
+		url, _ := url.Parse(raw)
+		match, err := rt.Match(url)
+		if (err == nil) != expected {
+			t.Logf("unexpected result %v for %s", err, raw)
+			t.FailNow()
+		}
+		if expected && match.Params["id"] != "ken" {
+			t.Logf("unexpected params %v for %s", match.Params, raw)
+			t.FailNow()
+		}
+	}
+}
+
+func TestSemverRangeAlternatives(t *testing.T) {
+	rng, err := parseSemverRange("< 1.2 || >= 2.0.1 <= 2.1 || =4")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	tests := map[string]bool{
+		"1.1.9": true,
+		"1.2":   false,
+		"2.0.1": true,
+		"v2.1":  true,
+		"2.1.1": false,
+		"4":     true,
+		"4.0.1": false,
+	}
+	for value, expected := range tests {
+		if rng.accepts(value) != expected {
+			t.Logf("expected %v for %s", expected, value)
+			t.FailNow()
+		}
+	}
+	if example := rng.example(); !rng.accepts(example) {
+		t.Logf("example %s does not satisfy the range", example)
+		t.FailNow()
+	}
+}
+
+func TestInvalidConstraint(t *testing.T) {
+	rt := newRouteTable()
+	for _, raw := range []string{
+		`http://www.abcdefg.com/api/:version<semver-range "one">/users`,
+		`http://www.abcdefg.com/api/:version<semver-range ≥1>/users`,
+		`http://www.abcdefg.com/api/:version<color "red">/users`,
+	} {
+		url, _ := url.Parse(raw)
+		if err := rt.Register(url, nil); !errors.Is(err, INVALID_VALUE) {
+			t.Logf("expected INVALID_VALUE but found %v for %s", err, raw)
+			t.FailNow()
+		}
+	}
+}
+
+func TestTypeConstraints(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/orders/:id<int>":           {"ttl": 10},
+		"http://www.abcdefg.com/resources/:rid<uuid>":      {"ttl": 10},
+		"http://www.abcdefg.com/flags/:on<bool>/:w<float>": {"ttl": 10},
+	})
+	tests := map[string]any{
+		"http://www.abcdefg.com/orders/42":                                      int64(42),
+		"http://www.abcdefg.com/orders/abc":                                     nil,
+		"http://www.abcdefg.com/resources/0D4C5A36-9A2E-4B8B-8E6F-3F1C2B7A9D10": "0d4c5a36-9a2e-4b8b-8e6f-3f1c2b7a9d10",
+		"http://www.abcdefg.com/resources/42":                                   nil,
+	}
+	for raw, expected := range tests {
+		url, _ := url.Parse(raw)
+		match, err := rt.Match(url)
+		if (err == nil) != (expected != nil) {
+			t.Logf("unexpected result %v for %s", err, raw)
+			t.FailNow()
+		}
+		if expected == nil {
+			continue
+		}
+		for _, value := range match.Typed {
+			if value != expected {
+				t.Logf("expected %v but found %v for %s", expected, value, raw)
+				t.FailNow()
+			}
+		}
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/flags/true/0.5")
+	match, err := rt.Match(url)
+	if err != nil || match.Typed["on"] != true || match.Typed["w"] != 0.5 || match.Params["w"] != "0.5" {
+		t.Logf("unexpected match %v %v", match, err)
+		t.FailNow()
+	}
+}
+
+func TestTypeConstraintErrors(t *testing.T) {
+	if _, _, err := parseParam(":id<int 3>"); !errors.Is(err, INVALID_VALUE) {
+		t.Log("expected type constraints to take no argument")
+		t.FailNow()
+	}
+	if _, _, err := parseParam(":id<integer>"); !errors.Is(err, INVALID_VALUE) {
+		t.Log("expected unknown types to be rejected")
+		t.FailNow()
+	}
+	name, constraint, err := parseParam(":id<int>")
+	if err != nil || name != "id" || constraint.String() != "int" || !constraint.accepts(constraint.example()) {
+		t.Log("expected the type constraint to be parsed")
+		t.FailNow()
+	}
+}
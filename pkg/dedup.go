@@ -0,0 +1,81 @@
+package gtr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// Selects the params the deduplication keys of a route are derived
+// from (see DedupKey). Routes without selected params derive their
+// keys from all of their parameters.
+// Params:
+//   - params: The names of route parameters, or the keys of query
+//     params declared by the template
+func WithDedupParams(params ...string) RouteOption {
+	return func(route *Route) error {
+		names := make(map[string]bool)
+		for _, name := range route.Params() {
+			names[name] = true
+		}
+		for key := range route.queryParams {
+			names[key] = true
+		}
+		for _, param := range params {
+			if !names[param] {
+				return fmt.Errorf("%w: %s", UNKNOWN_PARAMETER, param)
+			}
+		}
+		route.dedupParams = append([]string(nil), params...)
+		return nil
+	}
+}
+
+// Creates an idempotency key for a URL, for consumers publishing
+// matched requests onto queues that deduplicate messages. Unlike
+// CacheKey, the key only covers the matching route and its selected
+// params (see WithDedupParams), so requests that only differ by other
+// query params, such as tracking params, share the same key. The key
+// is stable as long as the hash of the route is.
+func (rt *RouteTable) DedupKey(url *url.URL) (string, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, prt, err := rt.match("", url)
+	if err != nil {
+		return "", err
+	}
+	defer releaseRoute(prt)
+	values := route.values(prt)
+	names := route.dedupParams
+	if len(names) == 0 {
+		names = make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+	}
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	query := url.Query()
+	hash := sha256.New()
+	hash.Write([]byte(route.hash))
+	for _, name := range names {
+		if value, ok := values[name]; ok {
+			hash.Write([]byte{0})
+			hash.Write([]byte(name))
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+			continue
+		}
+		queryValues := append([]string(nil), query[name]...)
+		sort.Strings(queryValues)
+		for _, value := range queryValues {
+			hash.Write([]byte{1})
+			hash.Write([]byte(name))
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
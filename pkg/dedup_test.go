@@ -0,0 +1,47 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDedupKey(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/orders/:id?version=:version"), nil, WithDedupParams("id", "version")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	key := func(raw string) string {
+		key, err := rt.DedupKey(PrepareURLFrom(t, raw))
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		return key
+	}
+	first := key("http://www.abcdefg.com/api/v1/users/ken/orders/1?version=2&utm_source=mail")
+	if first != key("http://www.abcdefg.com/api/v1/users/joe/orders/1?version=2") {
+		t.Log("expected params that are not selected to be left out of the key")
+		t.FailNow()
+	}
+	if first == key("http://www.abcdefg.com/api/v1/users/ken/orders/1?version=3") || first == key("http://www.abcdefg.com/api/v1/users/ken/orders/2?version=2") {
+		t.Log("expected selected params to be part of the key")
+		t.FailNow()
+	}
+	if key("http://www.abcdefg.com/api/v1/posts/1?a=1") != key("http://www.abcdefg.com/api/v1/posts/1?a=2") || key("http://www.abcdefg.com/api/v1/posts/1") == key("http://www.abcdefg.com/api/v1/posts/2") {
+		t.Log("expected the keys of routes without selected params to cover their parameters")
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/comments/:id"), nil, WithDedupParams("page")); !errors.Is(err, UNKNOWN_PARAMETER) {
+		t.Logf("expected UNKNOWN_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.DedupKey(PrepareURLFrom(t, "http://www.abcdefg.com/health")); err == nil {
+		t.Log("expected unmatched URLs to fail")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,69 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Renames duplicate parameters instead of rejecting their templates.
+// The second occurrence of `:id` becomes `:id2`, the third `:id3`,
+// and so on.
+func (rt *RouteTable) SetDuplicateParamSuffix(suffix bool) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.suffix = suffix
+}
+
+func dedupeParams(template *url.URL, suffix bool) (*url.URL, error) {
+	path := routePath(template)
+	segments := strings.Split(path, "/")
+	counts := make(map[string]int)
+	renamed := false
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name, constraint, extension, err := parseSegmentParam(segment)
+		if err != nil {
+			return nil, err
+		}
+		// Extension parameters are not renamed
+		if len(extension) > 0 {
+			counts[extension]++
+			if counts[extension] > 1 {
+				return nil, fmt.Errorf("%w: %s", DUPLICATE_PARAMETER, extension)
+			}
+		}
+		counts[name]++
+		if counts[name] == 1 {
+			continue
+		}
+		if !suffix || len(template.Opaque) > 0 {
+			return nil, fmt.Errorf("%w: %s", DUPLICATE_PARAMETER, name)
+		}
+		rename := name + strconv.Itoa(counts[name])
+		for counts[rename] > 0 {
+			counts[name]++
+			rename = name + strconv.Itoa(counts[name])
+		}
+		counts[rename]++
+		segments[index] = ":" + rename
+		switch constraint := constraint.(type) {
+		case extensionConstraint:
+			segments[index] = constraint.template(rename, extension)
+		case nil:
+		default:
+			segments[index] += "<" + constraint.String() + ">"
+		}
+		renamed = true
+	}
+	if !renamed {
+		return template, nil
+	}
+	deduped := *template
+	deduped.Path = strings.Join(segments, "/")
+	deduped.RawPath = ""
+	return &deduped, nil
+}
@@ -0,0 +1,36 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestDuplicateParams(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/a/:id/b/{id}")
+	if err := rt.Register(template, nil); !errors.Is(err, DUPLICATE_PARAMETER) {
+		t.Log("expected duplicate parameter error")
+		t.FailNow()
+	}
+	rt.SetDuplicateParamSuffix(true)
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/a/1/b/2")
+	match, err := rt.Match(url)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Params["id"] != "1" || match.Params["id2"] != "2" {
+		t.Logf("unexpected params %v", match.Params)
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(match.Hash)
+	if route.Template() != "http://www.abcdefg.com/a/:id/b/:id2" {
+		t.Logf("unexpected template %s", route.Template())
+		t.FailNow()
+	}
+}
@@ -0,0 +1,21 @@
+package gtr
+
+// The EmptyQueryMode determines how templates ending with an empty
+// query (`/path?`) are matched
+type EmptyQueryMode int
+
+const (
+	// The trailing `?` is ignored and the template matches requests
+	// with any query, exactly like `/path` (the default)
+	EMPTY_QUERY_ANY EmptyQueryMode = iota
+	// The trailing `?` marks a template that only matches requests
+	// without query params
+	EMPTY_QUERY_NONE
+)
+
+// Sets how templates registered from now on treat an empty query
+func (rt *RouteTable) SetEmptyQueryMode(mode EmptyQueryMode) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.emptyQuery = mode
+}
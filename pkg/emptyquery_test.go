@@ -0,0 +1,47 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestEmptyQueryMode(t *testing.T) {
+	rt := newRouteTable()
+	open, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username?")
+	rt.Register(open, nil)
+	withQuery, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken?format=json")
+	if _, err := rt.Find(withQuery); err != nil {
+		t.Log("empty query should match any query by default")
+		t.FailNow()
+	}
+	rt = newRouteTable()
+	rt.SetEmptyQueryMode(EMPTY_QUERY_NONE)
+	plain, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	rt.Register(open, map[string]any{"rule": "none"})
+	rt.Register(plain, map[string]any{"rule": "any"})
+	if len(rt.Routes()) != 2 {
+		t.Log("empty query template should not collide with the plain template")
+		t.FailNow()
+	}
+	hash, err := rt.Find(withQuery)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["rule"] != "any" {
+		t.Log("request with query params resolved to the empty query template")
+		t.FailNow()
+	}
+	withoutQuery, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken")
+	only := newRouteTable()
+	only.SetEmptyQueryMode(EMPTY_QUERY_NONE)
+	only.Register(open, nil)
+	if _, err := only.Find(withQuery); err == nil {
+		t.Log("empty query template matched a request with query params")
+		t.FailNow()
+	}
+	if _, err := only.Find(withoutQuery); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
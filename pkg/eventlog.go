@@ -0,0 +1,187 @@
+package gtr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The EventOp is the kind of mutation recorded by an Event
+type EventOp string
+
+const (
+	// A route was added to the table
+	EVENT_REGISTER EventOp = "register"
+	// The config of a route was replaced
+	EVENT_UPDATE EventOp = "update"
+	// A route was removed from the table
+	EVENT_UNREGISTER EventOp = "unregister"
+	// Every route of the table was replaced at once, see ReloadFile
+	EVENT_SNAPSHOT EventOp = "snapshot"
+)
+
+// The Event struct is a single entry of the event log. Register
+// events carry the rule needed to register the route again, update
+// events carry the rule of the route once its config, its method
+// overlays, its experiment, or its variants were changed, unregister events carry the template of the
+// removed route, and snapshot events carry the whole table as
+// serialized by Export.
+type Event struct {
+	Op       EventOp         `json:"op"`
+	Time     time.Time       `json:"time"`
+	Hash     string          `json:"hash"`
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+	Rule
+}
+
+// Sets the append-only log that every mutation of the route table is
+// written to as a line of JSON. A nil writer disables the log.
+func (rt *RouteTable) SetEventLog(w io.Writer) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.events = w
+}
+
+// Writes an event for a mutated route to the event log, if any
+func (rt *RouteTable) record(op EventOp, route *Route, conf map[string]any) error {
+	if rt.events == nil && rt.replication == nil {
+		return nil
+	}
+	event := Event{
+		Op:   op,
+		Time: time.Now().UTC(),
+		Hash: route.hash,
+		Rule: Rule{Template: route.template, Method: route.method, Config: conf},
+	}
+	if op == EVENT_REGISTER || op == EVENT_UPDATE {
+		event.Rule = route.rule(conf)
+	}
+	if rt.replication != nil {
+		rt.replication.append(event)
+	}
+	if rt.replaying {
+		return nil
+	}
+	return rt.write(event)
+}
+
+// Writes an update event for a route whose settings were changed
+// after it was registered
+func (rt *RouteTable) recordUpdate(hash string) error {
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return err
+	}
+	return rt.record(EVENT_UPDATE, route, rt.configs[hash])
+}
+
+// Writes a snapshot event of the whole table to the event log, if any.
+// Replicas are not sent snapshot events, since they resynchronize
+// through the snapshot of their streams.
+// Must be called with the write lock held
+func (rt *RouteTable) recordSnapshot() error {
+	if rt.events == nil {
+		return nil
+	}
+	snapshot, err := rt.export()
+	if err != nil {
+		return err
+	}
+	return rt.write(Event{Op: EVENT_SNAPSHOT, Time: time.Now().UTC(), Snapshot: snapshot})
+}
+
+// Writes an event to the event log as a line of JSON, if any
+func (rt *RouteTable) write(event Event) error {
+	if rt.events == nil {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = rt.events.Write(append(data, '\n'))
+	return err
+}
+
+// Reconstructs the state of the route table by applying the events
+// of an event log in order. Replaying a log into the table that wrote
+// it is a no-op, so a log may be replayed up to any point in time by
+// truncating it first. The replayed events are not written to the
+// event log of the table again, but are still streamed to replicas.
+func (rt *RouteTable) Replay(r io.Reader) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	rt.replaying = true
+	defer func() {
+		rt.replaying = false
+	}()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		event := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("%w: line %d: %s", INVALID_VALUE, line, err.Error())
+		}
+		if err := rt.apply(event); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Applies a single event to the route table
+func (rt *RouteTable) apply(event Event) error {
+	switch event.Op {
+	case EVENT_REGISTER:
+		return rt.registerRule(event.Rule)
+	case EVENT_UPDATE:
+		route := rt.template(event.Method, event.Template)
+		if route == nil {
+			return fmt.Errorf("%w: %s", HASH_NOT_REGISTERED, event.Template)
+		}
+		if err := rt.setConfig(route.hash, event.Config); err != nil {
+			return err
+		}
+		if err := rt.modify(route.hash, event.Rule.update); err != nil {
+			return err
+		}
+		return rt.recordUpdate(route.hash)
+	case EVENT_UNREGISTER:
+		route := rt.template(event.Method, event.Template)
+		if route == nil {
+			return fmt.Errorf("%w: %s", HASH_NOT_REGISTERED, event.Template)
+		}
+		return rt.unregister(route.hash)
+	case EVENT_SNAPSHOT:
+		scratch := rt.empty()
+		if err := scratch.LoadRules(bytes.NewReader(event.Snapshot)); err != nil {
+			return err
+		}
+		rt.swap(scratch)
+		return nil
+	}
+	return fmt.Errorf("%w: %s", UNKNOWN_OPERATION, event.Op)
+}
+
+// Gets the registered route with the given method and template, if any
+// Templates rather than hashes identify routes in the event log so
+// that a log stays valid across rehashing
+func (rt *RouteTable) template(method string, template string) *Route {
+	for _, route := range rt.index {
+		if route.method == method && route.template == template {
+			return route
+		}
+	}
+	return nil
+}
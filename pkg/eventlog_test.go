@@ -0,0 +1,143 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventLogReplay(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLTemplate(t)
+	err := rt.Register(template, map[string]any{"ttl": 10.0}, WithClass(CLASS_USER), WithBodyKeys("/id"), WithMethodOverlay("POST", map[string]any{"bypass": true}))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	if err := rt.PatchConfig(hash, []byte(`{"ttl": 20}`)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if lines := strings.Count(log.String(), "\n"); lines != 2 {
+		t.Logf("expected 2 events but found %d", lines)
+		t.FailNow()
+	}
+
+	replayed := newRouteTable()
+	if err := replayed.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, err := replayed.Lookup(hash)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if route.Class() != CLASS_USER || !reflect.DeepEqual(route.bodyKeys, []string{"/id"}) || route.overlays["POST"]["bypass"] != true {
+		t.Log("replay did not restore the route options")
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(replayed.GetConfig(hash), map[string]any{"ttl": 20.0}) {
+		t.Logf("unexpected config %v", replayed.GetConfig(hash))
+		t.FailNow()
+	}
+
+	// Replaying up to a point in time restores the state at that point
+	first := strings.SplitAfter(log.String(), "\n")[0]
+	earlier := newRouteTable()
+	if err := earlier.Replay(strings.NewReader(first)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(earlier.GetConfig(hash), map[string]any{"ttl": 10.0}) {
+		t.Logf("unexpected config %v", earlier.GetConfig(hash))
+		t.FailNow()
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	rt := newRouteTable()
+	err := rt.Replay(strings.NewReader(`{"op": "update", "template": "http://www.abcdefg.com/api"}`))
+	if !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	err = rt.Replay(strings.NewReader(`{"op": "rename", "template": "http://www.abcdefg.com/api"}`))
+	if !errors.Is(err, UNKNOWN_OPERATION) {
+		t.Logf("expected UNKNOWN_OPERATION but found %v", err)
+		t.FailNow()
+	}
+	err = rt.Replay(strings.NewReader(`not json`))
+	if !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestReplayMethodOverlay(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLTemplate(t)
+	hash := CreateHash(template)
+	rt.Register(template, map[string]any{"ttl": 10.0})
+	if err := rt.SetMethodOverlay(hash, "POST", map[string]any{"bypass": true}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if lines := strings.Count(log.String(), "\n"); lines != 2 {
+		t.Logf("expected 2 events but found %d", lines)
+		t.FailNow()
+	}
+
+	replayed := newRouteTable()
+	if err := replayed.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !replayed.GetPolicy(hash, "POST").Bypass() || replayed.GetPolicy(hash, "GET").Bypass() {
+		t.Log("replay did not restore the method overlay")
+		t.FailNow()
+	}
+
+	// Replaying a log into the table that wrote it leaves the log as is
+	written := log.String()
+	if err := rt.Replay(strings.NewReader(written)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if log.String() != written {
+		t.Log("expected the replayed events not to be written again")
+		t.FailNow()
+	}
+}
+
+func TestReplayExperiment(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLTemplate(t)
+	hash := CreateHash(template)
+	rt.Register(template, nil)
+	experiment := Experiment{Param: "username", Buckets: []Bucket{{Name: "control", Weight: 1}}}
+	if err := rt.SetExperiment(hash, experiment); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	replayed := newRouteTable()
+	if err := replayed.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	assignment, err := replayed.Assign(PrepareURL(t))
+	if err != nil || assignment.Bucket != "control" {
+		t.Logf("replay did not restore the experiment: %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,148 @@
+package gtr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	_exampleNames = []string{"ken", "dennis", "rob", "ada", "grace", "linus", "margaret", "barbara"}
+)
+
+// Generates distinct example URLs that match a route template.
+// Parameter examples given through WithParamDocs are used first, the
+// remaining values are generated based on parameter types, names, and
+// constraints. Fewer examples are returned when the template cannot
+// produce n distinct URLs, for example when its parameters are
+// constrained to a few values.
+// Params:
+//   - hash: The hash of the route
+//   - n: The maximum number of examples to generate
+func (rt *RouteTable) Examples(hash string, n int) []string {
+	route, err := rt.Lookup(hash)
+	if err != nil || n <= 0 {
+		return nil
+	}
+	examples := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for i := 0; i < n; i++ {
+		example := route.example(i)
+		if seen[example] {
+			continue
+		}
+		seen[example] = true
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+func (route *Route) example(i int) string {
+	example := *route.url
+	if len(route.hostLabels) > 0 {
+		labels := make([]string, len(route.hostLabels))
+		for index, label := range route.hostLabels {
+			labels[index] = label
+			if constraint, ok := route.hostConstraints[index]; ok {
+				labels[index] = constraintExample(constraint, i)
+			} else if name, ok := route.hostParams[index]; ok {
+				labels[index] = strings.ToLower(route.exampleValue(name, i))
+			} else if label == "*" {
+				labels[index] = "www"
+			}
+		}
+		example.Host = strings.Join(labels, ".")
+		if len(route.hostPort) > 0 {
+			example.Host += ":" + route.hostPort
+		}
+	}
+	if len(example.Opaque) > 0 {
+		example.Opaque = route.exampleSegments(example.Opaque, i)
+	} else {
+		example.Path = route.exampleSegments(example.Path, i)
+		example.RawPath = ""
+	}
+	query := example.Query()
+	for key, paramType := range route.queryTypes {
+		query.Set(key, exampleTypedValue(paramType, i))
+	}
+	for key, name := range route.queryNames {
+		if constraint, ok := route.queryConstraints[key]; ok {
+			query.Set(key, constraintExample(constraint, i))
+			continue
+		}
+		query.Set(key, route.exampleValue(name, i))
+	}
+	for pattern, value := range route.queryWildcards {
+		query.Del(pattern)
+		query.Set(strings.ReplaceAll(pattern, "*", "example"), strings.ReplaceAll(value, "*", "example"))
+	}
+	example.RawQuery = query.Encode()
+	return example.String()
+}
+
+func (route *Route) exampleSegments(path string, i int) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name, constraint, _, _ := parseSegmentParam(segment)
+		if constraint != nil {
+			segments[index] = constraintExample(constraint, i)
+			continue
+		}
+		segments[index] = route.exampleValue(name, i)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (route *Route) exampleValue(name string, i int) string {
+	if doc, ok := route.docs[name]; ok && len(doc.Example) > 0 && i == 0 {
+		return doc.Example
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "uuid") || strings.Contains(lower, "guid"):
+		return fmt.Sprintf("00000000-0000-4000-8000-%012d", i+1)
+	case strings.Contains(lower, "email"):
+		return _exampleNames[i%len(_exampleNames)] + "@example.com"
+	case strings.Contains(lower, "date"):
+		return fmt.Sprintf("2024-01-%02d", i%28+1)
+	case strings.Contains(lower, "name") || strings.Contains(lower, "user"):
+		return _exampleNames[i%len(_exampleNames)]
+	case strings.HasSuffix(lower, "id") || strings.Contains(lower, "number") || strings.Contains(lower, "page"):
+		return strconv.Itoa(1000 + i)
+	case strings.Contains(lower, "slug"):
+		return fmt.Sprintf("sample-%s-%d", lower, i+1)
+	}
+	return fmt.Sprintf("%s-%d", lower, i+1)
+}
+
+// Gets a value satisfying a constraint, cycling through the samples of
+// the constraint that it accepts so that examples vary
+func constraintExample(constraint paramConstraint, i int) string {
+	values := []string{constraint.example()}
+	seen := map[string]bool{values[0]: true}
+	for _, sample := range constraint.samples() {
+		if !seen[sample] && constraint.accepts(sample) {
+			seen[sample] = true
+			values = append(values, sample)
+		}
+	}
+	return values[i%len(values)]
+}
+
+func exampleTypedValue(paramType ParamType, i int) string {
+	switch paramType {
+	case PARAM_INT:
+		return strconv.Itoa(i + 1)
+	case PARAM_FLOAT:
+		return strconv.FormatFloat(float64(i)+0.5, 'f', -1, 64)
+	case PARAM_BOOL:
+		return strconv.FormatBool(i%2 == 0)
+	case PARAM_UUID:
+		return fmt.Sprintf("00000000-0000-4000-8000-%012d", i+1)
+	}
+	return fmt.Sprintf("value-%d", i+1)
+}
@@ -0,0 +1,74 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username/orders/:orderId?type=cache&page=<int>")
+	docs := map[string]ParamDoc{"username": {Example: "ken"}}
+	if err := rt.Register(template, nil, WithParamDocs(docs)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	examples := rt.Examples(CreateHash(template), 5)
+	if len(examples) != 5 {
+		t.Log("wrong number of examples")
+		t.FailNow()
+	}
+	if examples[0] != "http://www.abcdefg.com/api/v1/users/ken/orders/1000?page=1&type=cache" {
+		t.Logf("unexpected example %s", examples[0])
+		t.FailNow()
+	}
+	seen := make(map[string]bool)
+	for _, example := range examples {
+		url, err := url.Parse(example)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if hash, err := rt.Find(url); err != nil || hash != CreateHash(template) {
+			t.Logf("example %s does not match its template", example)
+			t.FailNow()
+		}
+		seen[example] = true
+	}
+	if len(seen) != 5 {
+		t.Log("examples are not distinct")
+		t.FailNow()
+	}
+	if rt.Examples("unknown", 1) != nil {
+		t.Log("unknown hash produced examples")
+		t.FailNow()
+	}
+}
+
+func TestExamplesOfConstrainedParams(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse(`http://www.abcdefg.com/api/:version<semver-range "≥1 <3">/status`)
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	examples := rt.Examples(hash, 100)
+	if len(examples) < 2 || len(examples) == 100 {
+		t.Logf("expected a few distinct examples but found %d", len(examples))
+		t.FailNow()
+	}
+	seen := make(map[string]bool)
+	for _, example := range examples {
+		url, _ := url.Parse(example)
+		if found, err := rt.Find(url); err != nil || found != hash || seen[example] {
+			t.Logf("unexpected example %s", example)
+			t.FailNow()
+		}
+		seen[example] = true
+	}
+	if rt.Examples(hash, -1) != nil || rt.Examples(hash, 0) != nil {
+		t.Log("expected no examples for a non-positive count")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,125 @@
+package gtr
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+)
+
+// The Experiment struct splits the traffic of a route into buckets
+// based on the value of one of its parameters. The same parameter
+// value is always assigned to the same bucket.
+type Experiment struct {
+	Param   string   `json:"param"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// The Bucket struct is a single arm of an experiment
+// Params:
+//   - Name: The name of the bucket
+//   - Weight: The relative share of traffic assigned to the bucket
+//   - Overlay: Config values overriding the route config for the bucket
+type Bucket struct {
+	Name    string         `json:"name"`
+	Weight  int            `json:"weight"`
+	Overlay map[string]any `json:"overlay,omitempty"`
+}
+
+// The Assignment struct is the outcome of assigning a request to a bucket
+type Assignment struct {
+	Hash   string
+	Bucket string
+	Config map[string]any
+}
+
+// Attaches an experiment to a route
+func WithExperiment(experiment Experiment) RouteOption {
+	return func(route *Route) error {
+		if err := experiment.validate(route); err != nil {
+			return err
+		}
+		route.experiment = &experiment
+		return nil
+	}
+}
+
+// Attaches an experiment to an already registered route
+func (rt *RouteTable) SetExperiment(hash string, experiment Experiment) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	if err := rt.modify(hash, WithExperiment(experiment)); err != nil {
+		return err
+	}
+	return rt.recordUpdate(hash)
+}
+
+// Assigns a URL to a bucket of the experiment of its matching route
+// and resolves the bucket specific config
+func (rt *RouteTable) Assign(url *url.URL) (*Assignment, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, prt, err := rt.match("", url)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseRoute(prt)
+	experiment := route.experiment
+	if experiment == nil {
+		return nil, NO_EXPERIMENT
+	}
+	bucket := experiment.bucket(route.hash, route.values(prt)[experiment.Param])
+	conf := copyMap(rt.configs[route.hash])
+	for key, value := range bucket.Overlay {
+		conf[key] = value
+	}
+	assignment := Assignment{
+		Hash:   route.hash,
+		Bucket: bucket.Name,
+		Config: conf,
+	}
+	return &assignment, nil
+}
+
+func (experiment *Experiment) validate(route *Route) error {
+	found := false
+	for _, segment := range route.segments {
+		if segment.param() && segment.value == experiment.Param {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", UNKNOWN_PARAMETER, experiment.Param)
+	}
+	if len(experiment.Buckets) == 0 {
+		return fmt.Errorf("%w: no buckets", INVALID_EXPERIMENT)
+	}
+	for _, bucket := range experiment.Buckets {
+		if bucket.Weight <= 0 {
+			return fmt.Errorf("%w: bucket %s has no weight", INVALID_EXPERIMENT, bucket.Name)
+		}
+	}
+	return nil
+}
+
+func (experiment *Experiment) bucket(hash string, value string) *Bucket {
+	total := 0
+	for _, bucket := range experiment.Buckets {
+		total += bucket.Weight
+	}
+	hasher := fnv.New64a()
+	hasher.Write([]byte(hash))
+	hasher.Write([]byte{0})
+	hasher.Write([]byte(value))
+	point := int(hasher.Sum64() % uint64(total))
+	for i := range experiment.Buckets {
+		point -= experiment.Buckets[i].Weight
+		if point < 0 {
+			return &experiment.Buckets[i]
+		}
+	}
+	return &experiment.Buckets[len(experiment.Buckets)-1]
+}
@@ -0,0 +1,61 @@
+package gtr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": {"ttl": 10, "format": "json"},
+	})
+	hash, err := rt.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	experiment := Experiment{
+		Param: "username",
+		Buckets: []Bucket{
+			{Name: "control", Weight: 1},
+			{Name: "short-ttl", Weight: 1, Overlay: map[string]any{"ttl": 1}},
+		},
+	}
+	if err := rt.SetExperiment(hash, experiment); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	buckets := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		url, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/api/v1/users/user%d/details", i))
+		first, err := rt.Assign(url)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		second, _ := rt.Assign(url)
+		if first.Bucket != second.Bucket {
+			t.Log("assignment is not deterministic")
+			t.FailNow()
+		}
+		if first.Config["format"] != "json" {
+			t.Log("base config was not inherited")
+			t.FailNow()
+		}
+		if first.Bucket == "short-ttl" && first.Config["ttl"] != 1 {
+			t.Log("bucket overlay was not applied")
+			t.FailNow()
+		}
+		buckets[first.Bucket]++
+	}
+	if buckets["control"] == 0 || buckets["short-ttl"] == 0 {
+		t.Log("traffic was not split")
+		t.FailNow()
+	}
+	if err := rt.SetExperiment(hash, Experiment{Param: "id"}); !errors.Is(err, UNKNOWN_PARAMETER) {
+		t.Log("expected unknown parameter error")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,206 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// The CheckResult describes the outcome of a single check made while
+// comparing a route template against a URL
+type CheckResult string
+
+const (
+	CHECK_LITERAL  CheckResult = "literal"
+	CHECK_PARAM    CheckResult = "param"
+	CHECK_MISMATCH CheckResult = "mismatch"
+	CHECK_MISSING  CheckResult = "missing"
+	CHECK_INVALID  CheckResult = "invalid"
+	// The request has query params although the template allows none
+	CHECK_UNEXPECTED CheckResult = "unexpected"
+)
+
+// The Explanation struct describes how the route table resolved a URL
+type Explanation struct {
+	URL        string      `json:"url"`
+	Segments   int         `json:"segments"`
+	Candidates []Candidate `json:"candidates"`
+	Selected   string      `json:"selected,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// The Candidate struct describes the comparison of a URL against a
+// single route template
+type Candidate struct {
+	Hash     string `json:"hash"`
+	Template string `json:"template"`
+	Class    string `json:"class"`
+	Rank     int    `json:"rank"`
+	// CHECK_MISMATCH if the host of the URL does not match the template
+	Host     CheckResult    `json:"host,omitempty"`
+	Selected bool           `json:"selected"`
+	Segments []SegmentCheck `json:"segments"`
+	Query    []QueryCheck   `json:"query"`
+	// The number of query params the template constrains
+	QueryConstraints int `json:"queryConstraints"`
+	// Why the selected route was preferred, set for the other matching
+	// candidates only
+	Precedence Precedence `json:"precedence,omitempty"`
+}
+
+// The SegmentCheck struct describes the comparison of a path segment
+type SegmentCheck struct {
+	Index    int         `json:"index"`
+	Template string      `json:"template"`
+	Value    string      `json:"value"`
+	Result   CheckResult `json:"result"`
+}
+
+// The QueryCheck struct describes the comparison of a query param
+type QueryCheck struct {
+	Key      string      `json:"key"`
+	Expected string      `json:"expected"`
+	Actual   string      `json:"actual"`
+	Result   CheckResult `json:"result"`
+}
+
+// Explains step by step how a URL is resolved by Find
+func (rt *RouteTable) Explain(url *url.URL) *Explanation {
+	return rt.ExplainMethod("", url)
+}
+
+// Explains step by step how the URL of a request of a method is
+// resolved by FindMethod. Only the routes accepting the method are
+// listed as candidates.
+func (rt *RouteTable) ExplainMethod(method string, url *url.URL) *Explanation {
+	method = strings.ToUpper(method)
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	prt := ParseRoute(url)
+	prt.foldCase = rt.foldCase
+	explanation := Explanation{
+		URL:        url.String(),
+		Segments:   len(prt.segments),
+		Candidates: make([]Candidate, 0),
+	}
+	if len(rt.routes) == 0 {
+		explanation.Error = NO_URL_REGISTERED.Error()
+		return &explanation
+	}
+	routes, ok := rt.routes[len(prt.segments)]
+	if !ok {
+		explanation.Error = HOST_NOT_REGISTERED.Error()
+		return &explanation
+	}
+	selected := -1
+	var best *Route
+	bestRank := 0
+	compared := make([]*Route, 0, len(routes))
+	for _, route := range routes {
+		if !route.accepts(method) {
+			continue
+		}
+		candidate := Candidate{
+			Hash:             route.hash,
+			Template:         route.template,
+			Class:            route.class.String(),
+			Segments:         make([]SegmentCheck, 0, len(route.segments)),
+			Query:            make([]QueryCheck, 0, len(route.queryParams)),
+			QueryConstraints: route.queryWeight(),
+		}
+		candidate.Rank = compare(route, prt, &candidate)
+		if rt.comparator != nil {
+			candidate.Rank = rt.rank(route, prt)
+		}
+		if key, ok := rt.unexpected(route, prt); ok && rt.strictQuery {
+			candidate.Rank = 0
+			candidate.query(key, "", prt.queryParams[key], CHECK_UNEXPECTED)
+		}
+		sort.Slice(candidate.Query, func(i, j int) bool {
+			return candidate.Query[i].Key < candidate.Query[j].Key
+		})
+		preferred := rt.prefers(route, candidate.Rank, best, bestRank)
+		if rt.mode == MATCH_FIRST {
+			preferred = best == nil || route.order < best.order
+		}
+		if candidate.Rank != 0 && preferred {
+			best = route
+			bestRank = candidate.Rank
+			selected = len(explanation.Candidates)
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+		compared = append(compared, route)
+	}
+	if selected < 0 {
+		explanation.Error = NO_MATCH_FOUND.Error()
+		return &explanation
+	}
+	explanation.Candidates[selected].Selected = true
+	explanation.Selected = best.hash
+	for i, route := range compared {
+		candidate := &explanation.Candidates[i]
+		if i == selected || candidate.Rank == 0 {
+			continue
+		}
+		candidate.Precedence = precedence(best, bestRank, route, candidate.Rank)
+		if rt.mode == MATCH_FIRST {
+			candidate.Precedence = PRECEDENCE_ORDER
+		}
+	}
+	return &explanation
+}
+
+// Explains how a URL is resolved as JSON
+func (rt *RouteTable) ExplainJSON(url *url.URL) ([]byte, error) {
+	return json.Marshal(rt.Explain(url))
+}
+
+// Records the check of every path segment of a parsed URL against a
+// template, gets the rank of the path, and whether every segment matched
+func (candidate *Candidate) path(template *Route, route *Route) (int, bool) {
+	rank := 0
+	matched := true
+	for position, segment := range template.segments {
+		value := route.segments[position].value
+		switch {
+		case segment.param() && segment.constraint != nil && !segment.constraint.accepts(value):
+			matched = false
+			candidate.segment(segment.index, segment.pattern(), value, CHECK_INVALID)
+		case segment.param():
+			rank += 1
+			candidate.segment(segment.index, segment.pattern(), value, CHECK_PARAM)
+		case segment.value != route.segment(position):
+			matched = false
+			candidate.segment(segment.index, segment.value, value, CHECK_MISMATCH)
+		default:
+			rank += 2
+			candidate.segment(segment.index, segment.value, value, CHECK_LITERAL)
+		}
+	}
+	return rank, matched
+}
+
+func (candidate *Candidate) segment(index int, template string, value string, result CheckResult) {
+	if candidate == nil {
+		return
+	}
+	candidate.Segments = append(candidate.Segments, SegmentCheck{
+		Index:    index,
+		Template: template,
+		Value:    value,
+		Result:   result,
+	})
+}
+
+func (candidate *Candidate) query(key string, expected string, actual string, result CheckResult) {
+	if candidate == nil {
+		return
+	}
+	candidate.Query = append(candidate.Query, QueryCheck{
+		Key:      key,
+		Expected: expected,
+		Actual:   actual,
+		Result:   result,
+	})
+}
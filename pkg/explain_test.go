@@ -0,0 +1,82 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestExplainJSON(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": nil,
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=fresh": nil,
+		"http://www.abcdefg.com/api/v1/posts/:id/details":                  nil,
+	})
+	data, err := rt.ExplainJSON(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	explanation := Explanation{}
+	if err := json.Unmarshal(data, &explanation); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(explanation.Candidates) != 3 || len(explanation.Selected) == 0 {
+		t.Logf("unexpected explanation %s", data)
+		t.FailNow()
+	}
+	for _, candidate := range explanation.Candidates {
+		if candidate.Selected != (candidate.Hash == explanation.Selected) {
+			t.Log("selected candidate is inconsistent")
+			t.FailNow()
+		}
+		if candidate.Selected && (candidate.Rank != 9 || candidate.Segments[3].Result != CHECK_PARAM) {
+			t.Logf("selected candidate explained incorrectly %+v", candidate)
+			t.FailNow()
+		}
+		if !candidate.Selected && candidate.Rank != 0 {
+			t.Log("rejected candidate has a rank")
+			t.FailNow()
+		}
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/unknown")
+	if rt.Explain(url).Error != HOST_NOT_REGISTERED.Error() {
+		t.Log("explanation error is invalid")
+		t.FailNow()
+	}
+}
+
+func TestExplainMethod(t *testing.T) {
+	rt := newRouteTable()
+	specific, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	generic, _ := url.Parse("http://www.abcdefg.com/api/v1/:kind/:id")
+	if err := rt.RegisterMethod("GET", specific, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(generic, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken")
+	get, _ := rt.FindMethod("GET", url)
+	for _, method := range []string{"", "get", "POST"} {
+		hash, err := rt.FindMethod(method, url)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		explanation := rt.ExplainMethod(method, url)
+		if explanation.Selected != hash {
+			t.Logf("expected the explanation of %q to select %s but found %s", method, hash, explanation.Selected)
+			t.FailNow()
+		}
+		for _, candidate := range explanation.Candidates {
+			if candidate.Hash == get && method != "get" {
+				t.Logf("expected the GET route not to be a candidate of %q", method)
+				t.FailNow()
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gtr
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Serializes the route table, that is its fragments and every route
+// with its config and options, so that it can be persisted and
+// restored through Import, for example across restarts. The output is
+// a JSON rule file (see LoadRules) with the templates written with
+// their fragments expanded.
+func (rt *RouteTable) Export() ([]byte, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.export()
+}
+
+// Serializes the route table
+// Must be called with the read lock held
+func (rt *RouteTable) export() ([]byte, error) {
+	file := RuleFile{Fragments: copyMap(rt.fragments), Routes: make([]Rule, 0, len(rt.index))}
+	for _, route := range rt.sorted() {
+		file.Routes = append(file.Routes, route.rule(rt.configs[route.hash]))
+	}
+	return json.Marshal(file)
+}
+
+// Restores a route table serialized through Export. The table should
+// be created with the options of the exported table, since options
+// such as the hasher are not serialized. Configs are restored as they
+// are decoded from JSON, so numbers become float64 and typed configs
+// must be registered again through RegisterTyped.
+// The routes are merged into the table (see Merge): routes conflicting
+// with the registered ones are left out and listed in the returned
+// report rather than overwriting them. Fragments that are already
+// defined keep their paths.
+func (rt *RouteTable) Import(data []byte) (*ConflictReport, error) {
+	rt.mutex.RLock()
+	scratch := rt.empty()
+	rt.mutex.RUnlock()
+	if err := scratch.LoadRules(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	report, err := rt.Merge(scratch)
+	if err != nil {
+		return report, err
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	for name, path := range scratch.fragments {
+		if _, ok := rt.fragments[name]; ok {
+			continue
+		}
+		if err := rt.defineFragment(name, path); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
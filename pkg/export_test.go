@@ -0,0 +1,95 @@
+package gtr
+
+import "testing"
+
+func TestExportImport(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.DefineFragment("userPath", "/users/:username"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/@userPath/details?type=cache"), map[string]any{"ttl": 10, "tags": []any{"users"}}, WithName("user-details"), WithMethodOverlay("POST", map[string]any{"bypass": true})); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.RegisterMethod("POST", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id<int>"), map[string]any{"ttl": 5}, WithClass(CLASS_USER)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	data, err := rt.Export()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	restored := newRouteTable()
+	if _, err := restored.Import(data); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(restored.Routes()) != 2 {
+		t.Logf("expected 2 routes but found %d", len(restored.Routes()))
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if found, err := restored.Find(PrepareURL(t)); err != nil || found != hash {
+		t.Logf("expected %s but found %s: %v", hash, found, err)
+		t.FailNow()
+	}
+	if conf := restored.GetConfig(hash); conf["ttl"] != 10.0 || len(conf["tags"].([]any)) != 1 {
+		t.Logf("unexpected config %v", conf)
+		t.FailNow()
+	}
+	if !restored.GetPolicy(hash, "POST").Bypass() {
+		t.Log("expected the method overlay to be restored")
+		t.FailNow()
+	}
+	if route, err := restored.LookupName("user-details"); err != nil || route.Hash() != hash {
+		t.Logf("expected the name to be restored: %v", err)
+		t.FailNow()
+	}
+	posts, err := restored.FindMethod("POST", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/42"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if route, _ := restored.Lookup(posts); route.Class() != CLASS_USER {
+		t.Log("expected the class to be restored")
+		t.FailNow()
+	}
+	if err := restored.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v2/@userPath"), nil); err != nil {
+		t.Logf("expected the fragments to be restored: %v", err)
+		t.FailNow()
+	}
+}
+
+func TestImportConflicts(t *testing.T) {
+	source := newRouteTable()
+	if err := source.Register(PrepareURLTemplate(t), map[string]any{"ttl": 20}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	data, err := source.Export()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	rt := newRouteTable()
+	if err := rt.Register(PrepareURLTemplate(t), map[string]any{"ttl": 10.0}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	report, err := rt.Import(data)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Kind != CONFLICT_CONFIG || report.Conflicts[0].Incoming.Config["ttl"] != 20.0 {
+		t.Logf("expected a config conflict but found %v", report.Conflicts)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["ttl"] != 10.0 {
+		t.Log("expected the registered config to be kept")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,172 @@
+package gtr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The extensionConstraint restricts a path parameter followed by a
+// file extension, written `:name.:ext`, to the values carrying one of
+// the given extensions, for example `:name.:ext(pdf|csv)`. The name may
+// have its own constraint, for example `:id<int>.:ext(json)`.
+type extensionConstraint struct {
+	// The constraint of the value before the extension, if any
+	base paramConstraint
+	// The accepted extensions, or none to accept any extension
+	extensions []string
+}
+
+// Splits a parameter segment into the parameter name, its constraint,
+// and the name of its extension parameter, if any
+func parseSegmentParam(value string) (string, paramConstraint, string, error) {
+	base, extension, ok := strings.Cut(value, ".:")
+	if !ok {
+		name, constraint, err := parseParam(value)
+		return name, constraint, "", err
+	}
+	name, constraint, err := parseParam(base)
+	if err != nil {
+		return "", nil, "", err
+	}
+	extensions := []string(nil)
+	if open := strings.Index(extension, "("); open >= 0 {
+		if !strings.HasSuffix(extension, ")") {
+			return "", nil, "", fmt.Errorf("%w: unterminated extensions %s", INVALID_VALUE, value)
+		}
+		extensions = strings.Split(extension[open+1:len(extension)-1], "|")
+		extension = extension[:open]
+		for _, candidate := range extensions {
+			if len(candidate) == 0 || strings.Contains(candidate, ".") {
+				return "", nil, "", fmt.Errorf("%w: invalid extension %q in %s", INVALID_VALUE, candidate, value)
+			}
+		}
+	}
+	if len(name) == 0 || len(extension) == 0 || strings.ContainsAny(extension, ".:<>") {
+		return "", nil, "", fmt.Errorf("%w: invalid extension parameter %s", INVALID_VALUE, value)
+	}
+	return name, extensionConstraint{base: constraint, extensions: extensions}, extension, nil
+}
+
+// Splits a value into the value before its extension and the extension
+func splitExtension(value string) (string, string, bool) {
+	dot := strings.LastIndex(value, ".")
+	if dot <= 0 || dot == len(value)-1 {
+		return "", "", false
+	}
+	return value[:dot], value[dot+1:], true
+}
+
+func (constraint extensionConstraint) accepts(value string) bool {
+	base, extension, ok := splitExtension(value)
+	if !ok || !constraint.allows(extension) {
+		return false
+	}
+	return constraint.base == nil || constraint.base.accepts(base)
+}
+
+// Checks whether an extension is accepted
+func (constraint extensionConstraint) allows(extension string) bool {
+	if len(constraint.extensions) == 0 {
+		return true
+	}
+	for _, candidate := range constraint.extensions {
+		if candidate == extension {
+			return true
+		}
+	}
+	return false
+}
+
+func (constraint extensionConstraint) example() string {
+	base := "file"
+	if constraint.base != nil {
+		base = constraint.base.example()
+	}
+	extension := "txt"
+	if len(constraint.extensions) > 0 {
+		extension = constraint.extensions[0]
+	}
+	return base + "." + extension
+}
+
+func (constraint extensionConstraint) samples() []string {
+	bases := []string{"file"}
+	if constraint.base != nil {
+		bases = constraint.base.samples()
+	}
+	extensions := append([]string{"txt"}, constraint.extensions...)
+	samples := make([]string, 0, len(bases)*len(extensions)+1)
+	for _, base := range bases {
+		for _, extension := range extensions {
+			samples = append(samples, base+"."+extension)
+		}
+	}
+	return append(samples, "file")
+}
+
+func (constraint extensionConstraint) String() string {
+	value := ""
+	if constraint.base != nil {
+		value = "<" + constraint.base.String() + ">"
+	}
+	value += ".:"
+	if len(constraint.extensions) > 0 {
+		value += "(" + strings.Join(constraint.extensions, "|") + ")"
+	}
+	return value
+}
+
+// Writes the parameter segment of a name and its extension parameter
+// the way it is written in templates
+func (constraint extensionConstraint) template(name string, extension string) string {
+	value := ":" + name
+	if constraint.base != nil {
+		value += "<" + constraint.base.String() + ">"
+	}
+	value += ".:" + extension
+	if len(constraint.extensions) > 0 {
+		value += "(" + strings.Join(constraint.extensions, "|") + ")"
+	}
+	return value
+}
+
+// Adds a config overlay for the requests of a file extension to a
+// route whose last path segment has an extension parameter, for
+// example to cache `pdf` exports longer than `csv` ones (see
+// MatchPolicy)
+func WithExtensionOverlay(extension string, overlay map[string]any) RouteOption {
+	return func(route *Route) error {
+		constraint, ok := route.extension()
+		if !ok {
+			return fmt.Errorf("%w: %s has no extension parameter", INVALID_VALUE, route.template)
+		}
+		if !constraint.allows(extension) {
+			return fmt.Errorf("%w: %s does not accept the %s extension", INVALID_VALUE, route.template, extension)
+		}
+		if route.extensionOverlays == nil {
+			route.extensionOverlays = make(map[string]map[string]any)
+		}
+		route.extensionOverlays[extension] = overlay
+		return nil
+	}
+}
+
+// Gets the constraint of the extension parameter of the route, if any
+func (route *Route) extension() (extensionConstraint, bool) {
+	if len(route.segments) == 0 {
+		return extensionConstraint{}, false
+	}
+	constraint, ok := route.segments[len(route.segments)-1].constraint.(extensionConstraint)
+	return constraint, ok
+}
+
+// Gets the value of a parameter segment, or the value before the
+// extension of a segment with an extension parameter, and the
+// extension
+func (segment segment) split(value string) (string, string) {
+	if len(segment.extension) == 0 {
+		return value, ""
+	}
+	base, extension, _ := splitExtension(value)
+	return base, extension
+}
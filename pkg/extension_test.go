@@ -0,0 +1,105 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExtensionParams(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/reports/:name.:ext(pdf|csv)")
+	err := rt.Register(template, map[string]any{"ttl": "10s"}, WithExtensionOverlay("pdf", map[string]any{"ttl": "1h"}), WithExtensionOverlay("csv", map[string]any{"ttl": "1m"}))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	tests := []struct {
+		url       string
+		extension string
+		ttl       time.Duration
+	}{
+		{"http://www.abcdefg.com/reports/q3.2024.pdf", "pdf", time.Hour},
+		{"http://www.abcdefg.com/reports/q3.csv", "csv", time.Minute},
+	}
+	for _, test := range tests {
+		match, err := rt.Match(PrepareURLFrom(t, test.url))
+		if err != nil {
+			t.Logf("expected %s to match: %v", test.url, err)
+			t.FailNow()
+		}
+		if match.Extension != test.extension || match.Params["ext"] != test.extension || len(match.Params["name"]) == 0 {
+			t.Logf("unexpected params %v of %s", match.Params, test.url)
+			t.FailNow()
+		}
+		if ttl, _ := rt.MatchPolicy(match, "GET").TTL(); ttl != test.ttl {
+			t.Logf("expected a ttl of %v for %s but found %v", test.ttl, test.url, ttl)
+			t.FailNow()
+		}
+	}
+	if ttl, _ := rt.GetPolicy(CreateHash(template), "GET").TTL(); ttl != 10*time.Second {
+		t.Log("expected the route config without an extension")
+		t.FailNow()
+	}
+	for _, raw := range []string{"http://www.abcdefg.com/reports/q3.xml", "http://www.abcdefg.com/reports/q3", "http://www.abcdefg.com/reports/.pdf"} {
+		if _, err := rt.Find(PrepareURLFrom(t, raw)); err == nil {
+			t.Logf("expected %s not to match", raw)
+			t.FailNow()
+		}
+	}
+	route, _ := rt.Lookup(CreateHash(template))
+	built, err := route.Build(map[string]string{"name": "q3", "ext": "pdf"}, nil)
+	if err != nil || built.Path != "/reports/q3.pdf" {
+		t.Logf("unexpected URL %v: %v", built, err)
+		t.FailNow()
+	}
+	if _, err := route.Build(map[string]string{"name": "q3", "ext": "xml"}, nil); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	if format := route.Format(PARAM_STYLE_BRACE); format != "http://www.abcdefg.com/reports/{name}.{ext(pdf|csv)}" || CreateHash(PrepareURLFrom(t, format)) != route.Hash() {
+		t.Logf("unexpected format %s", format)
+		t.FailNow()
+	}
+}
+
+func TestTypedExtensionParams(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/exports/:id<int>.:format"), nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/exports/42.json"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Typed["id"] != int64(42) || match.Params["format"] != "json" || match.Extension != "json" {
+		t.Logf("unexpected match %v", match)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/exports/abc.json")); err == nil {
+		t.Log("expected the name constraint to be checked")
+		t.FailNow()
+	}
+}
+
+func TestInvalidExtensionParams(t *testing.T) {
+	rt := newRouteTable()
+	tests := []struct {
+		template string
+		opts     []RouteOption
+	}{
+		{"http://www.abcdefg.com/reports/:name.:ext(pdf", nil},
+		{"http://www.abcdefg.com/reports/:name.:ext()", nil},
+		{"http://www.abcdefg.com/reports/:name.:ext/latest", nil},
+		{"http://www.abcdefg.com/reports/:name.:ext(pdf|csv)", []RouteOption{WithExtensionOverlay("xml", nil)}},
+		{"http://www.abcdefg.com/reports/:name", []RouteOption{WithExtensionOverlay("pdf", nil)}},
+	}
+	for _, test := range tests {
+		if err := rt.Register(PrepareURLFrom(t, test.template), nil, test.opts...); !errors.Is(err, INVALID_VALUE) {
+			t.Logf("expected INVALID_VALUE for %s but found %v", test.template, err)
+			t.FailNow()
+		}
+	}
+}
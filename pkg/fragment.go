@@ -0,0 +1,75 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Defines a named path fragment that templates may reference as a
+// whole segment, for example `@userPath`. References are expanded when
+// a template is registered, so a fragment must be defined before the
+// templates using it. Fragments may reference other fragments.
+// Params:
+//   - name: The name of the fragment, with or without the leading `@`
+//   - path: The path the fragment expands to, for example `/users/:username`
+func (rt *RouteTable) DefineFragment(name string, path string) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	return rt.defineFragment(name, path)
+}
+
+func (rt *RouteTable) defineFragment(name string, path string) error {
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	name = strings.TrimPrefix(name, "@")
+	if len(name) == 0 || strings.Contains(name, "/") {
+		return fmt.Errorf("%w: invalid fragment name %s", INVALID_VALUE, name)
+	}
+	if rt.fragments == nil {
+		rt.fragments = make(map[string]string)
+	}
+	rt.fragments[name] = strings.Trim(path, "/")
+	return nil
+}
+
+// Replaces the fragment references of a template with their paths
+func (rt *RouteTable) expandFragments(template *url.URL) (*url.URL, error) {
+	if len(template.Opaque) > 0 || !strings.Contains(template.Path, "@") {
+		return template, nil
+	}
+	path, err := rt.expand(template.Path, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	expanded := *template
+	expanded.Path = path
+	expanded.RawPath = ""
+	return &expanded, nil
+}
+
+func (rt *RouteTable) expand(path string, visiting map[string]bool) (string, error) {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, "@") {
+			continue
+		}
+		name := segment[1:]
+		fragment, ok := rt.fragments[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", UNKNOWN_FRAGMENT, name)
+		}
+		if visiting[name] {
+			return "", fmt.Errorf("%w: fragment %s references itself", INVALID_VALUE, name)
+		}
+		visiting[name] = true
+		expanded, err := rt.expand(fragment, visiting)
+		if err != nil {
+			return "", err
+		}
+		delete(visiting, name)
+		segments[index] = expanded
+	}
+	return strings.Join(segments, "/"), nil
+}
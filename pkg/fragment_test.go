@@ -0,0 +1,53 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestFragments(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.DefineFragment("@userPath", "/users/:username"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.DefineFragment("api", "/api/v1/@userPath"); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	template, _ := url.Parse("http://www.abcdefg.com/@api/details?type=cache")
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err := rt.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if hash != CreateHash(PrepareURLTemplate(t)) {
+		t.Log("expanded template hashes differently from the literal template")
+		t.FailNow()
+	}
+}
+
+func TestFragmentErrors(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/@missing/details")
+	if err := rt.Register(template, nil); !errors.Is(err, UNKNOWN_FRAGMENT) {
+		t.Logf("expected UNKNOWN_FRAGMENT but found %v", err)
+		t.FailNow()
+	}
+	rt.DefineFragment("a", "/x/@b")
+	rt.DefineFragment("b", "/@a")
+	template, _ = url.Parse("http://www.abcdefg.com/@a")
+	if err := rt.Register(template, nil); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.DefineFragment("@", "/x"); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
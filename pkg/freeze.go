@@ -0,0 +1,27 @@
+package gtr
+
+// Freezes the route table. Once frozen, every mutation of its routes,
+// configs, or fragments fails with TABLE_FROZEN, including the events
+// applied through Replay. The settings of the table itself, that is
+// its comparator, match mode, query mode, and ignored query params,
+// are exempt and may still be changed. A frozen table cannot be
+// unfrozen.
+func (rt *RouteTable) Freeze() {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.frozen = true
+}
+
+// Checks whether the route table is frozen
+func (rt *RouteTable) Frozen() bool {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.frozen
+}
+
+func (rt *RouteTable) checkFrozen() error {
+	if rt.frozen {
+		return TABLE_FROZEN
+	}
+	return nil
+}
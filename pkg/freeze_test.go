@@ -0,0 +1,65 @@
+package gtr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 10}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	rt.Freeze()
+	if !rt.Frozen() {
+		t.Log("expected the table to be frozen")
+		t.FailNow()
+	}
+	other := PrepareTable(t, map[string]map[string]any{"http://www.abcdefg.com/api/v1/posts/:id": nil})
+	_, mergeErr := rt.Merge(other)
+	_, rehashErr := rt.Rehash(Hasher{Version: HASH_V4})
+	event, _ := json.Marshal(Event{Op: EVENT_UNREGISTER, Rule: Rule{Template: rt.Routes()[0].Template()}})
+	mutations := map[string]error{
+		"Register":         rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil),
+		"RegisterFrom":     rt.RegisterFrom("tenant", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil),
+		"PatchConfig":      rt.PatchConfig(hash, []byte(`{"ttl": 20}`)),
+		"SetMethodOverlay": rt.SetMethodOverlay(hash, "POST", map[string]any{"bypass": true}),
+		"SetExperiment":    rt.SetExperiment(hash, Experiment{Param: "username", Buckets: []Bucket{{Name: "a", Weight: 1}}}),
+		"DefineFragment":   rt.DefineFragment("users", "/users"),
+		"Merge":            mergeErr,
+		"Rehash":           rehashErr,
+		"Replay":           rt.Replay(bytes.NewReader(event)),
+		"UnregisterHash":   rt.UnregisterHash(hash),
+	}
+	for name, err := range mutations {
+		if !errors.Is(err, TABLE_FROZEN) {
+			t.Logf("expected TABLE_FROZEN from %s but found %v", name, err)
+			t.FailNow()
+		}
+	}
+	if len(rt.Routes()) != 1 || rt.GetConfig(hash)["ttl"] != 10 {
+		t.Log("frozen table was mutated")
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	request := httptest.NewRequest(http.MethodPatch, "/routes/"+hash, strings.NewReader(`{"ttl": 20}`))
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	response := httptest.NewRecorder()
+	NewAdminHandler(rt).ServeHTTP(response, request)
+	if response.Code != http.StatusConflict {
+		t.Logf("expected 409 but found %d", response.Code)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,47 @@
+package gtr
+
+import "net/url"
+
+// The GenericTable wraps a route table whose routes all have a config
+// of type T, so that configs are read back without type assertions.
+// Configs are stored through RegisterTyped, which keeps the map view
+// served by the underlying table.
+type GenericTable[T any] struct {
+	rt *RouteTable
+}
+
+// Creates a route table whose routes have a config of type T
+func NewGenericTable[T any](opts ...Option) *GenericTable[T] {
+	return &GenericTable[T]{
+		rt: NewRouteTable(opts...),
+	}
+}
+
+// Gets the underlying route table, for example to explain lookups
+func (table *GenericTable[T]) Table() *RouteTable {
+	return table.rt
+}
+
+// Registers a new route with its config
+// Registering an already registered URL is a no-op
+func (table *GenericTable[T]) Register(url *url.URL, conf T, opts ...RouteOption) error {
+	return RegisterTyped(table.rt, url, conf, opts...)
+}
+
+// Gets the config of a route
+// The second return value is false if the hash is not registered
+func (table *GenericTable[T]) GetConfig(hash string) (T, bool) {
+	return GetTyped[T](table.rt, hash)
+}
+
+// Matches a URL against the route table and gets the config of the
+// matching route alongside the match
+func (table *GenericTable[T]) Match(url *url.URL) (*MatchResult, T, error) {
+	match, err := table.rt.Match(url)
+	if err != nil {
+		var zero T
+		return nil, zero, err
+	}
+	conf, _ := table.GetConfig(match.Hash)
+	return match, conf, nil
+}
@@ -0,0 +1,37 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenericTable(t *testing.T) {
+	table := NewGenericTable[cacheConfig]()
+	template := PrepareURLTemplate(t)
+	if err := table.Register(template, cacheConfig{TTL: 10, Format: "json"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	conf, ok := table.GetConfig(CreateHash(template))
+	if !ok || conf.TTL != 10 || conf.Format != "json" {
+		t.Logf("unexpected config %v", conf)
+		t.FailNow()
+	}
+	if _, ok := table.GetConfig("unknown"); ok {
+		t.Log("expected unknown hashes to have no config")
+		t.FailNow()
+	}
+	match, conf, err := table.Match(PrepareURL(t))
+	if err != nil || match.Hash != CreateHash(template) || conf.TTL != 10 {
+		t.Logf("unexpected match %v %v %v", match, conf, err)
+		t.FailNow()
+	}
+	if _, _, err := table.Match(PrepareURLFrom(t, "http://www.abcdefg.com/unknown")); !errors.Is(err, NO_MATCH_FOUND) && !errors.Is(err, HOST_NOT_REGISTERED) {
+		t.Logf("expected no match but found %v", err)
+		t.FailNow()
+	}
+	if table.Table().GetConfig(CreateHash(template))["ttl"] != 10.0 {
+		t.Log("expected the underlying table to serve the map view of the config")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,162 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// The GraphQLOperation struct describes the operation a GraphQL
+// request executes
+type GraphQLOperation struct {
+	Type string
+	Name string
+}
+
+const (
+	// The name given to operations that are not named
+	GRAPHQL_ANONYMOUS = "_anonymous"
+)
+
+// Gets the virtual URL of the operation. Operations are routed as
+// `graphql:<name>?type=<type>`, so the template `graphql:GetUser`
+// matches the operation regardless of its type, while
+// `graphql:GetUser?type=query` only matches queries.
+func (operation *GraphQLOperation) URL() *url.URL {
+	return virtualURL("graphql", operation.Name, url.Values{"type": {operation.Type}})
+}
+
+// Parses the operation a GraphQL request body executes
+func ParseGraphQLOperation(body []byte) (*GraphQLOperation, error) {
+	request := struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("%w: %s", INVALID_BODY, err.Error())
+	}
+	operations := parseGraphQLDocument(request.Query)
+	if len(request.OperationName) > 0 {
+		for _, operation := range operations {
+			if operation.Name == request.OperationName {
+				return &operation, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", UNKNOWN_OPERATION, request.OperationName)
+	}
+	if len(operations) != 1 {
+		return nil, fmt.Errorf("%w: document defines %d operations", UNKNOWN_OPERATION, len(operations))
+	}
+	return &operations[0], nil
+}
+
+// Matches a GraphQL request body against the virtual routes of the table
+func (rt *RouteTable) MatchGraphQL(body []byte) (*MatchResult, error) {
+	operation, err := ParseGraphQLOperation(body)
+	if err != nil {
+		return nil, err
+	}
+	return rt.Match(operation.URL())
+}
+
+// Lists the operations defined in a GraphQL document. Only the top
+// level of the document is inspected; selection sets are skipped.
+func parseGraphQLDocument(document string) []GraphQLOperation {
+	operations := make([]GraphQLOperation, 0)
+	depth := 0
+	parens := 0
+	var pending *GraphQLOperation
+	for _, token := range tokenizeGraphQL(document) {
+		switch token {
+		case "{":
+			// Braces within the variables or the directive arguments of
+			// an operation are object values rather than its selection set
+			if parens > 0 {
+				depth++
+				continue
+			}
+			if depth == 0 && pending == nil {
+				pending = &GraphQLOperation{Type: "query"}
+			}
+			if depth == 0 && pending != nil {
+				if len(pending.Name) == 0 {
+					pending.Name = GRAPHQL_ANONYMOUS
+				}
+				if len(pending.Type) > 0 {
+					operations = append(operations, *pending)
+				}
+				pending = nil
+			}
+			depth++
+		case "}":
+			depth--
+		case "(":
+			parens++
+		case ")":
+			parens--
+		default:
+			if depth != 0 || parens != 0 {
+				continue
+			}
+			switch {
+			case pending == nil && (token == "query" || token == "mutation" || token == "subscription"):
+				pending = &GraphQLOperation{Type: token}
+			case pending == nil && token == "fragment":
+				pending = &GraphQLOperation{}
+			case pending != nil && len(pending.Name) == 0 && isGraphQLName(token):
+				pending.Name = token
+			}
+		}
+	}
+	return operations
+}
+
+// Splits a GraphQL document into names and braces, dropping
+// comments, strings and other punctuation
+func tokenizeGraphQL(document string) []string {
+	tokens := make([]string, 0)
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == '#':
+			for i < len(document) && document[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			if i+2 < len(document) && document[i+1] == '"' && document[i+2] == '"' {
+				i += 3
+				for i < len(document) && !(i+2 < len(document) && document[i] == '"' && document[i+1] == '"' && document[i+2] == '"') {
+					i++
+				}
+				i += 3
+				continue
+			}
+			i++
+			for i < len(document) && document[i] != '"' {
+				if document[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '{' || c == '}' || c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '_' || c == '$' || c == '@' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			start := i
+			i++
+			for i < len(document) && (document[i] == '_' || (document[i] >= 'a' && document[i] <= 'z') || (document[i] >= 'A' && document[i] <= 'Z') || (document[i] >= '0' && document[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, document[start:i])
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isGraphQLName(token string) bool {
+	c := token[0]
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
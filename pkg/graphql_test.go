@@ -0,0 +1,59 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseGraphQLOperation(t *testing.T) {
+	tests := map[string]GraphQLOperation{
+		`{"query":"query GetUser($id: ID = \"{\") { user(id: $id) { name } }"}`:                                            {Type: "query", Name: "GetUser"},
+		`{"query":"mutation ($id: ID) { delete(id: $id) }"}`:                                                               {Type: "mutation", Name: GRAPHQL_ANONYMOUS},
+		`{"query":"query Search($f: In = {a: 1, b: {c: 2}}) { search(filter: $f) { id } }"}`:                               {Type: "query", Name: "Search"},
+		`{"query":"mutation Save @log(opts: {level: 1}) { save }"}`:                                                        {Type: "mutation", Name: "Save"},
+		`{"query":"{ me { id } }"}`:                                                                                        {Type: "query", Name: GRAPHQL_ANONYMOUS},
+		`{"query":"# query Fake\nfragment F on User { id } query A { me { ...F } } mutation B { x }","operationName":"B"}`: {Type: "mutation", Name: "B"},
+	}
+	for body, expected := range tests {
+		operation, err := ParseGraphQLOperation([]byte(body))
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if *operation != expected {
+			t.Logf("expected %v but found %v", expected, *operation)
+			t.FailNow()
+		}
+	}
+	if _, err := ParseGraphQLOperation([]byte(`{"query":"query A { a } query B { b }"}`)); !errors.Is(err, UNKNOWN_OPERATION) {
+		t.Log("expected ambiguous document to fail")
+		t.FailNow()
+	}
+}
+
+func TestMatchGraphQL(t *testing.T) {
+	rt := newRouteTable()
+	user, _ := url.Parse("graphql:GetUser")
+	mutation, _ := url.Parse("graphql:UpdateUser?type=mutation")
+	rt.Register(user, map[string]any{"ttl": 10})
+	rt.Register(mutation, map[string]any{"bypass": true})
+	match, err := rt.MatchGraphQL([]byte(`{"query":"query GetUser { user { name } }"}`))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if rt.GetConfig(match.Hash)["ttl"] != 10 {
+		t.Log("operation routed to the wrong route")
+		t.FailNow()
+	}
+	if _, err := rt.MatchGraphQL([]byte(`{"query":"query UpdateUser { user { name } }"}`)); err == nil {
+		t.Log("query matched a mutation only route")
+		t.FailNow()
+	}
+	regular, _ := url.Parse("http://www.abcdefg.com/GetUser")
+	if _, err := rt.Match(regular); err == nil {
+		t.Log("virtual route matched a regular path")
+		t.FailNow()
+	}
+}
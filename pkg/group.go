@@ -0,0 +1,163 @@
+package gtr
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The GroupStats struct rolls up the lookups of a group of routes, so
+// that capacity and cache efficiency can be reviewed per service
+// rather than per route
+// Params:
+//   - Routes: The number of registered routes in the group
+//   - Matches: The number of lookups matched by the routes of the group
+//   - Ranks: The number of matches per rank of the matching route.
+//     Literal segments rank 2 and parameters 1, so matches of low
+//     ranks are absorbed by generic routes.
+//   - Unmatched: The number of lookups within the group that matched
+//     no route
+type GroupStats struct {
+	Group     string      `json:"group"`
+	Routes    int         `json:"routes"`
+	Matches   int         `json:"matches"`
+	Ranks     map[int]int `json:"ranks"`
+	Unmatched int         `json:"unmatched"`
+}
+
+type groupCounter struct {
+	name     string
+	selector *selector
+	// The segments of the selector matching URLs rather than templates
+	patterns  []string
+	matches   int
+	ranks     map[int]int
+	unmatched int
+}
+
+type groupTracker struct {
+	mutex  sync.Mutex
+	groups []*groupCounter
+	// The groups of every matched route, keyed by hash
+	members map[string][]*groupCounter
+}
+
+// Enables tracking of the lookups made through Find and Match per
+// group of routes, reported through Stats. Groups are named selectors
+// (see OverrideConfig), for example `/api/v1/users/**`, and a route
+// counts towards every group selecting it. Lookups matching no route
+// count towards the groups whose selector matches the URL. Passing no
+// groups disables tracking.
+func (rt *RouteTable) TrackGroups(groups map[string]string) error {
+	if len(groups) == 0 {
+		rt.mutex.Lock()
+		defer rt.mutex.Unlock()
+		rt.groups = nil
+		return nil
+	}
+	tracker := &groupTracker{
+		groups:  make([]*groupCounter, 0, len(groups)),
+		members: make(map[string][]*groupCounter),
+	}
+	for name, raw := range groups {
+		selector, err := parseSelector(raw)
+		if err != nil {
+			return err
+		}
+		patterns := make([]string, len(selector.segments))
+		for index, segment := range selector.segments {
+			patterns[index] = segment
+			// Parameters of a selector match any value
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{") {
+				patterns[index] = "*"
+			}
+		}
+		tracker.groups = append(tracker.groups, &groupCounter{
+			name:     name,
+			selector: selector,
+			patterns: patterns,
+			ranks:    make(map[int]int),
+		})
+	}
+	sort.Slice(tracker.groups, func(i, j int) bool {
+		return tracker.groups[i].name < tracker.groups[j].name
+	})
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.groups = tracker
+	return nil
+}
+
+// Records a match against the groups of the matching route
+func (tracker *groupTracker) observe(route *Route) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	members, ok := tracker.members[route.hash]
+	if !ok {
+		members = make([]*groupCounter, 0)
+		for _, group := range tracker.groups {
+			if group.selector.selects(route) {
+				members = append(members, group)
+			}
+		}
+		tracker.members[route.hash] = members
+	}
+	rank := len(route.segments) + route.literals
+	for _, group := range members {
+		group.matches++
+		group.ranks[rank]++
+	}
+}
+
+// Records a lookup that matched no route against the groups whose
+// selector matches the URL
+func (tracker *groupTracker) miss(url *url.URL) {
+	host := strings.ToLower(url.Host)
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(url.Path, "/") {
+		if len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	for _, group := range tracker.groups {
+		if len(group.selector.host) > 0 && !globMatch(group.selector.host, host) {
+			continue
+		}
+		if matchSegments(group.patterns, segments) {
+			group.unmatched++
+		}
+	}
+}
+
+// Drops the memberships of a route that was unregistered
+func (tracker *groupTracker) forget(route *Route) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	delete(tracker.members, route.hash)
+}
+
+// Gets the stats of every group, ordered by name
+// Must be called with the read lock held
+func (tracker *groupTracker) report(routes map[string]*Route) []GroupStats {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	report := make([]GroupStats, 0, len(tracker.groups))
+	for _, group := range tracker.groups {
+		stats := GroupStats{
+			Group:     group.name,
+			Matches:   group.matches,
+			Ranks:     copyMap(group.ranks),
+			Unmatched: group.unmatched,
+		}
+		for _, route := range routes {
+			if group.selector.selects(route) {
+				stats.Routes++
+			}
+		}
+		report = append(report, stats)
+	}
+	return report
+}
@@ -0,0 +1,65 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGroupStats(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:id":         nil,
+		"http://www.abcdefg.com/api/v1/users/:id/details": nil,
+		"http://www.abcdefg.com/api/v1/users/me":          nil,
+		"http://www.abcdefg.com/api/v1/posts/:id":         nil,
+	})
+	if stats := rt.Stats(); len(stats.Groups) != 0 {
+		t.Log("expected no groups while tracking is disabled")
+		t.FailNow()
+	}
+	if err := rt.TrackGroups(map[string]string{"users": "/api/v1/users/**", "api": "http://www.abcdefg.com/api/**"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	for _, raw := range []string{
+		"http://www.abcdefg.com/api/v1/users/1",
+		"http://www.abcdefg.com/api/v1/users/2",
+		"http://www.abcdefg.com/api/v1/users/me",
+		"http://www.abcdefg.com/api/v1/users/1/details",
+		"http://www.abcdefg.com/api/v1/users/1/friends",
+		"http://www.abcdefg.com/api/v1/posts/1",
+		"http://www.abcdefg.com/health",
+	} {
+		rt.Find(PrepareURLFrom(t, raw))
+	}
+	stats := rt.Stats()
+	if len(stats.Groups) != 2 {
+		t.Logf("expected 2 groups but found %v", stats.Groups)
+		t.FailNow()
+	}
+	api, users := stats.Groups[0], stats.Groups[1]
+	if api.Group != "api" || api.Routes != 4 || api.Matches != 5 || api.Unmatched != 1 {
+		t.Logf("unexpected api group %v", api)
+		t.FailNow()
+	}
+	if users.Group != "users" || users.Routes != 3 || users.Matches != 4 || users.Unmatched != 1 {
+		t.Logf("unexpected users group %v", users)
+		t.FailNow()
+	}
+	if len(users.Ranks) != 3 || users.Ranks[7] != 2 || users.Ranks[8] != 1 || users.Ranks[9] != 1 {
+		t.Logf("unexpected users ranks %v", users.Ranks)
+		t.FailNow()
+	}
+	rt.TrackGroups(nil)
+	if stats := rt.Stats(); len(stats.Groups) != 0 {
+		t.Log("expected no groups once tracking is disabled")
+		t.FailNow()
+	}
+}
+
+func TestTrackGroupsValidation(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.TrackGroups(map[string]string{"invalid": "http://[::1"}); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
@@ -40,8 +40,8 @@ package gtr
 
 import (
 	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
+	"fmt"
+	"io"
 	"net/url"
 	"sort"
 	"strings"
@@ -59,26 +59,174 @@ const (
 	HOST_NOT_REGISTERED RouterError = "host not registered"
 	NO_MATCH_FOUND      RouterError = "no match found"
 	NO_URL_REGISTERED   RouterError = "no url registered"
+	HASH_NOT_REGISTERED RouterError = "hash not registered"
+	UNKNOWN_PARAMETER   RouterError = "unknown parameter"
+	UNKNOWN_OPERATION   RouterError = "unknown operation"
+	INVALID_EXPERIMENT  RouterError = "invalid experiment"
+	NO_EXPERIMENT       RouterError = "no experiment configured"
+	INVALID_VALUE       RouterError = "invalid value"
+	INVALID_POINTER     RouterError = "invalid json pointer"
+	INVALID_BODY        RouterError = "invalid body"
+	HASH_COLLISION      RouterError = "hash collision"
+	INVALID_PATCH       RouterError = "invalid patch"
+	UNSUPPORTED_SCHEME  RouterError = "unsupported scheme"
+	DUPLICATE_PARAMETER RouterError = "duplicate parameter"
+	RATE_LIMITED        RouterError = "rate limited"
+	QUOTA_EXCEEDED      RouterError = "quota exceeded"
+	UNKNOWN_FRAGMENT    RouterError = "unknown fragment"
+	TABLE_FROZEN        RouterError = "table frozen"
+	CIRCUIT_OPEN        RouterError = "circuit open"
+	AMBIGUOUS_ROUTE     RouterError = "ambiguous route"
+	NAME_NOT_REGISTERED RouterError = "name not registered"
+	DUPLICATE_NAME      RouterError = "duplicate name"
+	MISSING_PARAMETER   RouterError = "missing parameter"
+	UNSUPPORTED_FORMAT  RouterError = "unsupported format"
+	NOT_REPLICATED      RouterError = "not replicated"
 )
 
 var (
-	_routeTable RouteTable
+	_routeTable *RouteTable
 	_once       sync.Once
 )
 
 // The RouterTable is used to store information relating to routes
+// A RouteTable is safe for concurrent use: lookups share a read lock
+// and run in parallel, while mutations take the write lock and wait
+// for the lookups in progress. Hooks, comparators, and config
+// providers are called by whichever goroutine made the lookup, and
+// hooks are called once the lock has been released. Routes handed out
+// by the table must be treated as read-only.
 type RouteTable struct {
+	mutex   sync.RWMutex
 	routes  map[int][]*Route
+	index   map[string]*Route
 	configs map[string]map[string]any
+	hasher  Hasher
+	limits  *SourceLimits
+	sources map[string]*sourceState
+	ignored []string
+	suffix  bool
+	hooks   hooks
+	// The behavior of templates ending with an empty query
+	emptyQuery EmptyQueryMode
+	provider   *providerCache
+	scheme     SchemePolicy
+	events     io.Writer
+	fragments  map[string]string
+	// Configs registered through RegisterTyped, keyed by hash
+	typed       map[string]any
+	comparator  Comparator
+	suggest     bool
+	frozen      bool
+	tracker     *queryTracker
+	sampler     *trafficSampler
+	cardinality *cardinalityTracker
+	groups      *groupTracker
+	// The routes of every bucket indexed by their path segments
+	tries map[int]*trieNode
+	// The number of routes inserted so far, which orders routes of
+	// equal precedence
+	inserted int
+	mode     MatchMode
+	// Set through WithCaseInsensitivePaths and WithStrictQuery only,
+	// since they change how routes are registered
+	foldCase    bool
+	strictQuery bool
+	// The query mode of routes registered without WithQueryMode
+	queryMode QueryMode
+	// The config patches applied to the routes matching their selectors
+	overrides []*configOverride
+	// The emergency bypass of every route
+	bypass GlobalBypass
+	// The mutations streamed to replicas, see EnableReplication
+	replication *replicationLog
+	// The routes registered through RegisterIf
+	conditions []*conditionalRoute
+	// Set while Replay applies a log, which must not be written again
+	replaying bool
 }
 
 // The Route struct is used for breaking down a URL to segments
 // based on which a route matching can take place
 type Route struct {
-	host        string
-	routeParams map[int]string
+	url      *url.URL
+	template string
+	host     string
+	// The path segments in order
+	segments    []segment
 	queryParams map[string]string
-	hash        string
+	queryTypes  map[string]ParamType
+	// Query params whose keys are patterns, for example `filter[*]`
+	queryWildcards map[string]string
+	hash           string
+	docs           map[string]ParamDoc
+	experiment     *Experiment
+	variants       *Variants
+	bodyKeys       []string
+	dedupParams    []string
+	source         string
+	class          RouteClass
+	ignore         []string
+	keep           []string
+	overlays       map[string]map[string]any
+	// The config overlays of file extensions, see WithExtensionOverlay
+	extensionOverlays map[string]map[string]any
+	noQuery           bool
+	// The number of literal path segments
+	literals int
+	// The path segments in order, compared by RouteCompare
+	plan pathPlan
+	// The order in which the route was inserted into its route table
+	order int
+	// The method the route was registered for, empty for any method
+	method string
+	// Whether the literal segments of a parsed URL are compared
+	// regardless of case
+	foldCase  bool
+	ownership Ownership
+	// The labels of the template host, lower-cased, where `*` and
+	// `:name` labels match any label
+	hostLabels []string
+	hostPort   string
+	hostParams map[int]string
+	// The constraints of the host parameters, for example `:v<int>`
+	hostConstraints map[int]paramConstraint
+	hostRank        int
+	// The names of the query params whose values are placeholders, and
+	// their constraints
+	queryNames       map[string]string
+	queryConstraints map[string]paramConstraint
+	queryMode        QueryMode
+	// The name given through WithName
+	name string
+}
+
+// The ParamDoc struct documents a single template parameter
+type ParamDoc struct {
+	Description string `json:"description,omitempty"`
+	Example     string `json:"example,omitempty"`
+}
+
+// A RouteOption customizes a route while it is being registered
+type RouteOption func(route *Route) error
+
+// Attaches documentation to the parameters of a route
+// Params:
+//   - docs: Documentation keyed by parameter name (without the leading colon)
+func WithParamDocs(docs map[string]ParamDoc) RouteOption {
+	return func(route *Route) error {
+		names := make(map[string]bool)
+		for _, name := range route.Params() {
+			names[name] = true
+		}
+		for name, doc := range docs {
+			if !names[name] {
+				return fmt.Errorf("%w: %s", UNKNOWN_PARAMETER, name)
+			}
+			route.docs[name] = doc
+		}
+		return nil
+	}
 }
 
 // Parses a URL to Route struct
@@ -91,34 +239,144 @@ type Route struct {
 //		  }
 //
 //	   route := ParseRoute(url)
+//
+// Parameters can either be written in Express style (`:username`)
+// or in OpenAPI style (`{username}`); both are parsed identically.
 func ParseRoute(url *url.URL) *Route {
-	routeParams := make(map[int]string)
-	queryParams := make(map[string]string)
-	for index, segment := range strings.Split(url.Path, "/") {
-		if len(segment) == 0 {
+	url = normalizeTemplate(url)
+	route := Route{
+		url:         url,
+		template:    formatTemplate(url),
+		host:        url.Host,
+		queryParams: make(map[string]string),
+		queryTypes:  make(map[string]ParamType),
+		hash:        CreateHash(url),
+		docs:        make(map[string]ParamDoc),
+		overlays:    make(map[string]map[string]any),
+		class:       CLASS_SYSTEM,
+	}
+	route.parse(url)
+	route.parseHost()
+	route.splitWildcards()
+	route.splitPlaceholders()
+	return &route
+}
+
+// Fills the empty segments and query map of a route from a URL
+func (route *Route) parse(url *url.URL) {
+	for index, value := range strings.Split(routePath(url), "/") {
+		if len(value) == 0 {
 			continue
 		}
-		if strings.HasPrefix(segment, ":") {
-			routeParams[index] = "?"
+		if strings.HasPrefix(value, ":") {
+			name, constraint, extension, _ := parseSegmentParam(value)
+			route.segments = append(route.segments, segment{kind: _paramSegment, value: name, index: index, constraint: constraint, extension: extension})
+			route.plan.add("?", false, constraint != nil)
 			continue
 		}
-		routeParams[index] = segment
+		route.segments = append(route.segments, segment{kind: _literalSegment, value: value, index: index})
+		route.literals++
+		route.plan.add(value, true, false)
 	}
 
 	for key, value := range url.Query() {
-		sort.Slice(value, func(i, j int) bool {
-			return value[i] > value[j]
-		})
-		queryParams[key] = strings.Join(value, ",")
+		sort.Sort(sort.Reverse(sort.StringSlice(value)))
+		route.queryParams[key] = strings.Join(value, ",")
+		if paramType, ok := parseParamType(route.queryParams[key]); ok {
+			route.queryTypes[key] = paramType
+		}
 	}
-	hash := CreateHash(url)
-	route := Route{
-		host:        url.Host,
-		routeParams: routeParams,
-		queryParams: queryParams,
-		hash:        hash,
+}
+
+func (route *Route) clone() *Route {
+	clone := *route
+	clone.segments = append([]segment(nil), route.segments...)
+	clone.queryParams = copyMap(route.queryParams)
+	clone.queryTypes = copyMap(route.queryTypes)
+	if route.queryWildcards != nil {
+		clone.queryWildcards = copyMap(route.queryWildcards)
 	}
-	return &route
+	clone.docs = copyMap(route.docs)
+	clone.bodyKeys = append([]string(nil), route.bodyKeys...)
+	clone.dedupParams = append([]string(nil), route.dedupParams...)
+	clone.ignore = append([]string(nil), route.ignore...)
+	clone.keep = append([]string(nil), route.keep...)
+	clone.overlays = copyMap(route.overlays)
+	if route.extensionOverlays != nil {
+		clone.extensionOverlays = copyMap(route.extensionOverlays)
+	}
+	if route.variants != nil {
+		clone.variants = &Variants{Param: route.variants.Param, Overlays: copyMap(route.variants.Overlays)}
+	}
+	clone.plan = route.plan.clone()
+	clone.hostLabels = append([]string(nil), route.hostLabels...)
+	clone.hostParams = copyMap(route.hostParams)
+	clone.hostConstraints = copyMap(route.hostConstraints)
+	if route.queryNames != nil {
+		clone.queryNames = copyMap(route.queryNames)
+		clone.queryConstraints = copyMap(route.queryConstraints)
+	}
+	return &clone
+}
+
+func copyMap[K comparable, V any](source map[K]V) map[K]V {
+	target := make(map[K]V, len(source))
+	for key, value := range source {
+		target[key] = value
+	}
+	return target
+}
+
+// Extracts the values of the route parameters from a parsed URL
+func (route *Route) values(prt *Route) map[string]string {
+	values := make(map[string]string, len(route.segments))
+	for position, segment := range route.segments {
+		if !segment.param() {
+			continue
+		}
+		values[segment.value] = prt.segments[position].value
+		if len(segment.extension) > 0 {
+			values[segment.value], values[segment.extension] = segment.split(prt.segments[position].value)
+		}
+	}
+	route.hostValues(prt.host, values)
+	route.queryValues(prt, values)
+	return values
+}
+
+// Gets the hash of the route
+func (route *Route) Hash() string {
+	return route.hash
+}
+
+// Gets the source that registered the route through RegisterFrom
+func (route *Route) Source() string {
+	return route.source
+}
+
+// Gets the URL template the route was parsed from
+func (route *Route) Template() string {
+	return route.template
+}
+
+// Gets the names of the route parameters in the order they appear,
+// host parameters first and query placeholders last
+func (route *Route) Params() []string {
+	names := route.hostParamNames()
+	for _, segment := range route.segments {
+		if segment.param() {
+			names = append(names, segment.value)
+		}
+		if len(segment.extension) > 0 {
+			names = append(names, segment.extension)
+		}
+	}
+	return append(names, route.queryParamNames()...)
+}
+
+// Gets the documentation attached to the route parameters
+func (route *Route) ParamDocs() map[string]ParamDoc {
+	return copyMap(route.docs)
 }
 
 // Compares two routes against each other
@@ -126,100 +384,527 @@ func ParseRoute(url *url.URL) *Route {
 //   - preferredRoute: The route template
 //   - route: The route to match against the route template
 func RouteCompare(preferredRoute *Route, route *Route) int {
-	if len(preferredRoute.routeParams) != len(route.routeParams) {
+	return compare(preferredRoute, route, nil)
+}
+
+// Compares two routes and optionally records every check in a candidate
+// Recording disables the early exits so that all checks are reported
+func compare(preferredRoute *Route, route *Route, candidate *Candidate) int {
+	if len(preferredRoute.segments) != len(route.segments) {
 		return 0
 	}
 	rank := 0
-	for key, value := range preferredRoute.routeParams {
-		if value == "?" {
-			rank += 1
-			continue
+	matched := true
+	if !preferredRoute.acceptsHost(route.host) {
+		matched = false
+		if candidate == nil {
+			return 0
 		}
-		if value != route.routeParams[key] {
-			rank = 0
-			break
+		candidate.Host = CHECK_MISMATCH
+	}
+	if preferredRoute.noQuery && len(route.queryParams) > 0 {
+		matched = false
+		candidate.query("", "", route.url.RawQuery, CHECK_UNEXPECTED)
+		if candidate == nil {
+			return 0
+		}
+	}
+	// Explanations need the result of every segment, so only lookups
+	// compare the path in a single pass
+	if candidate == nil {
+		if rank = preferredRoute.comparePath(route); rank == 0 {
+			return 0
+		}
+	} else {
+		pathRank, ok := candidate.path(preferredRoute, route)
+		rank = pathRank
+		matched = matched && ok
+	}
+	if preferredRoute.queryMode == QUERY_IGNORE {
+		if !matched {
+			return 0
 		}
-		rank += 2
+		return rank
 	}
 	for key, value := range preferredRoute.queryParams {
+		result := CHECK_LITERAL
 		val, ok := route.queryParams[key]
+		if _, named := preferredRoute.queryNames[key]; ok && named {
+			result = preferredRoute.checkPlaceholder(key, val)
+		} else if paramType, typed := preferredRoute.queryTypes[key]; ok && typed {
+			result = CHECK_PARAM
+			if _, err := paramType.Coerce(val); err != nil {
+				result = CHECK_INVALID
+			}
+		} else if ok && val != value {
+			result = CHECK_MISMATCH
+		}
 		if !ok {
-			return 0
+			result = CHECK_MISSING
+		} else if preferredRoute.queryMode == QUERY_SUBSET {
+			result = CHECK_PARAM
 		}
-		if val != value {
-			return 0
+		candidate.query(key, value, val, result)
+		if result != CHECK_LITERAL && result != CHECK_PARAM {
+			matched = false
+			if candidate == nil {
+				return 0
+			}
+		}
+	}
+	for pattern, value := range preferredRoute.queryWildcards {
+		val, result := matchWildcard(pattern, value, route.queryParams)
+		if result == CHECK_MISMATCH && preferredRoute.queryMode == QUERY_SUBSET {
+			result = CHECK_PARAM
+		}
+		candidate.query(pattern, value, val, result)
+		if result != CHECK_PARAM {
+			matched = false
+			if candidate == nil {
+				return 0
+			}
 		}
 	}
+	if !matched {
+		return 0
+	}
 	return rank
 }
 
+// Checks whether a matching route should be preferred over the best
+// match found so far
+func better(route *Route, rank int, best *Route, bestRank int) bool {
+	if best == nil {
+		return true
+	}
+	if route.class != best.class {
+		return route.class > best.class
+	}
+	// The most specific host wins before paths are compared, so that
+	// every host keeps its own routes
+	if route.hostRank != best.hostRank {
+		return route.hostRank > best.hostRank
+	}
+	// Routes matching the same path are told apart by the number of
+	// query params they constrain (see checkOverlaps)
+	if rank != bestRank {
+		return rank > bestRank
+	}
+	return route.queryWeight() > best.queryWeight()
+}
+
+// Checks whether a matching route ties with the best match found so far
+func tied(route *Route, rank int, best *Route, bestRank int) bool {
+	return route.class == best.class && route.hostRank == best.hostRank && rank == bestRank && route.queryWeight() == best.queryWeight()
+}
+
+// Checks whether a matching route is preferred over the best route
+// found so far when the most specific route wins
+func (rt *RouteTable) prefers(route *Route, rank int, best *Route, bestRank int) bool {
+	if better(route, rank, best, bestRank) {
+		return true
+	}
+	if !tied(route, rank, best, bestRank) {
+		return false
+	}
+	// Custom comparators compare routes in order of precedence, so ties
+	// go to the first route compared. Routes of equal rank otherwise
+	// have as many literal segments, so the first inserted wins like it
+	// would in order of precedence.
+	if rt.comparator != nil {
+		return route.specific(best)
+	}
+	return route.specific(best) || !best.specific(route) && route.order < best.order
+}
+
 // Creates a unique hash for a URL
+// Templates using curly-brace parameters hash identically to
+// their colon equivalents, and the query is canonicalized so that
+// the order of query params does not matter. The hash is computed
+// using HASH_V3, the hash version of new route tables until routes
+// only differing by host are registered (see Register).
 func CreateHash(url *url.URL) string {
-	buffer := bytes.NewBufferString(url.Path)
+	return Hasher{Version: HASH_V3}.Hash(url)
+}
+
+// Gets the raw `path?query` of a URL as hashed by HASH_V1
+func hashInput(url *url.URL) *bytes.Buffer {
+	buffer := bytes.NewBufferString(normalizePath(routePath(url)))
 	if len(url.RawQuery) > 0 {
 		buffer.WriteString("?")
 		buffer.WriteString(url.RawQuery)
 	}
-	sha256 := sha256.New()
-	sha256.Write(buffer.Bytes())
-	hash := hex.EncodeToString(sha256.Sum(nil))
-	return hash
+	return buffer
 }
 
 // Gets the default route table
+// The default route table is shared by the whole process and, like
+// every route table, is safe for concurrent use. Use NewRouteTable
+// for a table of your own.
 func DefaultRouteTable() *RouteTable {
 	_once.Do(func() {
-		_routeTable = RouteTable{
-			routes:  map[int][]*Route{},
-			configs: map[string]map[string]any{},
-		}
+		_routeTable = newRouteTable()
 	})
-	return &_routeTable
+	return _routeTable
+}
+
+func newRouteTable() *RouteTable {
+	return &RouteTable{
+		routes:  map[int][]*Route{},
+		tries:   map[int]*trieNode{},
+		index:   map[string]*Route{},
+		configs: map[string]map[string]any{},
+		hasher:  Hasher{Version: HASH_V3},
+		sources: map[string]*sourceState{},
+	}
 }
 
 // Registers a new route to the route table
-func (rt RouteTable) Register(url *url.URL, conf map[string]any) {
-	route := ParseRoute(url)
-	len := len(route.routeParams)
-	if _, ok := rt.configs[route.hash]; ok {
-		return
+// Registering an already registered URL is a no-op
+// Segments such as `@userPath` are replaced by the fragments defined
+// through DefineFragment
+// Templates using the same parameter name twice are rejected with
+// DUPLICATE_PARAMETER unless SetDuplicateParamSuffix is enabled
+// Templates only match URLs of their host, where host labels written
+// as `*` or `:name` match any label (see ParseTemplate). Routes that
+// only differ by host need a hasher including the host, so tables
+// hashing with HASH_V1 or HASH_V3 switch to HASH_V2 or HASH_V4 through
+// Rehash the first time such a route is registered. This changes the
+// hashes of the routes with a host only.
+// Templates of the same path whose query params can be satisfied by a
+// single URL are matched by the template constraining more query
+// params, and are rejected with AMBIGUOUS_ROUTE if both constrain as
+// many.
+func (rt *RouteTable) Register(url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	_, err := rt.register("", url, conf, opts...)
+	return err
+}
+
+// Registers a new route and gets it, or nil if the URL was already registered
+// Routes registered without a method match requests of any method
+func (rt *RouteTable) register(method string, url *url.URL, conf map[string]any, opts ...RouteOption) (*Route, error) {
+	if err := rt.checkFrozen(); err != nil {
+		return nil, err
+	}
+	route, err := rt.prepare(method, url)
+	if err != nil {
+		return nil, err
+	}
+	if existing, ok := rt.index[route.hash]; ok {
+		if rt.hasher.routeInput(existing) != rt.hasher.routeInput(route) {
+			return nil, fmt.Errorf("%w: %s and %s", HASH_COLLISION, existing.describe(), route.template)
+		}
+		// Hash versions ignoring the host cannot keep routes of
+		// different hosts apart, so the table switches to the version
+		// including the host
+		if len(existing.host) > 0 && len(route.host) > 0 && !strings.EqualFold(existing.host, route.host) {
+			hasher, ok := rt.hasher.withHost()
+			if !ok {
+				return nil, fmt.Errorf("%w: %s and %s", HASH_COLLISION, existing.describe(), route.template)
+			}
+			if _, err := rt.rehash(hasher); err != nil {
+				return nil, err
+			}
+			return rt.register(method, url, conf, opts...)
+		}
+		return nil, nil
+	}
+	for _, opt := range opts {
+		if err := opt(route); err != nil {
+			return nil, err
+		}
+	}
+	if err := rt.checkOverlaps(route); err != nil {
+		return nil, err
+	}
+	if len(route.name) > 0 {
+		if existing, err := rt.named(route.name); err == nil {
+			return nil, fmt.Errorf("%w: %s is already used by %s", DUPLICATE_NAME, route.name, existing.describe())
+		}
+	}
+	return route, rt.insert(route, rt.override(route, conf))
+}
+
+// Parses a template into a route hashed the way the route table would
+// register it for a method, or for any method if the method is empty
+func (rt *RouteTable) prepare(method string, url *url.URL) (*Route, error) {
+	if err := rt.checkScheme(url); err != nil {
+		return nil, err
+	}
+	url, err := rt.expandFragments(url)
+	if err != nil {
+		return nil, err
+	}
+	url, err = dedupeParams(normalizeTemplate(url), rt.suffix)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateParams(url); err != nil {
+		return nil, err
+	}
+	if rt.foldCase {
+		url = foldTemplate(url)
 	}
+	route := ParseRoute(url)
+	route.noQuery = rt.emptyQuery == EMPTY_QUERY_NONE && url.ForceQuery && len(url.RawQuery) == 0
+	route.method = strings.ToUpper(method)
+	route.queryMode = rt.queryMode
+	route.hash = rt.hasher.hashRoute(route)
+	return route, nil
+}
+
+// Inserts a route into its bucket. Buckets are ordered by class and
+// then by the number of literal segments, both descending, so that
+// the routes most likely to win a match are compared first. Routes
+// of the same order keep their registration order.
+func (rt *RouteTable) insert(route *Route, conf map[string]any) error {
+	segments := len(route.segments)
+	rt.inserted++
+	route.order = rt.inserted
+	rt.index[route.hash] = route
 	rt.configs[route.hash] = conf
-	_, ok := rt.routes[len]
+	bucket := rt.routes[segments]
+	position := sort.Search(len(bucket), func(i int) bool {
+		return precedes(route, bucket[i])
+	})
+	bucket = append(bucket, nil)
+	copy(bucket[position+1:], bucket[position:])
+	bucket[position] = route
+	rt.routes[segments] = bucket
+	trie, ok := rt.tries[segments]
+	if !ok {
+		trie = &trieNode{}
+		rt.tries[segments] = trie
+	}
+	trie.insert(route)
+	return rt.record(EVENT_REGISTER, route, conf)
+}
+
+// Applies an option to a copy of a registered route and replaces the
+// route by the copy, since routes handed out earlier may still be read
+// without holding the lock
+// Must be called with the write lock held
+func (rt *RouteTable) modify(hash string, opt RouteOption) error {
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return err
+	}
+	clone := route.clone()
+	if err := opt(clone); err != nil {
+		return err
+	}
+	segments := len(route.segments)
+	bucket := make([]*Route, len(rt.routes[segments]))
+	for position, existing := range rt.routes[segments] {
+		if existing == route {
+			existing = clone
+		}
+		bucket[position] = existing
+	}
+	rt.routes[segments] = bucket
+	rt.index[hash] = clone
+	if trie, ok := rt.tries[segments]; ok {
+		trie.replace(route, clone)
+	}
+	rt.retrack(route, clone)
+	return nil
+}
+
+// Moves what the trackers recorded for a route to the route replacing
+// it, so that the route is reported once and can be freed
+func (rt *RouteTable) retrack(route *Route, replacement *Route) {
+	if rt.cardinality != nil {
+		rt.cardinality.replace(route, replacement)
+	}
+	if rt.sampler != nil {
+		rt.sampler.replace(route, replacement)
+	}
+	if rt.tracker != nil {
+		rt.tracker.replace(route, replacement)
+	}
+}
+
+// Checks whether a route is ordered before another route of its bucket
+func precedes(route *Route, other *Route) bool {
+	if route.class != other.class {
+		return route.class > other.class
+	}
+	if route.hostRank != other.hostRank {
+		return route.hostRank > other.hostRank
+	}
+	if route.literals != other.literals {
+		return route.literals > other.literals
+	}
+	return route.queryWeight() > other.queryWeight()
+}
+
+// Gets the registered route for a given hash
+func (rt *RouteTable) Lookup(hash string) (*Route, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.lookup(hash)
+}
+
+// Gets the route registered for a template, for any method
+// Returns HASH_NOT_REGISTERED if the template was never registered
+func (rt *RouteTable) LookupTemplate(template string) (*Route, error) {
+	return rt.lookupTemplate("", template)
+}
+
+func (rt *RouteTable) lookupTemplate(method string, template string) (*Route, error) {
+	url, err := ParseTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, err := rt.prepare(method, url)
+	if err != nil {
+		return nil, err
+	}
+	return rt.lookup(route.hash)
+}
+
+func (rt *RouteTable) lookup(hash string) (*Route, error) {
+	route, ok := rt.index[hash]
 	if !ok {
-		rt.routes[len] = make([]*Route, 0)
+		return nil, HASH_NOT_REGISTERED
 	}
-	rt.routes[len] = append(rt.routes[len], route)
+	return route, nil
 }
 
 // Finds the route template for a given URL
-func (rt RouteTable) Find(url *url.URL) (string, error) {
+// Use Match to get the params, rank, and config of the match as well
+func (rt *RouteTable) Find(url *url.URL) (string, error) {
+	hash := ""
+	err := rt.resolve("", url, func(route *Route, prt *Route) error {
+		hash = route.hash
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// Finds the route template for a given URL alongside the values of
+// its parameters keyed by their names
+func (rt *RouteTable) FindWithParams(url *url.URL) (string, map[string]string, error) {
+	hash := ""
+	var values map[string]string
+	err := rt.resolve("", url, func(route *Route, prt *Route) error {
+		hash = route.hash
+		values = route.values(prt)
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return hash, values, nil
+}
+
+// Matches a URL under the read lock and hands the matching route and
+// the parsed URL to fn while the lock is held. The lookup is reported
+// to the hooks once the lock has been released.
+func (rt *RouteTable) resolve(method string, url *url.URL, fn func(route *Route, prt *Route) error) error {
+	event, err := func() (*lookupEvent, error) {
+		rt.mutex.RLock()
+		defer rt.mutex.RUnlock()
+		route, prt, err := rt.match(method, url)
+		event := rt.observe(url, route, prt, err)
+		if err != nil {
+			return event, err
+		}
+		defer releaseRoute(prt)
+		return event, fn(route, prt)
+	}()
+	rt.notify(event)
+	return err
+}
+
+// Finds the best matching route for a given URL alongside the parsed URL
+// The parsed URL is pooled and should be released once it is no longer used
+// Only routes registered for any method are matched if the method is
+// empty, otherwise routes registered for the method are matched too
+func (rt *RouteTable) match(method string, url *url.URL) (*Route, *Route, error) {
+	if err := rt.checkScheme(url); err != nil {
+		return nil, nil, err
+	}
 	if len(rt.routes) == 0 {
-		return "", NO_URL_REGISTERED
+		return nil, nil, NO_URL_REGISTERED
 	}
-	prt := ParseRoute(url)
-	routes, ok := rt.routes[len(prt.routeParams)]
+	prt := acquireRoute(url)
+	prt.foldCase = rt.foldCase
+	routes, ok := rt.routes[len(prt.segments)]
 	if !ok {
-		return "", HOST_NOT_REGISTERED
+		return nil, nil, rt.miss(url, prt, HOST_NOT_REGISTERED)
 	}
 	lrnk := 0
 	var lrt *Route
-	for _, url := range routes {
-		rnk := RouteCompare(url, prt)
-		if rnk != 0 {
-			if rnk > lrnk {
+	if rt.mode == MATCH_FIRST {
+		lrt = rt.first(method, prt, routes)
+	} else if rt.comparator != nil {
+		// Custom comparators may rank freely, so every route of the
+		// bucket is compared and ties go to the first route in order
+		// of precedence
+		for _, route := range routes {
+			if !route.accepts(method) {
+				continue
+			}
+			rnk := rt.rank(route, prt)
+			if rnk > 0 && rt.prefers(route, rnk, lrt, lrnk) {
 				lrnk = rnk
-				lrt = url
+				lrt = route
 			}
 		}
+	} else {
+		rt.tries[len(prt.segments)].visit(prt, func(route *Route) {
+			if !route.accepts(method) {
+				return
+			}
+			rnk := rt.rank(route, prt)
+			if rnk == 0 {
+				return
+			}
+			if rt.prefers(route, rnk, lrt, lrnk) {
+				lrnk = rnk
+				lrt = route
+			}
+		})
+	}
+	if lrt == nil {
+		return nil, nil, rt.miss(url, prt, NO_MATCH_FOUND)
 	}
-	if lrnk == 0 {
-		return "", NO_MATCH_FOUND
+	return lrt, prt, nil
+}
+
+// Releases the parsed URL of a failed match and gets the error to return
+func (rt *RouteTable) miss(url *url.URL, prt *Route, err error) error {
+	defer releaseRoute(prt)
+	if rt.suggest {
+		return rt.suggestions(url, prt.Segments(), err)
 	}
-	return lrt.hash, nil
+	return err
 }
 
 // Gets configuration for a given hash
-func (rt RouteTable) GetConfig(hash string) map[string]any {
-	return rt.configs[hash]
+// Hashes without a config fall back to the config provider, if any
+func (rt *RouteTable) GetConfig(hash string) map[string]any {
+	rt.mutex.RLock()
+	conf, provider := rt.configs[hash], rt.provider
+	rt.mutex.RUnlock()
+	// The provider may be slow, so it is called without holding the lock
+	if conf == nil && provider != nil {
+		return provider.config(hash)
+	}
+	return conf
+}
+
+// Gets all registered routes ordered by their templates
+func (rt *RouteTable) Routes() []*Route {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.sorted()
 }
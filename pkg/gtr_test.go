@@ -1,6 +1,7 @@
 package gtr
 
 import (
+	"errors"
 	"net/url"
 	"testing"
 	"time"
@@ -33,7 +34,7 @@ func PrepareURL(t *testing.T) *url.URL {
 func PrepareParseRoute(t *testing.T) *Route {
 	url := PrepareURLTemplate(t)
 	route := ParseRoute(url)
-	if len(route.routeParams) != 5 {
+	if len(route.segments) != 5 {
 		t.Log("route params parsed incorrectly")
 		t.FailNow()
 	}
@@ -89,3 +90,118 @@ func TestGetConfig(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestParamDocs(t *testing.T) {
+	rt := newRouteTable()
+	docs := map[string]ParamDoc{
+		"username": {Description: "The login name of the user", Example: "ken"},
+	}
+	if err := rt.Register(PrepareURLTemplate(t), nil, WithParamDocs(docs)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err := rt.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, err := rt.Lookup(hash)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if route.ParamDocs()["username"].Example != "ken" {
+		t.Log("param docs not stored")
+		t.FailNow()
+	}
+	unknown := map[string]ParamDoc{"id": {Description: "not a parameter"}}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/posts/:postId")
+	if err := rt.Register(url, nil, WithParamDocs(unknown)); !errors.Is(err, UNKNOWN_PARAMETER) {
+		t.Log("expected unknown parameter error")
+		t.FailNow()
+	}
+}
+
+func TestBucketOrder(t *testing.T) {
+	rt := newRouteTable()
+	templates := []string{
+		"http://www.abcdefg.com/api/v1/:kind/:id/details",
+		"http://www.abcdefg.com/api/v1/users/:username/details",
+		"http://www.abcdefg.com/api/v1/users/ken/details",
+	}
+	for _, template := range templates {
+		url, _ := url.Parse(template)
+		if err := rt.Register(url, nil); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	learned, _ := url.Parse("http://www.abcdefg.com/api/v2/users/ken/details")
+	if err := rt.Register(learned, nil, WithClass(CLASS_LEARNED)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	bucket := rt.routes[5]
+	if bucket[0].template != templates[2] || bucket[1].template != templates[1] || bucket[2].template != templates[0] || bucket[3].class != CLASS_LEARNED {
+		t.Log("bucket is not ordered by precedence")
+		t.FailNow()
+	}
+	for url, expected := range map[string]string{
+		"http://www.abcdefg.com/api/v1/users/ken/details":    templates[2],
+		"http://www.abcdefg.com/api/v1/users/dennis/details": templates[1],
+		"http://www.abcdefg.com/api/v1/posts/1/details":      templates[0],
+	} {
+		route, _, err := rt.match("", PrepareURLFrom(t, url))
+		if err != nil || route.template != expected {
+			t.Logf("expected %s to match %s", url, expected)
+			t.FailNow()
+		}
+	}
+}
+
+func PrepareURLFrom(t *testing.T, raw string) *url.URL {
+	url, err := url.Parse(raw)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	return url
+}
+
+func TestFindWithParams(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/posts/{id}?type=cache": nil,
+	})
+	hash, params, err := rt.FindWithParams(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/posts/7?type=cache"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if hash != CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/posts/:id?type=cache")) {
+		t.Log("unexpected hash")
+		t.FailNow()
+	}
+	if len(params) != 2 || params["username"] != "ken" || params["id"] != "7" {
+		t.Logf("unexpected params %v", params)
+		t.FailNow()
+	}
+	if _, params, err := rt.FindWithParams(PrepareURL(t)); err == nil || params != nil {
+		t.Log("expected a failed lookup without params")
+		t.FailNow()
+	}
+}
+
+func TestLookupTemplate(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": nil,
+	})
+	route, err := rt.LookupTemplate("http://www.abcdefg.com/api/v1/users/{username}/details?type=cache")
+	if err != nil || route.Hash() != CreateHash(PrepareURLTemplate(t)) {
+		t.Logf("expected the registered route: %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.LookupTemplate("http://www.abcdefg.com/api/v1/users/:username"); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+}
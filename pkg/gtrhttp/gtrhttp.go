@@ -0,0 +1,51 @@
+// Package gtrhttp serves HTTP requests through the routes of a GTR
+// route table
+package gtrhttp
+
+import (
+	"net/http"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// The Handler struct is an http.Handler matching requests against a
+// route table and dispatching them to the handler of the matching
+// route, through the middlewares added by Use and UseFor. The hash,
+// params, and config of the match, and the policy of the route for the
+// method of the request, are attached to the context of the request
+// (see Match). Requests matching no route, or a route without a
+// handler, are served by NotFound.
+type Handler struct {
+	*gtr.Mux
+}
+
+// A Middleware wraps the handler of a route (see Use and UseFor)
+type Middleware = gtr.Middleware
+
+// Creates a handler dispatching requests through the routes of a
+// route table. Routes registered in the table later on are served as
+// soon as a handler is attached to them.
+func NewHandler(table *gtr.RouteTable) *Handler {
+	return &Handler{Mux: gtr.NewTableMux(table)}
+}
+
+// Registers the handler of a route registered in the route table.
+// Registering a template again replaces its handler.
+// Params:
+//   - template: The template of the route, for example `/users/:username`
+//   - h: The handler serving the requests matching the template
+func (handler *Handler) Handle(template string, h http.Handler) error {
+	return handler.Attach(template, h)
+}
+
+// Registers the handler function of a route registered in the route
+// table
+func (handler *Handler) HandleFunc(template string, h func(w http.ResponseWriter, r *http.Request)) error {
+	return handler.Attach(template, http.HandlerFunc(h))
+}
+
+// Gets the match attached to a request dispatched by a Handler
+// The second return value is false if the request was not dispatched by a Handler.
+func Match(r *http.Request) (*gtr.RequestMatch, bool) {
+	return gtr.GetRequestMatch(r)
+}
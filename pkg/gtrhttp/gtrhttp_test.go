@@ -0,0 +1,58 @@
+package gtrhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+func TestHandler(t *testing.T) {
+	table := gtr.NewRouteTable()
+	template, _ := url.Parse("/api/v1/users/:username")
+	if err := table.Register(template, map[string]any{"ttl": "1m"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	handler := NewHandler(table)
+	err := handler.HandleFunc("/api/v1/users/:username", func(w http.ResponseWriter, r *http.Request) {
+		match, ok := Match(r)
+		if !ok {
+			t.Log("expected the match in the context")
+			t.FailNow()
+		}
+		if ttl, _ := match.Policy.TTL(); ttl.Minutes() != 1 || match.Config["ttl"] != "1m" {
+			t.Log("expected the config of the route in the context")
+			t.FailNow()
+		}
+		w.Write([]byte(match.Params["username"]))
+	})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := handler.Handle("/api/v1/posts/:id", http.NotFoundHandler()); err == nil {
+		t.Log("expected an error for a template that is not registered")
+		t.FailNow()
+	}
+	handler.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("user:"))
+			next.ServeHTTP(w, r)
+		})
+	})
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/users/ken", nil))
+	if response.Code != http.StatusOK || response.Body.String() != "user:ken" {
+		t.Logf("unexpected response %d %q", response.Code, response.Body.String())
+		t.FailNow()
+	}
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/posts/1", nil))
+	if response.Code != http.StatusNotFound {
+		t.Logf("expected 404 but found %d", response.Code)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,199 @@
+// Package gtrtest helps projects using GTR test their integration:
+// it builds populated route tables, generates template and URL pairs,
+// and compares match results against golden files
+package gtrtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gtr "github.com/vedadiyan/gtr/pkg"
+)
+
+// The environment variable that rewrites golden files instead of
+// comparing against them, for example `GTRTEST_UPDATE=1 go test ./...`
+const UPDATE_ENV = "GTRTEST_UPDATE"
+
+// The TableBuilder struct registers routes into a new route table,
+// failing the test if any of them cannot be registered
+type TableBuilder struct {
+	t      testing.TB
+	opts   []gtr.Option
+	routes []builtRoute
+}
+
+type builtRoute struct {
+	method   string
+	template string
+	conf     map[string]any
+	opts     []gtr.RouteOption
+}
+
+// The Pair struct is a URL generated to match a route template
+type Pair struct {
+	Template string `json:"template"`
+	Method   string `json:"method,omitempty"`
+	Hash     string `json:"hash"`
+	URL      string `json:"url"`
+}
+
+// The Result struct is the outcome of matching a URL, the way it is
+// written to golden files
+type Result struct {
+	URL      string            `json:"url"`
+	Template string            `json:"template,omitempty"`
+	Hash     string            `json:"hash,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Config   map[string]any    `json:"config,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Creates a builder of a route table created with the given options
+func NewTable(t testing.TB, opts ...gtr.Option) *TableBuilder {
+	return &TableBuilder{t: t, opts: opts}
+}
+
+// Adds a route matching requests of any method
+func (builder *TableBuilder) Route(template string, conf map[string]any, opts ...gtr.RouteOption) *TableBuilder {
+	return builder.Method("", template, conf, opts...)
+}
+
+// Adds a route matching the requests of a method only
+func (builder *TableBuilder) Method(method string, template string, conf map[string]any, opts ...gtr.RouteOption) *TableBuilder {
+	builder.routes = append(builder.routes, builtRoute{method: method, template: template, conf: conf, opts: opts})
+	return builder
+}
+
+// Creates the route table and registers the routes in the order they
+// were added
+func (builder *TableBuilder) Build() *gtr.RouteTable {
+	builder.t.Helper()
+	table := gtr.NewRouteTable(builder.opts...)
+	for _, route := range builder.routes {
+		template, err := gtr.ParseTemplate(route.template)
+		if err == nil && len(route.method) > 0 {
+			err = table.RegisterMethod(route.method, template, route.conf, route.opts...)
+		} else if err == nil {
+			err = table.Register(template, route.conf, route.opts...)
+		}
+		if err != nil {
+			builder.t.Logf("%s: %s", route.template, err)
+			builder.t.FailNow()
+		}
+	}
+	return table
+}
+
+// Generates up to n URLs for every route of a route table (see
+// gtr.RouteTable.Examples), keeping only the URLs that the route
+// itself matches rather than a more specific route. Routes without
+// parameters get a single URL.
+func Pairs(table *gtr.RouteTable, n int) []Pair {
+	pairs := make([]Pair, 0)
+	for _, route := range table.Routes() {
+		seen := make(map[string]bool)
+		for _, example := range table.Examples(route.Hash(), n) {
+			parsed, err := url.Parse(example)
+			if err != nil || seen[example] {
+				continue
+			}
+			seen[example] = true
+			hash, err := table.FindMethod(route.Method(), parsed)
+			if err != nil || hash != route.Hash() {
+				continue
+			}
+			pairs = append(pairs, Pair{Template: route.Template(), Method: route.Method(), Hash: route.Hash(), URL: example})
+		}
+	}
+	return pairs
+}
+
+// Matches a URL and fails the test unless it matches the route
+// registered for a template
+func ExpectMatch(t testing.TB, table *gtr.RouteTable, rawURL string, template string) *gtr.MatchResult {
+	t.Helper()
+	match, err := table.Match(parse(t, rawURL))
+	if err != nil {
+		t.Logf("expected %s to match %s but found %s", rawURL, template, err)
+		t.FailNow()
+	}
+	route, err := table.LookupTemplate(template)
+	if err != nil {
+		t.Logf("%s: %s", template, err)
+		t.FailNow()
+	}
+	if match.Hash != route.Hash() {
+		t.Logf("expected %s to match %s but found %s", rawURL, template, match.Template)
+		t.FailNow()
+	}
+	return match
+}
+
+// Fails the test if a URL matches a route
+func ExpectMiss(t testing.TB, table *gtr.RouteTable, rawURL string) {
+	t.Helper()
+	if match, err := table.Match(parse(t, rawURL)); err == nil {
+		t.Logf("expected %s to match no route but found %s", rawURL, match.Template)
+		t.FailNow()
+	}
+}
+
+// Matches URLs and compares the results against a golden file of JSON
+// results (see Result). The golden file is written instead when the
+// UPDATE_ENV environment variable is set.
+func Golden(t testing.TB, table *gtr.RouteTable, path string, urls ...string) {
+	t.Helper()
+	results := make([]Result, 0, len(urls))
+	for _, rawURL := range urls {
+		result := Result{URL: rawURL}
+		if match, err := table.Match(parse(t, rawURL)); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Template = match.Template
+			result.Hash = match.Hash
+			result.Params = match.Params
+			result.Config = match.Config
+		}
+		results = append(results, result)
+	}
+	actual, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	actual = append(actual, '\n')
+	if len(os.Getenv(UPDATE_ENV)) > 0 {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Logf("%s (set %s=1 to write the golden file)", err, UPDATE_ENV)
+		t.FailNow()
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Logf("match results differ from %s (set %s=1 to update it):\n%s", path, UPDATE_ENV, actual)
+		t.FailNow()
+	}
+}
+
+func parse(t testing.TB, rawURL string) *url.URL {
+	t.Helper()
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	return parsed
+}
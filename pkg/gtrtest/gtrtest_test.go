@@ -0,0 +1,50 @@
+package gtrtest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableBuilder(t *testing.T) {
+	table := NewTable(t).
+		Route("/api/v1/users/:username", map[string]any{"ttl": "1m"}).
+		Route("/api/v1/users/admin", nil).
+		Method(http.MethodPost, "/api/v1/posts", nil).
+		Build()
+	match := ExpectMatch(t, table, "/api/v1/users/ken", "/api/v1/users/:username")
+	if match.Params["username"] != "ken" {
+		t.Logf("unexpected params %v", match.Params)
+		t.FailNow()
+	}
+	ExpectMatch(t, table, "/api/v1/users/admin", "/api/v1/users/admin")
+	ExpectMiss(t, table, "/api/v1/comments")
+	pairs := Pairs(table, 2)
+	if len(pairs) != 4 {
+		t.Logf("unexpected pairs %v", pairs)
+		t.FailNow()
+	}
+	for _, pair := range pairs {
+		if pair.Template == "/api/v1/users/:username" && pair.URL == "/api/v1/users/admin" {
+			t.Log("expected the URLs of more specific routes to be left out")
+			t.FailNow()
+		}
+	}
+}
+
+func TestGolden(t *testing.T) {
+	table := NewTable(t).
+		Route("/api/v1/users/:username", map[string]any{"ttl": "1m"}).
+		Build()
+	urls := []string{"/api/v1/users/ken", "/api/v1/posts"}
+	Golden(t, table, filepath.Join("testdata", "golden.json"), urls...)
+	path := filepath.Join(t.TempDir(), "golden.json")
+	t.Setenv(UPDATE_ENV, "1")
+	Golden(t, table, path, urls...)
+	expected, _ := os.ReadFile(filepath.Join("testdata", "golden.json"))
+	if actual, _ := os.ReadFile(path); string(actual) != string(expected) {
+		t.Logf("expected the golden file to be written but found %s", actual)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,222 @@
+package gtr
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// The HashVersion identifies the algorithm used to hash route
+// templates. The output of a version never changes once released,
+// so persisted hashes (for example cache keys) stay valid as long
+// as the table keeps using the same version.
+type HashVersion int
+
+const (
+	// SHA-256 of `path?query` with the query as written
+	HASH_V1 HashVersion = 1
+	// SHA-256 of `host/path?query` with the query as written, which
+	// keeps templates that only differ by host apart
+	HASH_V2 HashVersion = 2
+	// SHA-256 of `path?query` with a canonical query (sorted keys and
+	// values, normalized encoding). This is the output of CreateHash
+	// and the version used by new route tables.
+	HASH_V3 HashVersion = 3
+	// SHA-256 of `host/path?query` with a canonical query
+	HASH_V4 HashVersion = 4
+)
+
+// The HashEncoding determines how the digest of a hash is encoded
+type HashEncoding int
+
+const (
+	// Lowercase hexadecimal (the default)
+	HASH_HEX HashEncoding = iota
+	// Unpadded URL-safe base64
+	HASH_BASE64URL
+	// The raw digest bytes
+	HASH_RAW
+)
+
+// The Hasher struct creates route hashes using a given algorithm
+// Params:
+//   - Version: The hash algorithm
+//   - Encoding: The encoding of the digest
+//   - Length: Truncates hashes to the given number of characters
+//     (bytes for HASH_RAW); zero keeps the full hash. Truncation
+//     increases the chance of collisions, which are reported as
+//     HASH_COLLISION when registering routes.
+type Hasher struct {
+	Version  HashVersion
+	Encoding HashEncoding
+	Length   int
+}
+
+// Gets the version of the hasher keeping templates that only differ by
+// host apart, which hashes templates without a host like the hasher
+// does. Returns false if the hasher already includes the host.
+func (hasher Hasher) withHost() (Hasher, bool) {
+	switch hasher.Version {
+	case HASH_V1:
+		hasher.Version = HASH_V2
+	case HASH_V2, HASH_V4:
+		return hasher, false
+	default:
+		hasher.Version = HASH_V4
+	}
+	return hasher, true
+}
+
+// Creates the hash of a URL
+func (hasher Hasher) Hash(url *url.URL) string {
+	return hasher.digest(hasher.input(url))
+}
+
+// Creates the hash of a route. Routes that only match requests without
+// query params hash as if their template ended with a `?`.
+func (hasher Hasher) hashRoute(route *Route) string {
+	return hasher.digest(hasher.routeInput(route))
+}
+
+func (hasher Hasher) routeInput(route *Route) string {
+	input := hasher.input(route.url)
+	if route.noQuery {
+		input += "?"
+	}
+	// Routes registered for a method are kept apart from the same
+	// template registered for any method
+	if len(route.method) > 0 {
+		input = route.method + " " + input
+	}
+	return input
+}
+
+func (hasher Hasher) digest(input string) string {
+	sha256 := sha256.New()
+	sha256.Write([]byte(input))
+	sum := sha256.Sum(nil)
+	hash := ""
+	switch hasher.Encoding {
+	case HASH_BASE64URL:
+		hash = base64.RawURLEncoding.EncodeToString(sum)
+	case HASH_RAW:
+		hash = string(sum)
+	default:
+		hash = hex.EncodeToString(sum)
+	}
+	if hasher.Length > 0 && hasher.Length < len(hash) {
+		hash = hash[:hasher.Length]
+	}
+	return hash
+}
+
+// Gets the canonical string that is hashed for a URL
+func (hasher Hasher) input(url *url.URL) string {
+	switch hasher.Version {
+	case HASH_V1:
+		return hashInput(url).String()
+	case HASH_V2:
+		return strings.ToLower(url.Host) + hashInput(url).String()
+	case HASH_V4:
+		return strings.ToLower(url.Host) + canonicalHashInput(url)
+	default:
+		return canonicalHashInput(url)
+	}
+}
+
+// Gets the `path?query` of a URL with a canonical query
+func canonicalHashInput(url *url.URL) string {
+	input := normalizePath(routePath(url))
+	query := url.Query()
+	if len(query) == 0 {
+		return input
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return input + "?" + query.Encode()
+}
+
+// Gets the hasher used by the route table
+func (rt *RouteTable) Hasher() Hasher {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.hasher
+}
+
+// Switches the route table to another hash algorithm or format.
+// Every route and config is moved to its new hash, and the returned
+// map can be used to migrate hashes persisted outside of the table
+// (old hash to new hash). The table is left untouched if two routes
+// would end up with the same hash.
+func (rt *RouteTable) Rehash(hasher Hasher) (map[string]string, error) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return nil, err
+	}
+	return rt.rehash(hasher)
+}
+
+func (rt *RouteTable) rehash(hasher Hasher) (map[string]string, error) {
+	mapping := make(map[string]string, len(rt.index))
+	index := make(map[string]*Route, len(rt.index))
+	for hash, route := range rt.index {
+		rehash := hasher.hashRoute(route)
+		if existing, ok := index[rehash]; ok {
+			return nil, fmt.Errorf("%w: %s and %s", HASH_COLLISION, existing.describe(), route.describe())
+		}
+		index[rehash] = route
+		mapping[hash] = rehash
+	}
+	// Routes are replaced rather than mutated, since routes handed out
+	// earlier may still be read without holding the lock
+	replaced := make(map[*Route]*Route, len(index))
+	configs := make(map[string]map[string]any, len(rt.configs))
+	for hash, rehash := range mapping {
+		route := index[rehash].clone()
+		route.hash = rehash
+		replaced[index[rehash]] = route
+		rt.retrack(index[rehash], route)
+		index[rehash] = route
+		configs[rehash] = rt.configs[hash]
+	}
+	routes := make(map[int][]*Route, len(rt.routes))
+	for segments, bucket := range rt.routes {
+		rebuilt := make([]*Route, len(bucket))
+		for position, route := range bucket {
+			if replacement, ok := replaced[route]; ok {
+				route = replacement
+			}
+			rebuilt[position] = route
+		}
+		routes[segments] = rebuilt
+	}
+	rt.routes = routes
+	rt.index = index
+	rt.reindex()
+	rt.configs = configs
+	rt.typed = RemapHashes(mapping, rt.typed)
+	rt.hasher = hasher
+	return mapping, nil
+}
+
+// Moves values keyed by old hashes to their new hashes
+// Params:
+//   - mapping: The mapping returned by Rehash
+//   - source: The values keyed by old hashes
+func RemapHashes[V any](mapping map[string]string, source map[string]V) map[string]V {
+	target := make(map[string]V, len(source))
+	for hash, value := range source {
+		if rehash, ok := mapping[hash]; ok {
+			target[rehash] = value
+			continue
+		}
+		target[hash] = value
+	}
+	return target
+}
@@ -0,0 +1,120 @@
+package gtr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestHashStability(t *testing.T) {
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username/details?type=cache")
+	const (
+		EXPECTED = "691393843ed1853c913855ef28acbe0029e1bf7ea7660c83e104b34083241e8d"
+	)
+	if (Hasher{Version: HASH_V1}).Hash(url) != EXPECTED {
+		t.Log("v1 hash changed")
+		t.FailNow()
+	}
+	if (Hasher{Version: HASH_V3}).Hash(url) != CreateHash(url) {
+		t.Log("v3 hasher differs from CreateHash")
+		t.FailNow()
+	}
+	if (Hasher{Version: HASH_V2}).Hash(url) == (Hasher{Version: HASH_V1}).Hash(url) {
+		t.Log("v2 hasher should include the host")
+		t.FailNow()
+	}
+	if (Hasher{Version: HASH_V4}).Hash(url) == CreateHash(url) {
+		t.Log("v4 hasher should include the host")
+		t.FailNow()
+	}
+}
+
+func TestRehash(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10},
+	})
+	old, err := rt.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	mapping, err := rt.Rehash(Hasher{Version: HASH_V2})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err := rt.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if mapping[old] != hash || rt.GetConfig(hash)["ttl"] != 10 {
+		t.Log("config was not migrated")
+		t.FailNow()
+	}
+	persisted := RemapHashes(mapping, map[string]string{old: "cached"})
+	if persisted[hash] != "cached" {
+		t.Log("persisted hashes were not remapped")
+		t.FailNow()
+	}
+	other, _ := url.Parse("http://www.other.com/api/v1/users/:username/details?type=cache")
+	rt.Register(other, nil)
+	if _, err := rt.Rehash(Hasher{Version: HASH_V1}); !errors.Is(err, HASH_COLLISION) {
+		t.Log("expected collision when dropping the host from the hash")
+		t.FailNow()
+	}
+}
+
+func TestHashFormat(t *testing.T) {
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username/details?type=cache")
+	tests := map[Hasher]int{
+		{Version: HASH_V1}:                                64,
+		{Version: HASH_V1, Encoding: HASH_BASE64URL}:      43,
+		{Version: HASH_V1, Encoding: HASH_RAW}:            32,
+		{Version: HASH_V1, Length: 16}:                    16,
+		{Version: HASH_V2, Encoding: HASH_RAW, Length: 8}: 8,
+	}
+	for hasher, length := range tests {
+		if len(hasher.Hash(url)) != length {
+			t.Logf("%+v produced a hash of length %d", hasher, len(hasher.Hash(url)))
+			t.FailNow()
+		}
+	}
+	truncated := (Hasher{Version: HASH_V1, Length: 16}).Hash(url)
+	if truncated != CreateHash(url)[:16] {
+		t.Log("truncation should keep the prefix of the hash")
+		t.FailNow()
+	}
+	rt := newRouteTable()
+	rt.Rehash(Hasher{Version: HASH_V1, Length: 1})
+	collisions := 0
+	for i := 0; i < 32; i++ {
+		url, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/r%d", i))
+		if err := rt.Register(url, nil); errors.Is(err, HASH_COLLISION) {
+			collisions++
+		}
+	}
+	if collisions == 0 {
+		t.Log("expected truncated hashes to collide")
+		t.FailNow()
+	}
+}
+
+func TestCanonicalQueryHash(t *testing.T) {
+	first, _ := url.Parse("http://www.abcdefg.com/api/v1/users?a=1&b=2&b=1")
+	second, _ := url.Parse("http://www.abcdefg.com/api/v1/users?b=1&a=%31&b=2")
+	if CreateHash(first) != CreateHash(second) {
+		t.Log("equivalent queries hash differently")
+		t.FailNow()
+	}
+	if (Hasher{Version: HASH_V1}).Hash(first) == (Hasher{Version: HASH_V1}).Hash(second) {
+		t.Log("v1 should keep hashing the raw query")
+		t.FailNow()
+	}
+	third, _ := url.Parse("http://www.abcdefg.com/api/v1/users?a=1&b=2")
+	if CreateHash(first) == CreateHash(third) {
+		t.Log("different queries hash identically")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,66 @@
+package gtr
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// The number of index bits of a hyperLogLog. The 1024 registers
+// estimate cardinalities with a standard error of about 3%.
+const _hllPrecision = 10
+
+// A hyperLogLog estimates the number of distinct values added to it
+// in constant memory
+type hyperLogLog struct {
+	registers [1 << _hllPrecision]uint8
+}
+
+func (hll *hyperLogLog) add(value string) {
+	hll.addHash(hashValue(value))
+}
+
+// Adds a value hashed by hashValue, so that a value added to several
+// sketches is only hashed once
+func (hll *hyperLogLog) addHash(sum uint64) {
+	index := sum >> (64 - _hllPrecision)
+	rank := uint8(bits.LeadingZeros64(sum<<_hllPrecision|1<<(_hllPrecision-1))) + 1
+	if rank > hll.registers[index] {
+		hll.registers[index] = rank
+	}
+}
+
+func (hll *hyperLogLog) estimate() uint64 {
+	m := float64(len(hll.registers))
+	sum := 0.0
+	zeros := 0.0
+	for _, rank := range hll.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// Falls back to linear counting for small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/zeros)
+	}
+	return uint64(estimate + 0.5)
+}
+
+func hashValue(value string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(value))
+	return mix64(hash.Sum64())
+}
+
+// Spreads the bits of a FNV hash, whose high bits are poorly mixed
+// for short inputs
+func mix64(value uint64) uint64 {
+	value ^= value >> 33
+	value *= 0xff51afd7ed558ccd
+	value ^= value >> 33
+	value *= 0xc4ceb9fe1a85ec53
+	value ^= value >> 33
+	return value
+}
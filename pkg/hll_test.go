@@ -0,0 +1,22 @@
+package gtr
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	for _, cardinality := range []int{0, 10, 1000, 100000} {
+		hll := hyperLogLog{}
+		for i := 0; i < cardinality; i++ {
+			hll.add(strconv.Itoa(i))
+			hll.add(strconv.Itoa(i))
+		}
+		estimate := float64(hll.estimate())
+		if math.Abs(estimate-float64(cardinality)) > 0.1*float64(cardinality) {
+			t.Logf("expected about %d distinct values but estimated %v", cardinality, estimate)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gtr
+
+import (
+	"math/rand"
+	"net/url"
+)
+
+// The MatchEvent struct describes a successful lookup
+// Params:
+//   - Sampled: The lookup was selected by the metrics sample rate
+//   - Traced: The lookup was selected by the trace sample rate
+type MatchEvent struct {
+	Hash    string
+	URL     *url.URL
+	Sampled bool
+	Traced  bool
+}
+
+// The MissEvent struct describes a failed lookup
+type MissEvent struct {
+	URL *url.URL
+	Err error
+}
+
+// The MatchHook interface is notified about successful lookups made
+// through Find and Match. Only lookups selected by the metrics or
+// trace sample rate of the matching route are reported, which keeps
+// the cost of noisy routes bounded.
+type MatchHook interface {
+	OnMatch(event MatchEvent)
+}
+
+// The MissHook interface is notified about failed lookups made
+// through Find and Match
+type MissHook interface {
+	OnMiss(event MissEvent)
+}
+
+type hooks struct {
+	match []MatchHook
+	miss  []MissHook
+	quota []QuotaHook
+}
+
+// The lookupEvent struct is a lookup waiting to be reported to the
+// hooks that were registered when it was made
+type lookupEvent struct {
+	match *MatchEvent
+	miss  *MissEvent
+	hooks hooks
+}
+
+// Adds a hook notified about successful lookups
+func (rt *RouteTable) AddMatchHook(hook MatchHook) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.hooks.match = append(rt.hooks.match, hook)
+}
+
+// Adds a hook notified about failed lookups
+func (rt *RouteTable) AddMissHook(hook MissHook) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.hooks.miss = append(rt.hooks.miss, hook)
+}
+
+// Records a lookup for the uncovered query, traffic, cardinality, and
+// group reports and
+// gets the event to report to the hooks, if any
+// Must be called with the read lock held
+func (rt *RouteTable) observe(url *url.URL, route *Route, prt *Route, err error) *lookupEvent {
+	if err != nil {
+		if groups := rt.groups; groups != nil {
+			groups.miss(url)
+		}
+		if len(rt.hooks.miss) == 0 {
+			return nil
+		}
+		return &lookupEvent{miss: &MissEvent{URL: url, Err: err}, hooks: rt.hooks}
+	}
+	if tracker := rt.tracker; tracker != nil {
+		tracker.track(rt, route, url)
+	}
+	if groups := rt.groups; groups != nil {
+		groups.observe(route)
+	}
+	if rt.sampler != nil || rt.cardinality != nil {
+		values := route.values(prt)
+		if sampler := rt.sampler; sampler != nil {
+			sampler.observe(route, values)
+		}
+		if cardinality := rt.cardinality; cardinality != nil {
+			cardinality.observe(route, values)
+		}
+	}
+	if len(rt.hooks.match) == 0 {
+		return nil
+	}
+	policy := Policy(rt.configs[route.hash])
+	event := MatchEvent{
+		Hash:    route.hash,
+		URL:     url,
+		Sampled: sample(policy.MetricsSampleRate()),
+		Traced:  sample(policy.TraceSampleRate()),
+	}
+	if !event.Sampled && !event.Traced {
+		return nil
+	}
+	return &lookupEvent{match: &event, hooks: rt.hooks}
+}
+
+// Reports a lookup to the hooks
+// Must be called without holding the lock, so hooks may use the table
+func (rt *RouteTable) notify(event *lookupEvent) {
+	if event == nil {
+		return
+	}
+	if event.miss != nil {
+		for _, hook := range event.hooks.miss {
+			hook.OnMiss(*event.miss)
+		}
+		return
+	}
+	for _, hook := range event.hooks.match {
+		hook.OnMatch(*event.match)
+	}
+}
+
+func sample(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return rand.Float64() < rate
+}
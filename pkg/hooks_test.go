@@ -0,0 +1,62 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+type recordingHook struct {
+	matches []MatchEvent
+	misses  []MissEvent
+}
+
+func (hook *recordingHook) OnMatch(event MatchEvent) {
+	hook.matches = append(hook.matches, event)
+}
+
+func (hook *recordingHook) OnMiss(event MissEvent) {
+	hook.misses = append(hook.misses, event)
+}
+
+func TestHookSampling(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/health":             {"metrics_sample_rate": 0.0, "trace_sample_rate": 0.0},
+		"http://www.abcdefg.com/checkout":           {"trace_sample_rate": 1.0},
+		"http://www.abcdefg.com/api/v1/users/:id/x": {"metrics_sample_rate": 0.5, "trace_sample_rate": 0.0},
+	})
+	hook := &recordingHook{}
+	rt.AddMatchHook(hook)
+	rt.AddMissHook(hook)
+	health, _ := url.Parse("http://www.abcdefg.com/health")
+	checkout, _ := url.Parse("http://www.abcdefg.com/checkout")
+	users, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/x")
+	missing, _ := url.Parse("http://www.abcdefg.com/missing")
+	for i := 0; i < 1000; i++ {
+		rt.Find(health)
+		rt.Find(users)
+	}
+	rt.Find(checkout)
+	rt.Find(missing)
+	if len(hook.misses) != 1 {
+		t.Log("miss was not reported")
+		t.FailNow()
+	}
+	traced := 0
+	for _, event := range hook.matches {
+		if event.Hash == CreateHash(health) {
+			t.Log("unsampled route was reported")
+			t.FailNow()
+		}
+		if event.Traced {
+			traced++
+		}
+	}
+	if traced != 1 {
+		t.Log("trace sampling is invalid")
+		t.FailNow()
+	}
+	if len(hook.matches) < 350 || len(hook.matches) > 650 {
+		t.Logf("unexpected number of sampled matches %d", len(hook.matches))
+		t.FailNow()
+	}
+}
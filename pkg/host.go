@@ -0,0 +1,147 @@
+package gtr
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Parses a route template. Unlike url.Parse, the labels of the host may
+// be parameters written as `:tenant` or `{tenant}`, for example
+// `https://:tenant.example.com/users`. Labels written as `*` match any
+// label without capturing it.
+func ParseTemplate(template string) (*url.URL, error) {
+	scheme, rest, ok := strings.Cut(template, "://")
+	if !ok {
+		return url.Parse(template)
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	labels := strings.Split(rest[:end], ".")
+	placeholders := make([]string, len(labels))
+	params := false
+	for index, label := range labels {
+		placeholders[index] = label
+		if name, ok := placeholder(label); ok {
+			labels[index] = ":" + name
+			placeholders[index] = "param"
+			params = true
+		}
+	}
+	if !params {
+		return url.Parse(template)
+	}
+	// url.Parse rejects parameters in hosts, so placeholders are parsed
+	// in their place
+	parsed, err := url.Parse(scheme + "://" + strings.Join(placeholders, ".") + rest[end:])
+	if err != nil {
+		return nil, err
+	}
+	parsed.Host = strings.Join(labels, ".")
+	return parsed, nil
+}
+
+// Splits a host into its name and port. Parameter labels start with a
+// colon, so only a colon after the first character starts a port.
+func splitHost(host string) (string, string) {
+	index := strings.LastIndex(host, ":")
+	if index <= 0 || strings.Contains(host[index:], ".") || strings.Contains(host[index:], "]") {
+		return host, ""
+	}
+	return host[:index], host[index+1:]
+}
+
+// Parses the host of a template into labels, lower-casing the literal
+// ones. The host rank orders hosts
+// by specificity: literal labels count twice, parameters and wildcards
+// once, and templates without a host match every host with a rank of 0.
+func (route *Route) parseHost() {
+	if len(route.host) == 0 {
+		return
+	}
+	name, port := splitHost(route.host)
+	route.hostPort = port
+	route.hostLabels = strings.Split(name, ".")
+	route.hostParams = make(map[int]string)
+	route.hostConstraints = make(map[int]paramConstraint)
+	for index, label := range route.hostLabels {
+		switch {
+		case label == "*":
+			route.hostRank++
+		case strings.HasPrefix(label, ":"):
+			// Invalid constraints are rejected by validateParams
+			name, constraint, _ := parseParam(label)
+			route.hostParams[index] = name
+			if constraint != nil {
+				route.hostConstraints[index] = constraint
+			}
+			route.hostRank++
+		default:
+			route.hostLabels[index] = strings.ToLower(label)
+			route.hostRank += 2
+		}
+	}
+}
+
+// Checks whether a host matches the host of the template. Templates
+// without a host match every host, and hosts are not checked for URLs
+// without a host, for example the URLs of server requests, unless the
+// template captures host parameters. Such URLs would otherwise share
+// the cache keys of every tenant.
+func (route *Route) acceptsHost(host string) bool {
+	if len(route.hostLabels) == 0 {
+		return true
+	}
+	if len(host) == 0 {
+		return len(route.hostParams) == 0
+	}
+	name, port := splitHost(host)
+	if len(route.hostPort) > 0 && route.hostPort != port {
+		return false
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) != len(route.hostLabels) {
+		return false
+	}
+	for index, label := range route.hostLabels {
+		if constraint, ok := route.hostConstraints[index]; ok && !constraint.accepts(labels[index]) {
+			return false
+		}
+		if label == "*" || strings.HasPrefix(label, ":") {
+			continue
+		}
+		if !strings.EqualFold(label, labels[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Adds the values of the host parameters of the template to the values
+// of a match. Hosts are case-insensitive, so values are lower-cased.
+func (route *Route) hostValues(host string, values map[string]string) {
+	if len(route.hostParams) == 0 || len(host) == 0 {
+		return
+	}
+	name, _ := splitHost(host)
+	labels := strings.Split(strings.ToLower(name), ".")
+	for index, param := range route.hostParams {
+		values[param] = labels[index]
+	}
+}
+
+// Gets the names of the host parameters in the order they appear
+func (route *Route) hostParamNames() []string {
+	indexes := make([]int, 0, len(route.hostParams))
+	for index := range route.hostParams {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	names := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		names = append(names, route.hostParams[index])
+	}
+	return names
+}
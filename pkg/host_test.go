@@ -0,0 +1,224 @@
+package gtr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func PrepareHostTable(t *testing.T) *RouteTable {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	for _, template := range []string{
+		"http://api.example.com/users/:id",
+		"http://*.example.com/users/:id",
+		"http://:tenant.shop.com/users/:id",
+		"/users/:id",
+	} {
+		url, err := ParseTemplate(template)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if err := rt.Register(url, map[string]any{"template": template}); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	return rt
+}
+
+func TestHostRouting(t *testing.T) {
+	rt := PrepareHostTable(t)
+	tests := map[string]string{
+		"http://API.example.com/users/1":    "http://api.example.com/users/:id",
+		"http://www.example.com/users/1":    "http://*.example.com/users/:id",
+		"http://acme.shop.com/users/1":      "http://:tenant.shop.com/users/:id",
+		"http://acme.shop.com:8080/users/1": "http://:tenant.shop.com/users/:id",
+		"http://a.b.example.com/users/1":    "/users/:id",
+		"http://www.abcdefg.com/users/1":    "/users/:id",
+	}
+	for raw, expected := range tests {
+		match, err := rt.Match(PrepareURLFrom(t, raw))
+		if err != nil {
+			t.Logf("%s: %s", raw, err)
+			t.FailNow()
+		}
+		if template := rt.GetConfig(match.Hash)["template"]; template != expected {
+			t.Logf("expected %s to match %s but found %v", raw, expected, template)
+			t.FailNow()
+		}
+	}
+	match, _ := rt.Match(PrepareURLFrom(t, "http://acme.shop.com/users/1"))
+	if match.Params["tenant"] != "acme" || match.Params["id"] != "1" {
+		t.Logf("unexpected params %v", match.Params)
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(match.Hash)
+	if params := route.Params(); len(params) != 2 || params[0] != "tenant" {
+		t.Logf("unexpected params %v", params)
+		t.FailNow()
+	}
+	if route.Format(PARAM_STYLE_BRACE) != "http://{tenant}.shop.com/users/{id}" {
+		t.Logf("unexpected format %s", route.Format(PARAM_STYLE_BRACE))
+		t.FailNow()
+	}
+}
+
+func TestHostOnlyDifference(t *testing.T) {
+	rt := DefaultRouteTable()
+	if err := rt.Register(PrepareURLFrom(t, "http://a.com/x/:id"), map[string]any{"host": "a"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://b.com/x/:id"), map[string]any{"host": "b"}); err != nil {
+		t.Logf("expected routes only differing by host to be registered but found %v", err)
+		t.FailNow()
+	}
+	if rt.Hasher().Version != HASH_V4 {
+		t.Logf("expected the table to switch to HASH_V4 but found %d", rt.Hasher().Version)
+		t.FailNow()
+	}
+	for _, host := range []string{"a", "b"} {
+		hash, err := rt.Find(PrepareURLFrom(t, "http://"+host+".com/x/1"))
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if conf := rt.GetConfig(hash); conf["host"] != host {
+			t.Logf("expected the config of %s but found %v", host, conf)
+			t.FailNow()
+		}
+	}
+	// Routes without a host keep their hashes
+	template := PrepareURLFrom(t, "/x/:id/y")
+	rt.Register(template, nil)
+	if _, err := rt.Lookup(CreateHash(template)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	url, err := ParseTemplate("https://{tenant}.example.com:8443/users/{id}?q=1")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if url.Host != ":tenant.example.com:8443" || url.Path != "/users/{id}" || url.RawQuery != "q=1" {
+		t.Logf("unexpected template %s %s %s", url.Host, url.Path, url.RawQuery)
+		t.FailNow()
+	}
+	route := ParseRoute(url)
+	if route.hostPort != "8443" || route.hostParams[0] != "tenant" {
+		t.Logf("unexpected host %v %s", route.hostLabels, route.hostPort)
+		t.FailNow()
+	}
+	if route.acceptsHost("acme.example.com:80") || !route.acceptsHost("acme.example.com:8443") {
+		t.Log("expected ports of the template to be checked")
+		t.FailNow()
+	}
+}
+
+func TestMuxHostRouting(t *testing.T) {
+	mux := NewMux()
+	mux.Table().hasher = Hasher{Version: HASH_V4}
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		host := host
+		mux.HandleFunc("http://"+host+"/ping", nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(host))
+		})
+	}
+	request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	request.Host = "b.example.com"
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+	if recorder.Body.String() != "b.example.com" {
+		t.Logf("expected the request host to be matched but found %s", recorder.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestMuxHostRehash(t *testing.T) {
+	mux := NewMux()
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		host := host
+		if err := mux.HandleFunc("http://"+host+"/ping", nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(host))
+		}); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		request.Host = host
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		if recorder.Body.String() != host {
+			t.Logf("expected the handler of %s to survive the rehash but found %q", host, recorder.Body.String())
+			t.FailNow()
+		}
+	}
+}
+
+func TestHostParams(t *testing.T) {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	template, err := ParseTemplate("http://:tenantId.:region.api.example.com/items")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	typed, _ := ParseTemplate("http://:v<int>.versions.example.com/items")
+	if err := rt.Register(typed, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "http://ACME.eu.api.example.com/items"))
+	if err != nil || match.Params["tenantId"] != "acme" || match.Params["region"] != "eu" {
+		t.Logf("unexpected match %v %v", match, err)
+		t.FailNow()
+	}
+	match, err = rt.Match(PrepareURLFrom(t, "http://3.versions.example.com/items"))
+	if err != nil || match.Typed["v"] != int64(3) {
+		t.Logf("unexpected match %v %v", match, err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURLFrom(t, "http://latest.versions.example.com/items")); err == nil {
+		t.Log("expected the host constraint to reject the URL")
+		t.FailNow()
+	}
+
+	// Tenants never share cache keys, and URLs without a host cannot
+	// match routes capturing host parameters
+	first, _ := rt.CacheKey(PrepareURLFrom(t, "http://acme.eu.api.example.com/items"))
+	second, _ := rt.CacheKey(PrepareURLFrom(t, "http://globex.eu.api.example.com/items"))
+	third, _ := rt.CacheKey(PrepareURLFrom(t, "http://ACME.eu.api.example.com/items"))
+	if first == second || first != third {
+		t.Log("expected cache keys to vary by tenant only")
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURLFrom(t, "/items")); err == nil {
+		t.Log("expected URLs without a host not to match")
+		t.FailNow()
+	}
+	for _, target := range rt.ProbeTargets() {
+		if _, err := rt.Find(PrepareURLFrom(t, target.URL)); err != nil {
+			t.Logf("expected the probe URL %s to match: %v", target.URL, err)
+			t.FailNow()
+		}
+	}
+}
+
+func TestHostParamsInvalid(t *testing.T) {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	template, _ := ParseTemplate("http://:v<integer>.example.com/items")
+	if err := rt.Register(template, nil); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
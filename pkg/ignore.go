@@ -0,0 +1,141 @@
+package gtr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+)
+
+// Sets the query params ignored by cache keys of every route
+// Params:
+//   - patterns: Param names where `*` matches any sequence of
+//     characters, for example `utm_*`
+func (rt *RouteTable) SetIgnoredQuery(patterns ...string) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.ignored = append([]string(nil), patterns...)
+}
+
+// Ignores additional query params in the cache keys of a route
+func IgnoreQuery(patterns ...string) RouteOption {
+	return func(route *Route) error {
+		route.ignore = append(route.ignore, patterns...)
+		return nil
+	}
+}
+
+// Keeps query params in the cache keys of a route even though they
+// are ignored table-wide
+func KeepQuery(patterns ...string) RouteOption {
+	return func(route *Route) error {
+		route.keep = append(route.keep, patterns...)
+		return nil
+	}
+}
+
+// Checks whether a query param is ignored by the cache keys of a route
+func (rt *RouteTable) IsQueryIgnored(hash string, key string) bool {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return globMatchAny(rt.ignored, key)
+	}
+	return rt.isQueryIgnored(route, key)
+}
+
+func (rt *RouteTable) isQueryIgnored(route *Route, key string) bool {
+	if globMatchAny(route.ignore, key) {
+		return true
+	}
+	return globMatchAny(rt.ignored, key) && !globMatchAny(route.keep, key) && !route.varies(key)
+}
+
+// Creates a cache key for a URL. The key covers the matching route,
+// its parameter values, and every query param that is not ignored,
+// regardless of the order in which they appear.
+func (rt *RouteTable) CacheKey(url *url.URL) (string, error) {
+	return rt.cacheKey("", url)
+}
+
+func (rt *RouteTable) cacheKey(method string, url *url.URL) (string, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, prt, err := rt.match(method, url)
+	if err != nil {
+		return "", err
+	}
+	defer releaseRoute(prt)
+	hash := sha256.New()
+	hash.Write([]byte(route.hash))
+	writeKeyVersion(hash, rt.configs[route.hash])
+	values := route.values(prt)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		hash.Write([]byte{0})
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(values[name]))
+	}
+	query := url.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !rt.isQueryIgnored(route, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			hash.Write([]byte{1})
+			hash.Write([]byte(key))
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func globMatchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if globMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches a value against a pattern where `*` matches any sequence
+// of characters. Unlike path.Match, brackets have no special meaning.
+func globMatch(pattern string, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star = p
+			mark = v
+			p++
+		case p < len(pattern) && pattern[p] == value[v]:
+			p++
+			v++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,60 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := map[[2]string]bool{
+		{"utm_*", "utm_source"}:       true,
+		{"utm_*", "xutm_source"}:      false,
+		{"filter[*]", "filter[name]"}: true,
+		{"*", ""}:                     true,
+		{"a*b*c", "aXXbYYc"}:          true,
+		{"a*b*c", "aXXbYY"}:           false,
+		{"exact", "exact"}:            true,
+		{"exact", "exactly"}:          false,
+	}
+	for test, expected := range tests {
+		if globMatch(test[0], test[1]) != expected {
+			t.Logf("globMatch(%q, %q) should be %v", test[0], test[1], expected)
+			t.FailNow()
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetIgnoredQuery("utm_*", "fbclid")
+	users, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	analytics, _ := url.Parse("http://www.abcdefg.com/api/v1/analytics/:id")
+	rt.Register(users, nil, IgnoreQuery("debug"))
+	rt.Register(analytics, nil, KeepQuery("utm_*"))
+	key := func(raw string) string {
+		url, _ := url.Parse(raw)
+		key, err := rt.CacheKey(url)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		return key
+	}
+	base := key("http://www.abcdefg.com/api/v1/users/ken?a=1&b=2")
+	if base != key("http://www.abcdefg.com/api/v1/users/ken?b=2&a=1&utm_source=x&fbclid=y&debug=1") {
+		t.Log("ignored or reordered params changed the key")
+		t.FailNow()
+	}
+	if base == key("http://www.abcdefg.com/api/v1/users/dennis?a=1&b=2") {
+		t.Log("route params are not part of the key")
+		t.FailNow()
+	}
+	if key("http://www.abcdefg.com/api/v1/analytics/1") == key("http://www.abcdefg.com/api/v1/analytics/1?utm_source=x") {
+		t.Log("kept params are not part of the key")
+		t.FailNow()
+	}
+	if !rt.IsQueryIgnored(CreateHash(users), "utm_medium") || rt.IsQueryIgnored(CreateHash(analytics), "utm_medium") {
+		t.Log("ignore overrides resolved incorrectly")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,78 @@
+package gtr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// The JSONRPCCall struct describes a single JSON-RPC call
+type JSONRPCCall struct {
+	Method string
+	ID     json.RawMessage
+}
+
+// The JSONRPCMatch struct is the outcome of matching a single call
+// of a (batch) JSON-RPC request
+type JSONRPCMatch struct {
+	Call  JSONRPCCall
+	Match *MatchResult
+	Err   error
+}
+
+// Gets the virtual URL of the call. Calls are routed as
+// `jsonrpc:<method>`.
+func (call *JSONRPCCall) URL() *url.URL {
+	return virtualURL("jsonrpc", call.Method, nil)
+}
+
+// Parses the calls of a JSON-RPC request body. Batch requests
+// are expanded into one call per entry.
+func ParseJSONRPC(body []byte) ([]JSONRPCCall, error) {
+	body = bytes.TrimSpace(body)
+	type request struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	requests := make([]request, 1)
+	if bytes.HasPrefix(body, []byte("[")) {
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return nil, fmt.Errorf("%w: %s", INVALID_BODY, err.Error())
+		}
+		if len(requests) == 0 {
+			return nil, fmt.Errorf("%w: empty batch", INVALID_BODY)
+		}
+	} else {
+		if err := json.Unmarshal(body, &requests[0]); err != nil {
+			return nil, fmt.Errorf("%w: %s", INVALID_BODY, err.Error())
+		}
+	}
+	calls := make([]JSONRPCCall, 0, len(requests))
+	for _, request := range requests {
+		if len(request.Method) == 0 {
+			return nil, fmt.Errorf("%w: missing method", INVALID_BODY)
+		}
+		calls = append(calls, JSONRPCCall{Method: request.Method, ID: request.ID})
+	}
+	return calls, nil
+}
+
+// Matches every call of a JSON-RPC request body against the virtual
+// routes of the table. Calls that do not match carry their own error.
+func (rt *RouteTable) MatchJSONRPC(body []byte) ([]JSONRPCMatch, error) {
+	calls, err := ParseJSONRPC(body)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]JSONRPCMatch, 0, len(calls))
+	for _, call := range calls {
+		match, err := rt.Match(call.URL())
+		matches = append(matches, JSONRPCMatch{
+			Call:  call,
+			Match: match,
+			Err:   err,
+		})
+	}
+	return matches, nil
+}
@@ -0,0 +1,46 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestMatchJSONRPC(t *testing.T) {
+	rt := newRouteTable()
+	balance, _ := url.Parse("jsonrpc:eth_getBalance")
+	rt.Register(balance, map[string]any{"ttl": 5})
+	matches, err := rt.MatchJSONRPC([]byte(`[
+		{"jsonrpc":"2.0","method":"eth_getBalance","id":1},
+		{"jsonrpc":"2.0","method":"eth_sendTransaction","id":2}
+	]`))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(matches) != 2 {
+		t.Log("batch was not expanded")
+		t.FailNow()
+	}
+	if matches[0].Err != nil || rt.GetConfig(matches[0].Match.Hash)["ttl"] != 5 {
+		t.Log("call routed incorrectly")
+		t.FailNow()
+	}
+	if string(matches[0].Call.ID) != "1" {
+		t.Log("call id was not preserved")
+		t.FailNow()
+	}
+	if matches[1].Err == nil {
+		t.Log("unregistered method matched")
+		t.FailNow()
+	}
+	single, err := rt.MatchJSONRPC([]byte(`{"jsonrpc":"2.0","method":"eth_getBalance","id":"a"}`))
+	if err != nil || len(single) != 1 || single[0].Err != nil {
+		t.Log("single call routed incorrectly")
+		t.FailNow()
+	}
+	if _, err := rt.MatchJSONRPC([]byte(`[]`)); !errors.Is(err, INVALID_BODY) {
+		t.Log("expected empty batch to fail")
+		t.FailNow()
+	}
+}
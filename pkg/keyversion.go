@@ -0,0 +1,41 @@
+package gtr
+
+import (
+	"hash"
+	"strconv"
+)
+
+// Bumps the version of the cache keys of a route (see
+// POLICY_KEY_VERSION), so that every cache key created for the route
+// from now on differs from the keys created before. This invalidates
+// every cached response of the route at once without touching the
+// cache backend, where the stale entries simply expire.
+// Returns the new version
+func (rt *RouteTable) BumpKeyVersion(hash string) (int, error) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return 0, err
+	}
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return 0, err
+	}
+	version := Policy(rt.configs[hash]).KeyVersion() + 1
+	if err := rt.setConfig(hash, MergePatch(rt.configs[hash], map[string]any{POLICY_KEY_VERSION: version})); err != nil {
+		return 0, err
+	}
+	if err := rt.record(EVENT_UPDATE, route, rt.configs[hash]); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Adds the key version of a config to a cache key. Routes that never
+// had their version bumped keep the keys they had before versioning.
+func writeKeyVersion(digest hash.Hash, conf map[string]any) {
+	if version := Policy(conf).KeyVersion(); version != 0 {
+		digest.Write([]byte{2})
+		digest.Write([]byte(strconv.Itoa(version)))
+	}
+}
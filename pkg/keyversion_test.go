@@ -0,0 +1,65 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestBumpKeyVersion(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 10}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	graphql, _ := url.Parse("http://www.abcdefg.com/graphql")
+	if err := rt.Register(graphql, nil, WithBodyKeys("/operationName")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	before, _ := rt.CacheKey(PrepareURL(t))
+	if version, err := rt.BumpKeyVersion(hash); err != nil || version != 1 {
+		t.Logf("expected version 1 but found %d: %v", version, err)
+		t.FailNow()
+	}
+	after, _ := rt.CacheKey(PrepareURL(t))
+	if before == after {
+		t.Log("expected bumping the key version to change the cache keys")
+		t.FailNow()
+	}
+	if again, _ := rt.CacheKey(PrepareURL(t)); again != after {
+		t.Log("expected the cache keys of a version to be stable")
+		t.FailNow()
+	}
+	if conf := rt.GetConfig(hash); conf["ttl"] != 10 || rt.GetPolicy(hash, "GET").KeyVersion() != 1 {
+		t.Logf("unexpected config %v", conf)
+		t.FailNow()
+	}
+	if err := rt.PatchConfig(hash, []byte(`{"key_version": 5}`)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if version, _ := rt.BumpKeyVersion(hash); version != 6 {
+		t.Logf("expected version 6 but found %d", version)
+		t.FailNow()
+	}
+	match, _ := rt.Match(graphql)
+	body := []byte(`{"operationName":"GetUser"}`)
+	first, _ := rt.CacheKeyForBody(match, body)
+	rt.BumpKeyVersion(match.Hash)
+	if second, _ := rt.CacheKeyForBody(match, body); first == second {
+		t.Log("expected bumping the key version to change the body cache keys")
+		t.FailNow()
+	}
+	if _, err := rt.BumpKeyVersion("unknown"); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	rt.Freeze()
+	if _, err := rt.BumpKeyVersion(hash); !errors.Is(err, TABLE_FROZEN) {
+		t.Logf("expected TABLE_FROZEN but found %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,140 @@
+package gtr
+
+import "net/url"
+
+// The MatchResult struct describes a successful route match
+// Params:
+//   - Hash: The hash of the matching route template
+//   - Name: The name of the matching route, if any (see WithName)
+//   - Template: The template of the matching route
+//   - Params: The values of the route parameters keyed by name
+//   - Typed: The coerced values of typed route parameters keyed by
+//     name, for example an int64 for `:id<int>`
+//   - Query: The coerced values of typed query parameters
+//   - Rank: The rank of the match, higher ranks being more specific
+//   - QueryRank: The number of query params the matching route
+//     constrains, which tells apart routes of equal rank
+//   - Segments: Whether each path segment matched a literal or a param
+//   - Uncovered: The query params neither declared by the template nor
+//     ignored by its cache keys
+//   - Config: The config of the matching route, served by the config
+//     provider for routes without a config
+//   - Extension: The file extension of the URL for routes with an
+//     extension parameter, for example `pdf` for `:name.:ext(pdf|csv)`
+//   - Variant: The value of the variant parameter of the matching
+//     route, if any (see WithVariants)
+type MatchResult struct {
+	Hash      string
+	Name      string
+	Template  string
+	Params    map[string]string
+	Typed     map[string]any
+	Query     map[string]any
+	Rank      int
+	QueryRank int
+	Segments  []CheckResult
+	Uncovered []string
+	Config    map[string]any
+	Extension string
+	Variant   string
+}
+
+// Matches a URL against the route table. Match is the primary lookup
+// API, while Find only gets the hash of the matching route.
+func (rt *RouteTable) Match(url *url.URL) (*MatchResult, error) {
+	var match *MatchResult
+	var provider *providerCache
+	err := rt.resolve("", url, func(route *Route, prt *Route) error {
+		query := make(map[string]any, len(route.queryTypes))
+		for key, paramType := range route.queryTypes {
+			value, err := paramType.Coerce(prt.queryParams[key])
+			if err != nil {
+				// Only strict routes guarantee typed query params,
+				// the others leave out the values that are invalid
+				if route.queryMode != QUERY_STRICT {
+					continue
+				}
+				return err
+			}
+			query[key] = value
+		}
+		typed := make(map[string]any)
+		for position, segment := range route.segments {
+			constraint := segment.constraint
+			if extension, ok := constraint.(extensionConstraint); ok {
+				constraint = extension.base
+			}
+			if constraint, ok := constraint.(typeConstraint); ok {
+				base, _ := segment.split(prt.segments[position].value)
+				value, err := constraint.paramType.Coerce(base)
+				if err != nil {
+					return err
+				}
+				typed[segment.value] = value
+			}
+		}
+		params := route.values(prt)
+		for key, constraint := range route.queryConstraints {
+			if constraint, ok := constraint.(typeConstraint); ok {
+				coerced, err := constraint.paramType.Coerce(params[route.queryNames[key]])
+				if err != nil {
+					if route.queryMode != QUERY_STRICT {
+						continue
+					}
+					return err
+				}
+				typed[route.queryNames[key]] = coerced
+			}
+		}
+		for index, constraint := range route.hostConstraints {
+			if constraint, ok := constraint.(typeConstraint); ok {
+				coerced, err := constraint.paramType.Coerce(params[route.hostParams[index]])
+				if err != nil {
+					return err
+				}
+				typed[route.hostParams[index]] = coerced
+			}
+		}
+		match = &MatchResult{
+			Hash:      route.hash,
+			Name:      route.name,
+			Template:  route.template,
+			Params:    params,
+			Typed:     typed,
+			Query:     query,
+			Rank:      rt.rank(route, prt),
+			QueryRank: route.queryWeight(),
+			Segments:  route.segmentKinds(),
+			Uncovered: rt.uncovered(route, url.Query()),
+			Config:    rt.configs[route.hash],
+		}
+		if _, ok := route.extension(); ok {
+			match.Extension = params[route.segments[len(route.segments)-1].extension]
+		}
+		if route.variants != nil {
+			match.Variant = params[route.variants.Param]
+		}
+		provider = rt.provider
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The provider may be slow, so it is called without holding the lock
+	if match.Config == nil && provider != nil {
+		match.Config = provider.config(match.Hash)
+	}
+	return match, nil
+}
+
+// Gets whether each path segment of the route is a literal or a param
+func (route *Route) segmentKinds() []CheckResult {
+	kinds := make([]CheckResult, len(route.segments))
+	for i, segment := range route.segments {
+		kinds[i] = CHECK_LITERAL
+		if segment.param() {
+			kinds[i] = CHECK_PARAM
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,117 @@
+package gtr
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatchTypedQuery(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/posts?page=<int>&active=<bool>": nil,
+	})
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/posts?page=2&active=true")
+	match, err := rt.Match(url)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Params["username"] != "ken" {
+		t.Log("route params extracted incorrectly")
+		t.FailNow()
+	}
+	if match.Query["page"] != int64(2) || match.Query["active"] != true {
+		t.Log("query params coerced incorrectly")
+		t.FailNow()
+	}
+	url.RawQuery = "page=two&active=true"
+	if _, err := rt.Match(url); err == nil {
+		t.Log("expected coercion failure to fail the match")
+		t.FailNow()
+	}
+}
+
+func TestMatchRank(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": nil,
+		"http://www.abcdefg.com/api/v1/:kind/:id/details":       nil,
+	})
+	specific, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/details")
+	match, err := rt.Match(specific)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := []CheckResult{CHECK_LITERAL, CHECK_LITERAL, CHECK_LITERAL, CHECK_PARAM, CHECK_LITERAL}
+	if !reflect.DeepEqual(match.Segments, expected) {
+		t.Logf("unexpected segments %v", match.Segments)
+		t.FailNow()
+	}
+	broad, _ := url.Parse("http://www.abcdefg.com/api/v1/posts/1/details")
+	other, err := rt.Match(broad)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Rank != 9 || other.Rank != 8 {
+		t.Logf("unexpected ranks %d and %d", match.Rank, other.Rank)
+		t.FailNow()
+	}
+}
+
+func TestMatchQueryRank(t *testing.T) {
+	templates := []string{"/x/:a", "/x/:a?type=cache"}
+	// Both orders of registration, with the trie and with a comparator
+	for _, comparator := range []Comparator{nil, DefaultComparator} {
+		for _, reversed := range []bool{false, true} {
+			rt := newRouteTable()
+			rt.SetComparator(comparator)
+			for i := range templates {
+				template := templates[i]
+				if reversed {
+					template = templates[len(templates)-1-i]
+				}
+				rt.Register(PrepareURLFrom(t, template), nil)
+			}
+			match, err := rt.Match(PrepareURLFrom(t, "/x/1?type=cache"))
+			if err != nil {
+				t.Log(err)
+				t.FailNow()
+			}
+			if match.Template != "/x/:a?type=cache" || match.QueryRank != 1 {
+				t.Logf("expected the query-constrained route to win but found %s", match.Template)
+				t.FailNow()
+			}
+			if match, _ := rt.Match(PrepareURLFrom(t, "/x/1")); match.Template != "/x/:a" || match.QueryRank != 0 || match.Rank != 3 {
+				t.Logf("unexpected match %v", match)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestMatchTemplateAndConfig(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10},
+		"http://www.abcdefg.com/api/v1/posts/:id":                          nil,
+	})
+	match, err := rt.Match(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Template != "http://www.abcdefg.com/api/v1/users/:username/details?type=cache" || match.Config["ttl"] != 10 {
+		t.Logf("unexpected match %s %v", match.Template, match.Config)
+		t.FailNow()
+	}
+	// Routes without a config fall back to the config provider
+	rt.SetConfigProvider(ConfigProviderFunc(func(key string) (map[string]any, bool, error) {
+		return map[string]any{"ttl": 1}, true, nil
+	}), time.Minute, time.Minute)
+	match, err = rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1"))
+	if err != nil || match.Config["ttl"] != 1 {
+		t.Logf("expected the provided config but found %v %v", match, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,180 @@
+package gtr
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// The ConflictKind describes why two routes could not be merged
+type ConflictKind string
+
+const (
+	// Both tables register the same template with different configs
+	CONFLICT_CONFIG ConflictKind = "config"
+	// The templates differ but always match the same URLs with the same rank
+	CONFLICT_AMBIGUOUS ConflictKind = "ambiguous"
+	// The templates differ but are registered under the same name
+	CONFLICT_NAME ConflictKind = "name"
+	// The templates differ only by query constraints that a single URL
+	// can satisfy at once (see AMBIGUOUS_ROUTE)
+	CONFLICT_OVERLAP ConflictKind = "overlap"
+)
+
+// The ConflictReport lists every conflict encountered while merging
+// route tables. It is JSON serializable so that it can be published
+// as a build artifact.
+type ConflictReport struct {
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// The Conflict struct describes a single merge conflict
+type Conflict struct {
+	Kind     ConflictKind `json:"kind"`
+	Existing ConflictSide `json:"existing"`
+	Incoming ConflictSide `json:"incoming"`
+}
+
+// The ConflictSide struct describes one side of a merge conflict
+type ConflictSide struct {
+	Hash      string         `json:"hash"`
+	Template  string         `json:"template"`
+	Config    map[string]any `json:"config"`
+	Ownership *Ownership     `json:"ownership,omitempty"`
+}
+
+// Checks whether the report contains any conflict
+func (report *ConflictReport) HasConflicts() bool {
+	return len(report.Conflicts) > 0
+}
+
+// Serializes the report to indented JSON
+func (report *ConflictReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// Merges all routes of another table into the route table
+// Conflicting routes are left untouched and are listed in the returned report
+// The merged routes are patched by the overrides of the route table
+// like registered routes (see OverrideConfig)
+func (rt *RouteTable) Merge(other *RouteTable) (*ConflictReport, error) {
+	// The other table is copied first so that the locks of both tables
+	// are never held at once
+	other.mutex.RLock()
+	incomings := other.sorted()
+	configs := copyMap(other.configs)
+	typeds := copyMap(other.typed)
+	other.mutex.RUnlock()
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return nil, err
+	}
+	report := &ConflictReport{Conflicts: make([]Conflict, 0)}
+	shapes := make(map[string]*Route)
+	for _, route := range rt.index {
+		shapes[route.shape()] = route
+	}
+	for _, incoming := range incomings {
+		conf := configs[incoming.hash]
+		typed, hasTyped := typeds[incoming.hash]
+		incoming = incoming.clone()
+		incoming.hash = rt.hasher.hashRoute(incoming)
+		if existing, ok := rt.index[incoming.hash]; ok {
+			if !reflect.DeepEqual(rt.configs[existing.hash], conf) {
+				report.Conflicts = append(report.Conflicts, rt.conflict(CONFLICT_CONFIG, existing, incoming, conf))
+			}
+			continue
+		}
+		if existing, ok := shapes[incoming.shape()]; ok {
+			report.Conflicts = append(report.Conflicts, rt.conflict(CONFLICT_AMBIGUOUS, existing, incoming, conf))
+			continue
+		}
+		if existing, err := rt.named(incoming.name); err == nil {
+			report.Conflicts = append(report.Conflicts, rt.conflict(CONFLICT_NAME, existing, incoming, conf))
+			continue
+		}
+		if existing := rt.overlapping(incoming); existing != nil {
+			report.Conflicts = append(report.Conflicts, rt.conflict(CONFLICT_OVERLAP, existing, incoming, conf))
+			continue
+		}
+		if hasTyped {
+			if rt.typed == nil {
+				rt.typed = make(map[string]any)
+			}
+			rt.typed[incoming.hash] = typed
+		}
+		if err := rt.insert(incoming, rt.override(incoming, conf)); err != nil {
+			return report, err
+		}
+		shapes[incoming.shape()] = incoming
+	}
+	return report, nil
+}
+
+func (rt *RouteTable) conflict(kind ConflictKind, existing *Route, incoming *Route, conf map[string]any) Conflict {
+	return Conflict{
+		Kind: kind,
+		Existing: ConflictSide{
+			Hash:      existing.hash,
+			Template:  existing.template,
+			Config:    rt.configs[existing.hash],
+			Ownership: existing.owners(),
+		},
+		Incoming: ConflictSide{
+			Hash:      incoming.hash,
+			Template:  incoming.template,
+			Config:    conf,
+			Ownership: incoming.owners(),
+		},
+	}
+}
+
+// Gets the registered routes ordered by their templates
+func (rt *RouteTable) sorted() []*Route {
+	routes := make([]*Route, 0, len(rt.index))
+	for _, route := range rt.index {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].template < routes[j].template
+	})
+	return routes
+}
+
+// Creates a key that is identical for routes matching the same URLs
+func (route *Route) shape() string {
+	buffer := strings.Builder{}
+	buffer.WriteString(route.method)
+	buffer.WriteString(" ")
+	buffer.WriteString(strings.Join(route.hostLabels, "."))
+	for _, segment := range route.segments {
+		buffer.WriteString("/")
+		if !segment.param() {
+			buffer.WriteString(segment.value)
+			continue
+		}
+		buffer.WriteString("?")
+		if segment.constraint != nil {
+			buffer.WriteString(segment.constraint.String())
+		}
+	}
+	query := copyMap(route.queryParams)
+	for pattern, value := range route.queryWildcards {
+		query[pattern] = value
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		buffer.WriteString("&")
+		buffer.WriteString(key)
+		buffer.WriteString("=")
+		buffer.WriteString(query[key])
+	}
+	return buffer.String()
+}
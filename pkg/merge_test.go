@@ -0,0 +1,140 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func PrepareTable(t *testing.T, templates map[string]map[string]any) *RouteTable {
+	rt := newRouteTable()
+	for template, conf := range templates {
+		url, err := url.Parse(template)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if err := rt.Register(url, conf); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	return rt
+}
+
+func TestMerge(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": {"ttl": 10},
+		"http://www.abcdefg.com/api/v1/posts/:id":               {"ttl": 10},
+	})
+	other := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": {"ttl": 20},
+		"http://www.abcdefg.com/api/v1/posts/:postId":           {"ttl": 10},
+		"http://www.abcdefg.com/api/v1/comments/:id":            {"ttl": 10},
+	})
+	report, err := rt.Merge(other)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(report.Conflicts) != 2 {
+		t.Log("expected two conflicts")
+		t.FailNow()
+	}
+	kinds := map[ConflictKind]bool{}
+	for _, conflict := range report.Conflicts {
+		kinds[conflict.Kind] = true
+	}
+	if !kinds[CONFLICT_CONFIG] || !kinds[CONFLICT_AMBIGUOUS] {
+		t.Log("conflict kinds are invalid")
+		t.FailNow()
+	}
+	if len(rt.index) != 3 {
+		t.Log("non conflicting route was not merged")
+		t.FailNow()
+	}
+	data, err := report.JSON()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	decoded := ConflictReport{}
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Conflicts) != 2 {
+		t.Log("report is not JSON serializable")
+		t.FailNow()
+	}
+}
+
+func TestMergeNames(t *testing.T) {
+	rt := newRouteTable()
+	other := newRouteTable()
+	users, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	posts, _ := url.Parse("http://www.abcdefg.com/api/v1/posts/:id")
+	if err := rt.RegisterNamed("details", users, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := other.RegisterNamed("details", posts, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	report, err := rt.Merge(other)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Kind != CONFLICT_NAME || report.Conflicts[0].Incoming.Hash != CreateHash(posts) {
+		t.Logf("expected a name conflict but found %v", report.Conflicts)
+		t.FailNow()
+	}
+	if route, err := rt.LookupName("details"); err != nil || route.Hash() != CreateHash(users) {
+		t.Log("expected the name to keep resolving to the existing route")
+		t.FailNow()
+	}
+	if len(rt.Routes()) != 1 {
+		t.Log("expected the conflicting route to be left out")
+		t.FailNow()
+	}
+}
+
+func TestMergeOverlaps(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users?a=1": nil,
+	})
+	other := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users?b=2": nil,
+	})
+	report, err := rt.Merge(other)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Kind != CONFLICT_OVERLAP {
+		t.Logf("expected an overlap conflict but found %v", report.Conflicts)
+		t.FailNow()
+	}
+	if len(rt.Routes()) != 1 {
+		t.Log("expected the overlapping route to be left out")
+		t.FailNow()
+	}
+}
+
+func TestMergeOverrides(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.OverrideConfig("/api/v1/**", map[string]any{"ttl": 5}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	other := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/posts/:id": {"ttl": 10, "format": "json"},
+	})
+	if _, err := rt.Merge(other); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1"))
+	if err != nil || match.Config["ttl"] != 5 || match.Config["format"] != "json" {
+		t.Logf("expected the override to apply to merged routes but found %v %v", match, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,67 @@
+package gtr
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Registers a new route for a single HTTP method. Requests of that
+// method prefer the route over a route of equal rank registered for
+// any method through Register, and the route has a hash of its own,
+// so the same template may be registered for several methods.
+// Registering an already registered method and URL is a no-op
+// Params:
+//   - method: The HTTP method, case-insensitive. An empty method
+//     registers the route for any method like Register does.
+func (rt *RouteTable) RegisterMethod(method string, url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	_, err := rt.register(method, url, conf, opts...)
+	return err
+}
+
+// Finds the route template for a request of an HTTP method. Routes
+// registered for the method and routes registered for any method are
+// both considered, whereas Find only considers the latter.
+func (rt *RouteTable) FindMethod(method string, url *url.URL) (string, error) {
+	hash := ""
+	err := rt.resolve(strings.ToUpper(method), url, func(route *Route, prt *Route) error {
+		hash = route.hash
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// Gets the route registered for a template and an HTTP method
+// Returns HASH_NOT_REGISTERED if the template was never registered
+// for the method
+func (rt *RouteTable) LookupTemplateMethod(method string, template string) (*Route, error) {
+	return rt.lookupTemplate(method, template)
+}
+
+// Creates a cache key for a request of an HTTP method like CacheKey,
+// considering the routes registered for the method like FindMethod
+func (rt *RouteTable) CacheKeyMethod(method string, url *url.URL) (string, error) {
+	return rt.cacheKey(strings.ToUpper(method), url)
+}
+
+// Gets the HTTP method the route was registered for, or an empty
+// string if it matches any method
+func (route *Route) Method() string {
+	return route.method
+}
+
+// Checks whether the route matches requests of a method
+func (route *Route) accepts(method string) bool {
+	return len(route.method) == 0 || route.method == method
+}
+
+// Checks whether the route is registered for a method while another
+// route is not, which makes it preferred over the other for requests
+// of that method
+func (route *Route) specific(other *Route) bool {
+	return len(route.method) > 0 && len(other.method) == 0
+}
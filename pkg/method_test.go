@@ -0,0 +1,67 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRegisterMethod(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, map[string]any{"ttl": 10}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.RegisterMethod("post", template, map[string]any{"bypass": true}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	url := PrepareURL(t)
+	agnostic, _ := rt.Find(url)
+	get, _ := rt.FindMethod("GET", url)
+	post, _ := rt.FindMethod("POST", url)
+	if agnostic != CreateHash(template) || get != agnostic || post == agnostic {
+		t.Log("expected POST requests to match the POST route and others the route for any method")
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(post)
+	if route.Method() != "POST" || rt.GetConfig(post)["bypass"] != true {
+		t.Logf("unexpected route %s %v", route.Method(), rt.GetConfig(post))
+		t.FailNow()
+	}
+
+	// Method specific routes are only matched for their method
+	other := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id")
+	rt.RegisterMethod("DELETE", other, nil)
+	if _, err := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")); !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")); !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.FindMethod("DELETE", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+func TestRegisterMethodReplay(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLTemplate(t)
+	rt.RegisterMethod("POST", template, nil)
+	replayed := newRouteTable()
+	if err := replayed.Replay(&log); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err := replayed.FindMethod("POST", PrepareURL(t))
+	if err != nil || hash == CreateHash(template) {
+		t.Log("expected the method to be restored by replaying")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,231 @@
+package gtr
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// The Mux struct is an http.Handler dispatching requests to the
+// handler registered for the route template matching their URL
+type Mux struct {
+	rt    *RouteTable
+	mutex sync.RWMutex
+	// The handlers and middlewares of the routes are keyed by template,
+	// since hashes change when the table is rehashed
+	handlers map[string]http.Handler
+	// The middlewares of every route, and of each route
+	middlewares      []Middleware
+	routeMiddlewares map[string][]Middleware
+	// Serves requests that match no route (http.NotFound if nil)
+	NotFound http.Handler
+}
+
+// A Middleware wraps the handler of a route, for example to
+// authenticate or log the requests matching the route. Middlewares can
+// read the match of the request through GetRequestMatch.
+type Middleware func(next http.Handler) http.Handler
+
+// The RequestMatch struct is attached to the context of every request
+// dispatched by a Mux
+type RequestMatch struct {
+	*MatchResult
+	// The policy of the route for the method of the request
+	Policy Policy
+}
+
+type requestMatchKey struct{}
+
+// Creates a Mux backed by a new route table
+func NewMux() *Mux {
+	return &Mux{
+		rt:               newRouteTable(),
+		handlers:         make(map[string]http.Handler),
+		routeMiddlewares: make(map[string][]Middleware),
+	}
+}
+
+// Creates a Mux dispatching requests through the routes of an existing
+// route table, for example a table loaded from rule files. Handlers are
+// attached to the routes of the table through Attach.
+func NewTableMux(rt *RouteTable) *Mux {
+	return &Mux{
+		rt:               rt,
+		handlers:         make(map[string]http.Handler),
+		routeMiddlewares: make(map[string][]Middleware),
+	}
+}
+
+// Gets the route table of the Mux, for example to update configs
+func (mux *Mux) Table() *RouteTable {
+	return mux.rt
+}
+
+// Registers the handler of a route template. Registering a template
+// again replaces its handler and its config, while its options are kept.
+// Params:
+//   - template: The route template, for example `/users/:username`
+//   - conf: The config of the route, from which the request policy is resolved
+//   - handler: The handler serving the requests matching the template
+//   - opts: The options of the route
+func (mux *Mux) Handle(template string, conf map[string]any, handler http.Handler, opts ...RouteOption) error {
+	url, err := ParseTemplate(template)
+	if err != nil {
+		return err
+	}
+	if err := mux.register(url, conf, opts...); err != nil {
+		return err
+	}
+	key, err := mux.registered(template)
+	if err != nil {
+		return err
+	}
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+	mux.handlers[key] = handler
+	return nil
+}
+
+// Registers a template in the route table of the Mux, or replaces the
+// config of the route if the template is already registered
+func (mux *Mux) register(url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	mux.rt.mutex.Lock()
+	defer mux.rt.mutex.Unlock()
+	route, err := mux.rt.register("", url, conf, opts...)
+	if err != nil || route != nil {
+		return err
+	}
+	// The table may have been rehashed while registering, so the
+	// existing route is prepared again
+	route, err = mux.rt.prepare("", url)
+	if err != nil {
+		return err
+	}
+	existing, err := mux.rt.lookup(route.hash)
+	if err != nil {
+		return err
+	}
+	if err := mux.rt.setConfig(existing.hash, mux.rt.override(existing, conf)); err != nil {
+		return err
+	}
+	return mux.rt.recordUpdate(existing.hash)
+}
+
+// Attaches a handler to a route already registered in the route table
+// of the Mux. Attaching a handler again replaces it. Fails with
+// HASH_NOT_REGISTERED if the template is not registered.
+func (mux *Mux) Attach(template string, handler http.Handler) error {
+	key, err := mux.registered(template)
+	if err != nil {
+		return err
+	}
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+	mux.handlers[key] = handler
+	return nil
+}
+
+// Adds middlewares wrapping the handlers of every route. Middlewares
+// run in the order they are added, before the middlewares of the
+// matching route (see UseFor), and only for requests matching a route
+// with a handler.
+func (mux *Mux) Use(middlewares ...Middleware) {
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+	mux.middlewares = append(mux.middlewares, middlewares...)
+}
+
+// Adds middlewares wrapping the handler of a route registered in the
+// route table of the Mux. Middlewares run in the order they are added.
+// Fails with HASH_NOT_REGISTERED if the template is not registered.
+func (mux *Mux) UseFor(template string, middlewares ...Middleware) error {
+	key, err := mux.registered(template)
+	if err != nil {
+		return err
+	}
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+	mux.routeMiddlewares[key] = append(mux.routeMiddlewares[key], middlewares...)
+	return nil
+}
+
+// Gets the key of the handler and middlewares of a template registered
+// in the route table of the Mux, that is the template of its route
+func (mux *Mux) registered(template string) (string, error) {
+	route, err := mux.rt.LookupTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	return route.template, nil
+}
+
+// Registers the handler function of a route template
+func (mux *Mux) HandleFunc(template string, conf map[string]any, handler func(w http.ResponseWriter, r *http.Request), opts ...RouteOption) error {
+	return mux.Handle(template, conf, http.HandlerFunc(handler), opts...)
+}
+
+// Gets the URL of a request to match against route templates. The
+// URLs of server requests carry no host, which is taken from the
+// request so that routes may match by host. The URL of the request is
+// left untouched.
+func RequestURL(r *http.Request) *url.URL {
+	if len(r.URL.Host) > 0 || len(r.Host) == 0 {
+		return r.URL
+	}
+	withHost := *r.URL
+	withHost.Host = r.Host
+	return &withHost
+}
+
+// Dispatches a request to the handler of the matching route
+func (mux *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	match, err := mux.rt.Match(RequestURL(r))
+	if err != nil {
+		if !isMiss(err) {
+			writeError(w, statusOf(err), err)
+			return
+		}
+		mux.notFound(w, r)
+		return
+	}
+	mux.mutex.RLock()
+	handler, ok := mux.handlers[match.Template]
+	if ok {
+		handler = wrap(handler, mux.routeMiddlewares[match.Template])
+		handler = wrap(handler, mux.middlewares)
+	}
+	mux.mutex.RUnlock()
+	if !ok {
+		mux.notFound(w, r)
+		return
+	}
+	requestMatch := RequestMatch{
+		MatchResult: match,
+		Policy:      mux.rt.MatchPolicy(match, r.Method),
+	}
+	handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestMatchKey{}, &requestMatch)))
+}
+
+// Wraps a handler in middlewares, the first middleware being the outermost
+func wrap(handler http.Handler, middlewares []Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func (mux *Mux) notFound(w http.ResponseWriter, r *http.Request) {
+	if mux.NotFound != nil {
+		mux.NotFound.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// Gets the match of a request dispatched by a Mux
+// The second return value is false if the request was not dispatched by a Mux.
+func GetRequestMatch(r *http.Request) (*RequestMatch, bool) {
+	requestMatch, ok := r.Context().Value(requestMatchKey{}).(*RequestMatch)
+	return requestMatch, ok
+}
@@ -0,0 +1,164 @@
+package gtr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMux(t *testing.T) {
+	mux := NewMux()
+	err := mux.HandleFunc("/api/v1/users/:username/details", map[string]any{"ttl": "1m"}, func(w http.ResponseWriter, r *http.Request) {
+		match, ok := GetRequestMatch(r)
+		if !ok {
+			t.Log("expected the request match in the context")
+			t.FailNow()
+		}
+		if match.Policy.Bypass() {
+			w.Write([]byte("bypass:"))
+		}
+		w.Write([]byte(match.Params["username"]))
+	}, WithMethodOverlay(http.MethodPost, map[string]any{"bypass": true}))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	mux.HandleFunc("/api/v1/users/admin/details", nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("admin"))
+	})
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/api/v1/users/ken/details", http.StatusOK, "ken"},
+		{http.MethodPost, "/api/v1/users/ken/details?page=1", http.StatusOK, "bypass:ken"},
+		{http.MethodGet, "/api/v1/users/admin/details", http.StatusOK, "admin"},
+		{http.MethodGet, "/api/v1/posts/1", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, test := range tests {
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, httptest.NewRequest(test.method, test.path, nil))
+		if response.Code != test.status || response.Body.String() != test.body {
+			t.Logf("unexpected response %d %q for %s %s", response.Code, response.Body.String(), test.method, test.path)
+			t.FailNow()
+		}
+	}
+	if _, ok := GetRequestMatch(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Log("expected no match outside of the mux")
+		t.FailNow()
+	}
+}
+
+func TestTableMux(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "/api/v1/posts/:id"), map[string]any{"ttl": 5})
+	mux := NewTableMux(rt)
+	if err := mux.Attach("/api/v1/users/:username", http.NotFoundHandler()); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	err := mux.Attach("/api/v1/posts/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match, _ := GetRequestMatch(r)
+		w.Write([]byte(match.Params["id"]))
+	}))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/posts/1", nil))
+	if response.Code != http.StatusOK || response.Body.String() != "1" {
+		t.Logf("unexpected response %d %q", response.Code, response.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestMuxHandleAgain(t *testing.T) {
+	mux := NewMux()
+	for _, body := range []string{"1m", "2m"} {
+		body := body
+		err := mux.HandleFunc("/api/v1/posts/:id", map[string]any{"ttl": body}, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	route, err := mux.Table().LookupTemplate("/api/v1/posts/:id")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if ttl := mux.Table().GetConfig(route.Hash())["ttl"]; ttl != "2m" {
+		t.Logf("expected the config to be replaced but found %v", ttl)
+		t.FailNow()
+	}
+	response := httptest.NewRecorder()
+	mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/api/v1/posts/1", nil))
+	if response.Body.String() != "2m" {
+		t.Logf("expected the handler to be replaced but found %q", response.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestMuxMiddleware(t *testing.T) {
+	mux := NewMux()
+	mux.HandleFunc("/api/v1/posts/:id", nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("handler"))
+	})
+	mux.HandleFunc("/api/v1/users/:username", nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("handler"))
+	})
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := GetRequestMatch(r); !ok {
+					t.Log("expected the match in the context of middlewares")
+					t.FailNow()
+				}
+				w.Write([]byte(name + ">"))
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	mux.Use(trace("log"), trace("auth"))
+	if err := mux.UseFor("/api/v1/posts/:id", trace("posts")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := mux.UseFor("/api/v1/comments/:id", trace("comments")); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	tests := map[string]string{
+		"/api/v1/posts/1":    "log>auth>posts>handler",
+		"/api/v1/users/ken":  "log>auth>handler",
+		"/api/v1/comments/1": "404 page not found\n",
+	}
+	for path, body := range tests {
+		response := httptest.NewRecorder()
+		mux.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+		if response.Body.String() != body {
+			t.Logf("expected %q but found %q for %s", body, response.Body.String(), path)
+			t.FailNow()
+		}
+	}
+}
+
+func TestRequestURL(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/api/users/ken", nil)
+	request.Host = "www.abcdefg.com"
+	if url := RequestURL(request); url.Host != "www.abcdefg.com" || url.Path != "/api/users/ken" || len(request.URL.Host) != 0 {
+		t.Logf("unexpected request URL %s", url)
+		t.FailNow()
+	}
+	proxied := httptest.NewRequest(http.MethodGet, "http://api.abcdefg.com/api/users/ken", nil)
+	if url := RequestURL(proxied); url != proxied.URL {
+		t.Log("expected absolute request URLs to be kept")
+		t.FailNow()
+	}
+}
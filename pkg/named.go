@@ -0,0 +1,62 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Sets the name of a route, so that it can be looked up, built, and
+// logged by name rather than by hash. Names are unique within a route
+// table.
+func WithName(name string) RouteOption {
+	return func(route *Route) error {
+		route.name = name
+		return nil
+	}
+}
+
+// Gets the name of the route, empty unless set through WithName
+func (route *Route) Name() string {
+	return route.name
+}
+
+// Registers a new route under a symbolic name
+// Registering an already registered URL is a no-op, even if it was
+// registered under another name
+// Params:
+//   - name: The name of the route, for example `user-details`
+func (rt *RouteTable) RegisterNamed(name string, url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	if len(name) == 0 {
+		return fmt.Errorf("%w: routes cannot be named with an empty name", INVALID_VALUE)
+	}
+	return rt.Register(url, conf, append(opts, WithName(name))...)
+}
+
+// Gets the route registered under a name
+func (rt *RouteTable) LookupName(name string) (*Route, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	return rt.named(name)
+}
+
+// Gets the configuration of the route registered under a name
+// Routes without a config fall back to the config provider, if any
+func (rt *RouteTable) GetConfigByName(name string) (map[string]any, error) {
+	route, err := rt.LookupName(name)
+	if err != nil {
+		return nil, err
+	}
+	return rt.GetConfig(route.hash), nil
+}
+
+// Gets the route registered under a name
+func (rt *RouteTable) named(name string) (*Route, error) {
+	if len(name) > 0 {
+		for _, route := range rt.index {
+			if route.name == name {
+				return route, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", NAME_NOT_REGISTERED, name)
+}
@@ -0,0 +1,74 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRegisterNamed(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/details")
+	if err := rt.RegisterNamed("user-details", template, map[string]any{"ttl": 60}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, err := rt.LookupName("user-details")
+	if err != nil || route.Hash() != CreateHash(template) || route.Name() != "user-details" {
+		t.Logf("unexpected route %v %v", route, err)
+		t.FailNow()
+	}
+	if conf, err := rt.GetConfigByName("user-details"); err != nil || conf["ttl"] != 60 {
+		t.Logf("unexpected config %v %v", conf, err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/details"))
+	if err != nil || match.Name != "user-details" {
+		t.Logf("expected the match to be named but found %v %v", match, err)
+		t.FailNow()
+	}
+	if info, _ := rt.Info(route.Hash()); info.Name != "user-details" {
+		t.Logf("expected the info to be named but found %s", info.Name)
+		t.FailNow()
+	}
+	if err := rt.RegisterNamed("user-details", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil); !errors.Is(err, DUPLICATE_NAME) {
+		t.Logf("expected DUPLICATE_NAME but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.RegisterNamed("", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.GetConfigByName("unknown"); !errors.Is(err, NAME_NOT_REGISTERED) {
+		t.Logf("expected NAME_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestNamedRules(t *testing.T) {
+	rt := newRouteTable()
+	rt.RegisterNamed("user-details", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/details"), nil)
+	buffer := bytes.NewBuffer(nil)
+	if err := rt.ExportRules(buffer); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	loaded := newRouteTable()
+	if err := loaded.LoadRules(buffer); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := loaded.LookupName("user-details"); err != nil {
+		t.Logf("expected names to be kept by rule files: %v", err)
+		t.FailNow()
+	}
+	// Unregistered routes release their names
+	if err := loaded.Unregister(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/details")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := loaded.LookupName("user-details"); !errors.Is(err, NAME_NOT_REGISTERED) {
+		t.Logf("expected NAME_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+}
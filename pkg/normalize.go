@@ -0,0 +1,82 @@
+package gtr
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Rewrites a URL to the canonical form of the route it matches: the
+// host is lower-cased, repeated slashes are collapsed, literal path
+// segments are written the way the template writes them, and the
+// query params ignored by the cache keys of the route are dropped.
+// The remaining query params are ordered by key.
+func (rt *RouteTable) Canonical(url *url.URL) (*url.URL, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	route, prt, err := rt.match("", url)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseRoute(prt)
+	canonical := *url
+	canonical.Host = strings.ToLower(url.Host)
+	if len(url.Opaque) == 0 {
+		segments := make([]string, len(prt.segments))
+		for position, segment := range route.segments {
+			segments[position] = prt.segments[position].value
+			if !segment.param() {
+				segments[position] = segment.value
+			}
+		}
+		canonical.Path = "/" + strings.Join(segments, "/")
+		if len(segments) > 0 && strings.HasSuffix(url.Path, "/") {
+			canonical.Path += "/"
+		}
+		canonical.RawPath = ""
+	}
+	query := url.Query()
+	for key := range query {
+		if rt.isQueryIgnored(route, key) {
+			query.Del(key)
+		}
+	}
+	canonical.RawQuery = query.Encode()
+	canonical.ForceQuery = false
+	return &canonical, nil
+}
+
+// Creates a middleware rewriting requests to the canonical form of the
+// route they match (see Canonical) before they reach the next handler,
+// so that caches and upstreams only see canonical URLs. Requests that
+// match no route are passed on as they are.
+func NewNormalizeHandler(rt *RouteTable, next http.Handler) http.Handler {
+	return &normalizeHandler{rt: rt, next: next}
+}
+
+type normalizeHandler struct {
+	rt   *RouteTable
+	next http.Handler
+}
+
+func (handler *normalizeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	canonical, err := handler.rt.Canonical(RequestURL(r))
+	if err != nil {
+		handler.next.ServeHTTP(w, r)
+		return
+	}
+	normalized := new(http.Request)
+	*normalized = *r
+	normalized.URL = new(url.URL)
+	*normalized.URL = *r.URL
+	normalized.URL.Path = canonical.Path
+	normalized.URL.RawPath = canonical.RawPath
+	normalized.URL.RawQuery = canonical.RawQuery
+	normalized.URL.ForceQuery = false
+	if len(r.URL.Host) > 0 {
+		normalized.URL.Host = canonical.Host
+	}
+	normalized.Host = strings.ToLower(r.Host)
+	normalized.RequestURI = normalized.URL.RequestURI()
+	handler.next.ServeHTTP(w, normalized)
+}
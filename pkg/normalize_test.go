@@ -0,0 +1,45 @@
+package gtr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	rt := NewRouteTable(WithCaseInsensitivePaths(), WithIgnoredQuery("utm_*"))
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/API/Users/:id?type=cache"), nil)
+	canonical, err := rt.Canonical(PrepareURLFrom(t, "http://WWW.abcdefg.com//api//USERS/Ken/?utm_source=x&type=cache&b=2"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if expected := "http://www.abcdefg.com/api/users/Ken/?b=2&type=cache"; canonical.String() != expected {
+		t.Logf("expected %s but found %s", expected, canonical)
+		t.FailNow()
+	}
+	if _, err := rt.Canonical(PrepareURLFrom(t, "http://www.abcdefg.com/unknown")); err == nil {
+		t.Log("expected URLs matching no route to fail")
+		t.FailNow()
+	}
+}
+
+func TestNormalizeHandler(t *testing.T) {
+	rt := NewRouteTable(WithCaseInsensitivePaths(), WithIgnoredQuery("utm_*"))
+	rt.Register(PrepareURLFrom(t, "/api/users/:id"), nil)
+	seen := ""
+	handler := NewNormalizeHandler(rt, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.RequestURI
+	}))
+	tests := map[string]string{
+		"/API//Users/Ken?utm_source=x&page=2": "/api/users/Ken?page=2",
+		"/unknown//path?utm_source=x":         "/unknown//path?utm_source=x",
+	}
+	for target, expected := range tests {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+		if seen != expected {
+			t.Logf("expected %s to be rewritten to %s but found %s", target, expected, seen)
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// The prefix of the OpenAPI extensions carried over into route
+	// configs, for example `x-cache-ttl` becomes `ttl`
+	_openAPIExtension = "x-cache-"
+)
+
+type openAPIDocument struct {
+	Servers []openAPIServer            `json:"servers"`
+	Paths   map[string]json.RawMessage `json:"paths"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIOperation struct {
+	OperationID string             `json:"operationId"`
+	Parameters  []openAPIParameter `json:"parameters"`
+}
+
+type openAPIParameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description"`
+	Example     any    `json:"example"`
+	Schema      struct {
+		Type   string `json:"type"`
+		Format string `json:"format"`
+		Enum   []any  `json:"enum"`
+	} `json:"schema"`
+}
+
+// Registers a route for every operation of an OpenAPI 3 document (in
+// JSON). Path templates such as `/users/{username}/details` are
+// prefixed with the URL of the first server, path parameters declared
+// as integers, numbers, booleans, or UUIDs are typed, extension
+// parameters such as `{name}.{ext}` accept the values their schema
+// enumerates, and operation IDs become route names. The `x-cache-*`
+// extensions of path items and operations are carried over into the
+// route configs with the prefix dropped and dashes replaced by
+// underscores, for example `x-cache-ttl` becomes `ttl`. Extensions of
+// operations take precedence over those of their path item.
+// Every operation is registered for its method only (see
+// RegisterMethod), so the imported routes are found through FindMethod,
+// ExplainMethod, and CacheKeyMethod, as well as by the caching handler
+// of the cache package, but not through Find or Match.
+func (rt *RouteTable) ImportOpenAPI(doc []byte) error {
+	document := openAPIDocument{}
+	if err := json.Unmarshal(doc, &document); err != nil {
+		return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	server := ""
+	if len(document.Servers) > 0 {
+		server = strings.TrimSuffix(document.Servers[0].URL, "/")
+	}
+	paths := make([]string, 0, len(document.Paths))
+	for path := range document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	for _, path := range paths {
+		if err := rt.importPathItem(server, path, document.Paths[path]); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Registers the operations of an OpenAPI path item
+// Must be called with the write lock held
+func (rt *RouteTable) importPathItem(server string, path string, raw json.RawMessage) error {
+	item := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	shared := openAPIOperation{}
+	if parameters, ok := item["parameters"]; ok {
+		if err := json.Unmarshal(parameters, &shared.Parameters); err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+	}
+	base, err := openAPIExtensions(raw)
+	if err != nil {
+		return err
+	}
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions, http.MethodTrace} {
+		raw, ok := item[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		operation := openAPIOperation{}
+		if err := json.Unmarshal(raw, &operation); err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		extensions, err := openAPIExtensions(raw)
+		if err != nil {
+			return err
+		}
+		conf := copyMap(base)
+		for key, value := range extensions {
+			conf[key] = value
+		}
+		parameters := append(append([]openAPIParameter(nil), shared.Parameters...), operation.Parameters...)
+		template, err := ParseTemplate(server + openAPIPath(path, parameters))
+		if err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		opts := []RouteOption{WithParamDocs(openAPIDocs(parameters))}
+		if len(operation.OperationID) > 0 {
+			opts = append(opts, WithName(operation.OperationID))
+		}
+		if _, err := rt.register(method, template, conf, opts...); err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// Gets the config carried by the `x-cache-*` extensions of an OpenAPI
+// object
+func openAPIExtensions(raw json.RawMessage) (map[string]any, error) {
+	fields := make(map[string]any)
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	conf := make(map[string]any)
+	for key, value := range fields {
+		if strings.HasPrefix(key, _openAPIExtension) {
+			conf[strings.ReplaceAll(strings.TrimPrefix(key, _openAPIExtension), "-", "_")] = value
+		}
+	}
+	return conf, nil
+}
+
+// Types the path parameters of an OpenAPI path template after their
+// schema, for example `{id}` becomes `{id<int>}` for integers, and
+// restricts extension parameters to the values they enumerate, for
+// example `{name}.{ext(pdf|csv)}`
+func openAPIPath(path string, parameters []openAPIParameter) string {
+	for _, parameter := range parameters {
+		if parameter.In != "path" {
+			continue
+		}
+		if extension := "}.{" + parameter.Name + "}"; strings.HasSuffix(path, extension) && len(parameter.Schema.Enum) > 0 {
+			values := make([]string, len(parameter.Schema.Enum))
+			for index, value := range parameter.Schema.Enum {
+				values[index] = fmt.Sprint(value)
+			}
+			path = strings.TrimSuffix(path, extension) + "}.{" + parameter.Name + "(" + strings.Join(values, "|") + ")}"
+			continue
+		}
+		paramType := ParamType("")
+		switch {
+		case parameter.Schema.Type == "integer":
+			paramType = PARAM_INT
+		case parameter.Schema.Type == "number":
+			paramType = PARAM_FLOAT
+		case parameter.Schema.Type == "boolean":
+			paramType = PARAM_BOOL
+		case parameter.Schema.Type == "string" && parameter.Schema.Format == "uuid":
+			paramType = PARAM_UUID
+		}
+		if len(paramType) > 0 {
+			path = strings.ReplaceAll(path, "{"+parameter.Name+"}", "{"+parameter.Name+"<"+string(paramType)+">}")
+		}
+	}
+	return path
+}
+
+// Gets the documentation of the path parameters of an operation
+func openAPIDocs(parameters []openAPIParameter) map[string]ParamDoc {
+	docs := make(map[string]ParamDoc)
+	for _, parameter := range parameters {
+		if parameter.In != "path" || len(parameter.Description) == 0 && parameter.Example == nil {
+			continue
+		}
+		doc := ParamDoc{Description: parameter.Description}
+		if parameter.Example != nil {
+			doc.Example = fmt.Sprint(parameter.Example)
+		}
+		docs[parameter.Name] = doc
+	}
+	return docs
+}
+
+// Generates an OpenAPI 3 document (in JSON) with a path item for
+// every path template of the table, so that API documentation can be
+// kept in sync with the routes. Routes registered for a method become
+// operations of that method and the others become GET operations.
+// Parameters are typed after their constraints, route names become
+// operation IDs, and route configs are written as `x-cache-*`
+// extensions, so that ImportOpenAPI can read the document back. The
+// hosts of the routes are listed as servers, and path items list their
+// own servers when the table has more than one. Virtual routes are
+// left out.
+func (rt *RouteTable) ExportOpenAPI() ([]byte, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	paths := make(map[string]map[string]any)
+	origins := make(map[string]map[string]bool)
+	servers := make(map[string]bool)
+	for _, route := range rt.sorted() {
+		if len(route.url.Opaque) > 0 {
+			continue
+		}
+		path := route.openAPIPath()
+		item, ok := paths[path]
+		if !ok {
+			item = make(map[string]any)
+			paths[path] = item
+			origins[path] = make(map[string]bool)
+		}
+		method := strings.ToLower(route.method)
+		if len(method) == 0 {
+			method = "get"
+		}
+		// Routes registered for a method take precedence over routes
+		// registered for any method
+		if _, exists := item[method]; exists && len(route.method) == 0 {
+			continue
+		}
+		item[method] = rt.openAPIOperation(route)
+		origin := route.openAPIOrigin()
+		origins[path][origin] = true
+		servers[origin] = true
+	}
+	document := map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": "Route table", "version": "1.0.0"},
+		"paths":   paths,
+	}
+	if len(servers) > 0 && !servers[""] {
+		document["servers"] = openAPIServers(servers)
+	}
+	if len(servers) > 1 {
+		for path, item := range paths {
+			if !origins[path][""] {
+				item["servers"] = openAPIServers(origins[path])
+			}
+		}
+	}
+	return json.Marshal(document)
+}
+
+// Gets the path of the template in OpenAPI style
+func (route *Route) openAPIPath() string {
+	segments := make([]string, len(route.segments))
+	for position, segment := range route.segments {
+		segments[position] = segment.value
+		if segment.param() {
+			segments[position] = "{" + segment.value + "}"
+		}
+		if len(segment.extension) > 0 {
+			segments[position] += ".{" + segment.extension + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// Gets the scheme and host of the template as an OpenAPI server URL,
+// where host parameters are server variables
+func (route *Route) openAPIOrigin() string {
+	if len(route.host) == 0 {
+		return ""
+	}
+	labels := make([]string, len(route.hostLabels))
+	for index, label := range route.hostLabels {
+		labels[index] = label
+		if name, ok := route.hostParams[index]; ok {
+			labels[index] = "{" + name + "}"
+		} else if label == "*" {
+			labels[index] = "{label" + strconv.Itoa(index) + "}"
+		}
+	}
+	host := strings.Join(labels, ".")
+	if len(route.hostPort) > 0 {
+		host += ":" + route.hostPort
+	}
+	scheme := route.url.Scheme
+	if len(scheme) == 0 {
+		scheme = "https"
+	}
+	return scheme + "://" + host
+}
+
+// Gets the OpenAPI server objects of origins, ordered by URL
+func openAPIServers(origins map[string]bool) []map[string]any {
+	urls := make([]string, 0, len(origins))
+	for origin := range origins {
+		if len(origin) > 0 {
+			urls = append(urls, origin)
+		}
+	}
+	sort.Strings(urls)
+	servers := make([]map[string]any, len(urls))
+	for index, url := range urls {
+		server := map[string]any{"url": url}
+		variables := make(map[string]any)
+		for _, variable := range strings.Split(url, "{")[1:] {
+			name, _, _ := strings.Cut(variable, "}")
+			variables[name] = map[string]any{"default": name}
+		}
+		if len(variables) > 0 {
+			server["variables"] = variables
+		}
+		servers[index] = server
+	}
+	return servers
+}
+
+// Gets the OpenAPI operation of a route
+// Must be called with the read lock held
+func (rt *RouteTable) openAPIOperation(route *Route) map[string]any {
+	operation := map[string]any{
+		"responses": map[string]any{"default": map[string]any{"description": "The response of " + route.template}},
+	}
+	if len(route.name) > 0 {
+		operation["operationId"] = route.name
+	}
+	parameters := make([]map[string]any, 0)
+	for _, segment := range route.segments {
+		if !segment.param() {
+			continue
+		}
+		extension, ok := segment.constraint.(extensionConstraint)
+		if !ok {
+			parameters = append(parameters, route.openAPIParameter(segment.value, "path", openAPISchema(segment.constraint)))
+			continue
+		}
+		parameters = append(parameters, route.openAPIParameter(segment.value, "path", openAPISchema(extension.base)))
+		schema := map[string]any{"type": "string"}
+		if len(extension.extensions) > 0 {
+			schema["enum"] = extension.extensions
+		}
+		parameters = append(parameters, route.openAPIParameter(segment.extension, "path", schema))
+	}
+	keys := make([]string, 0, len(route.queryParams))
+	for key := range route.queryParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		schema := openAPISchema(route.queryConstraints[key])
+		if paramType, ok := route.queryTypes[key]; ok {
+			schema = openAPISchema(typeConstraint{paramType: paramType})
+		}
+		if literal, ok := route.literalQuery(key); ok {
+			schema["enum"] = []string{literal}
+		}
+		parameters = append(parameters, route.openAPIParameter(key, "query", schema))
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	for key, value := range rt.configs[route.hash] {
+		operation[_openAPIExtension+strings.ReplaceAll(key, "_", "-")] = value
+	}
+	return operation
+}
+
+// Gets the OpenAPI parameter object of a path or query parameter
+func (route *Route) openAPIParameter(name string, in string, schema map[string]any) map[string]any {
+	parameter := map[string]any{
+		"name":     name,
+		"in":       in,
+		"required": in == "path" || route.queryMode != QUERY_IGNORE,
+		"schema":   schema,
+	}
+	if doc, ok := route.docs[name]; ok {
+		if len(doc.Description) > 0 {
+			parameter["description"] = doc.Description
+		}
+		if len(doc.Example) > 0 {
+			parameter["example"] = doc.Example
+		}
+	}
+	return parameter
+}
+
+// Gets the OpenAPI schema of the values accepted by a constraint
+func openAPISchema(constraint paramConstraint) map[string]any {
+	if constraint, ok := constraint.(typeConstraint); ok {
+		switch constraint.paramType {
+		case PARAM_INT:
+			return map[string]any{"type": "integer"}
+		case PARAM_FLOAT:
+			return map[string]any{"type": "number"}
+		case PARAM_BOOL:
+			return map[string]any{"type": "boolean"}
+		case PARAM_UUID:
+			return map[string]any{"type": "string", "format": "uuid"}
+		}
+	}
+	return map[string]any{"type": "string"}
+}
@@ -0,0 +1,176 @@
+package gtr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const _openAPIDocument = `{
+	"openapi": "3.0.3",
+	"servers": [{"url": "http://www.abcdefg.com/api/v1/"}],
+	"paths": {
+		"/users/{username}/details": {
+			"x-cache-ttl": 60,
+			"parameters": [{"name": "username", "in": "path", "required": true, "description": "The login of the user", "schema": {"type": "string"}}],
+			"get": {"operationId": "getUserDetails", "x-cache-stale-if-error": "1m"},
+			"delete": {"x-cache-bypass": true, "x-cache-ttl": 0}
+		},
+		"/posts/{id}": {
+			"summary": "Posts",
+			"get": {"operationId": "getPost", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}], "x-internal": true}
+		}
+	}
+}`
+
+func TestImportOpenAPI(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.ImportOpenAPI([]byte(_openAPIDocument)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	details := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/details")
+	hash, err := rt.FindMethod("GET", details)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(hash)
+	if route.Name() != "getUserDetails" || route.ParamDocs()["username"].Description != "The login of the user" {
+		t.Logf("unexpected route %s", route.Name())
+		t.FailNow()
+	}
+	if conf := rt.GetConfig(hash); conf["ttl"] != 60.0 || conf["stale_if_error"] != "1m" || len(conf) != 2 {
+		t.Logf("unexpected config %v", conf)
+		t.FailNow()
+	}
+	if _, err := rt.Find(details); !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected the operations to be found for their methods only but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.CacheKeyMethod("GET", details); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err = rt.FindMethod("DELETE", details)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if policy := rt.GetPolicy(hash, "DELETE"); !policy.Bypass() || policy["ttl"] != 0.0 {
+		t.Logf("unexpected policy %v", policy)
+		t.FailNow()
+	}
+	if _, err := rt.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/abc")); err == nil {
+		t.Log("expected integer parameters to be typed")
+		t.FailNow()
+	}
+	hash, err = rt.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/42"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if route, _ := rt.Lookup(hash); route.Name() != "getPost" || len(rt.GetConfig(hash)) != 0 {
+		t.Logf("unexpected route %s", route.Name())
+		t.FailNow()
+	}
+}
+
+func TestImportOpenAPIValidation(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.ImportOpenAPI([]byte(`openapi: 3.0.3`)); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	duplicate := `{"paths": {"/a": {"get": {"operationId": "same"}}, "/b": {"get": {"operationId": "same"}}}}`
+	if err := rt.ImportOpenAPI([]byte(duplicate)); !errors.Is(err, DUPLICATE_NAME) {
+		t.Logf("expected DUPLICATE_NAME but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestExportOpenAPI(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/details"), map[string]any{"ttl": 60.0, "stale_if_error": "1m"}, WithName("getUserDetails"), WithParamDocs(map[string]ParamDoc{"username": {Description: "The login of the user"}}))
+	rt.RegisterMethod("DELETE", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/details"), map[string]any{"bypass": true})
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id<int>?active=<bool>"), nil)
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/reports/:name.:ext(pdf|csv)"), nil)
+	data, err := rt.ExportOpenAPI()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	document := map[string]any{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if servers := document["servers"].([]any); len(servers) != 1 || servers[0].(map[string]any)["url"] != "http://www.abcdefg.com" {
+		t.Logf("unexpected servers %v", servers)
+		t.FailNow()
+	}
+	paths := document["paths"].(map[string]any)
+	if len(paths) != 3 || paths["/api/v1/reports/{name}.{ext}"] == nil {
+		t.Logf("unexpected paths %v", paths)
+		t.FailNow()
+	}
+	details := paths["/api/v1/users/{username}/details"].(map[string]any)
+	get, remove := details["get"].(map[string]any), details["delete"].(map[string]any)
+	if get["operationId"] != "getUserDetails" || get["x-cache-ttl"] != 60.0 || get["x-cache-stale-if-error"] != "1m" || remove["x-cache-bypass"] != true {
+		t.Logf("unexpected operations %v", details)
+		t.FailNow()
+	}
+	if parameter := get["parameters"].([]any)[0].(map[string]any); parameter["name"] != "username" || parameter["description"] != "The login of the user" {
+		t.Logf("unexpected parameter %v", parameter)
+		t.FailNow()
+	}
+	posts := paths["/api/v1/posts/{id}"].(map[string]any)["get"].(map[string]any)["parameters"].([]any)
+	id, active := posts[0].(map[string]any), posts[1].(map[string]any)
+	if id["schema"].(map[string]any)["type"] != "integer" || active["in"] != "query" || active["schema"].(map[string]any)["type"] != "boolean" {
+		t.Logf("unexpected parameters %v", posts)
+		t.FailNow()
+	}
+	// The routes without query params survive a round trip
+	imported := newRouteTable()
+	if err := imported.ImportOpenAPI(data); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, err := imported.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/details"))
+	if err != nil || imported.GetConfig(hash)["ttl"] != 60.0 {
+		t.Logf("expected the exported routes to be imported: %v", err)
+		t.FailNow()
+	}
+	if _, err := imported.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/reports/q3.pdf")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := imported.FindMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/reports/q3.xml")); err == nil {
+		t.Log("expected the extensions to be imported")
+		t.FailNow()
+	}
+}
+
+func TestExportOpenAPIServers(t *testing.T) {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/health"), nil)
+	tenant, _ := ParseTemplate("https://:tenant.shop.com/orders/:id")
+	rt.Register(tenant, nil)
+	data, err := rt.ExportOpenAPI()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	document := map[string]any{}
+	json.Unmarshal(data, &document)
+	if servers := document["servers"].([]any); len(servers) != 2 {
+		t.Logf("unexpected servers %v", servers)
+		t.FailNow()
+	}
+	orders := document["paths"].(map[string]any)["/orders/{id}"].(map[string]any)
+	server := orders["servers"].([]any)[0].(map[string]any)
+	if server["url"] != "https://{tenant}.shop.com" || server["variables"].(map[string]any)["tenant"] == nil {
+		t.Logf("unexpected server %v", server)
+		t.FailNow()
+	}
+}
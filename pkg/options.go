@@ -0,0 +1,122 @@
+package gtr
+
+import (
+	"net/url"
+	"strings"
+)
+
+// An Option configures a route table created through NewRouteTable
+type Option func(rt *RouteTable)
+
+// Creates a new route table owned by the caller. Unlike the default
+// route table, which is shared by the whole process, tables created
+// this way are isolated from each other, for example one per tenant
+// or per test.
+func NewRouteTable(opts ...Option) *RouteTable {
+	rt := newRouteTable()
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// Matches the literal path segments of templates regardless of case.
+// Parameter values are extracted with their original case.
+func WithCaseInsensitivePaths() Option {
+	return func(rt *RouteTable) {
+		rt.foldCase = true
+	}
+}
+
+// Rejects URLs carrying query params that the matched template does
+// not expect. Params covered by a wildcard query param or ignored by
+// the cache keys of the route are still accepted.
+func WithStrictQuery() Option {
+	return func(rt *RouteTable) {
+		rt.strictQuery = true
+	}
+}
+
+// Sets the hasher of the route table
+func WithHasher(hasher Hasher) Option {
+	return func(rt *RouteTable) {
+		rt.hasher = hasher
+	}
+}
+
+// Sets how templates ending with an empty query are matched
+func WithEmptyQueryMode(mode EmptyQueryMode) Option {
+	return func(rt *RouteTable) {
+		rt.emptyQuery = mode
+	}
+}
+
+// Sets how a route is picked among the routes matching a URL
+func WithMatchMode(mode MatchMode) Option {
+	return func(rt *RouteTable) {
+		rt.mode = mode
+	}
+}
+
+// Sets the comparator used to match URLs against route templates
+func WithComparator(comparator Comparator) Option {
+	return func(rt *RouteTable) {
+		rt.comparator = comparator
+	}
+}
+
+// Sets the schemes accepted by the route table
+func WithSchemePolicy(policy SchemePolicy) Option {
+	return func(rt *RouteTable) {
+		rt.scheme = policy
+	}
+}
+
+// Sets the query params ignored by cache keys of every route
+func WithIgnoredQuery(patterns ...string) Option {
+	return func(rt *RouteTable) {
+		rt.ignored = append([]string(nil), patterns...)
+	}
+}
+
+// Enables "did you mean" suggestions for URLs that do not match
+func WithSuggestions() Option {
+	return func(rt *RouteTable) {
+		rt.suggest = true
+	}
+}
+
+// Lower-cases the literal path segments of a template
+func foldTemplate(template *url.URL) *url.URL {
+	segments := strings.Split(template.Path, "/")
+	for index, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			segments[index] = strings.ToLower(segment)
+		}
+	}
+	folded := *template
+	folded.Path = strings.Join(segments, "/")
+	folded.RawPath = ""
+	return &folded
+}
+
+// Gets the path segment of a parsed URL at a position, lower-cased if
+// the route table matches paths regardless of case
+func (route *Route) segment(position int) string {
+	if route.foldCase {
+		return strings.ToLower(route.segments[position].value)
+	}
+	return route.segments[position].value
+}
+
+// Gets the first query param of a parsed URL that a route template
+// does not expect, if any
+func (rt *RouteTable) unexpected(template *Route, route *Route) (string, bool) {
+	for key := range route.queryParams {
+		if _, ok := template.queryParams[key]; ok || template.varies(key) || rt.isQueryIgnored(template, key) {
+			continue
+		}
+		return key, true
+	}
+	return "", false
+}
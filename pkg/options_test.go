@@ -0,0 +1,78 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRouteTable(t *testing.T) {
+	first := NewRouteTable()
+	second := NewRouteTable(WithMatchMode(MATCH_FIRST), WithSuggestions())
+	if first == second || first == DefaultRouteTable() {
+		t.Log("expected every route table to be isolated")
+		t.FailNow()
+	}
+	first.Register(PrepareURLTemplate(t), nil)
+	if len(second.Routes()) != 0 {
+		t.Log("expected routes to be registered to a single table")
+		t.FailNow()
+	}
+	if second.mode != MATCH_FIRST || !second.suggest {
+		t.Log("expected the options to be applied")
+		t.FailNow()
+	}
+}
+
+func TestWithCaseInsensitivePaths(t *testing.T) {
+	rt := NewRouteTable(WithCaseInsensitivePaths())
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/API/v1/Users/:userId")
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash, params, err := rt.FindWithParams(PrepareURLFrom(t, "http://www.abcdefg.com/api/V1/USERS/JohnDoe"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if params["userId"] != "JohnDoe" {
+		t.Logf("expected the parameter to keep its case but found %s", params["userId"])
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:userId"), nil); err != nil || len(rt.Routes()) != 1 {
+		t.Log("expected templates differing in case to be the same route")
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(hash)
+	if route.template != "http://www.abcdefg.com/api/v1/users/:userId" {
+		t.Logf("unexpected template %s", route.template)
+		t.FailNow()
+	}
+
+	sensitive := NewRouteTable()
+	sensitive.Register(template, nil)
+	if _, err := sensitive.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/JohnDoe")); !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestWithStrictQuery(t *testing.T) {
+	rt := NewRouteTable(WithStrictQuery(), WithIgnoredQuery("utm_*"))
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users?sort=<string>")
+	rt.Register(template, nil)
+	if _, err := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users?sort=name&utm_source=mail")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	url := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users?sort=name&page=2")
+	if _, err := rt.Find(url); !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+	explanation := rt.Explain(url)
+	if explanation.Selected != "" || len(explanation.Candidates) != 1 {
+		t.Log("expected the explanation to reject the URL")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,45 @@
+package gtr
+
+// The MatchMode determines which route wins when several routes match
+// a URL
+type MatchMode int
+
+const (
+	// The most specific route wins: routes are ranked by class and
+	// then by how many segments and query params they fix (the default)
+	MATCH_MOST_SPECIFIC MatchMode = iota
+	// The first registered route that matches wins, regardless of its
+	// class or rank, like the ordered rules of nginx or Express. Meant
+	// for rule files whose semantics depend on their order.
+	MATCH_FIRST
+)
+
+// Sets how the route table picks a route among the routes matching a URL
+func (rt *RouteTable) SetMatchMode(mode MatchMode) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.mode = mode
+}
+
+// Gets the first registered route matching the parsed URL, if any
+// Routes registered after the first match found so far are skipped
+// without being ranked.
+func (rt *RouteTable) first(method string, prt *Route, routes []*Route) *Route {
+	var first *Route
+	consider := func(route *Route) {
+		if first != nil && route.order > first.order || !route.accepts(method) {
+			return
+		}
+		if rt.rank(route, prt) > 0 {
+			first = route
+		}
+	}
+	if rt.comparator != nil {
+		for _, route := range routes {
+			consider(route)
+		}
+		return first
+	}
+	rt.tries[len(prt.segments)].visit(prt, consider)
+	return first
+}
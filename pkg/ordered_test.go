@@ -0,0 +1,45 @@
+package gtr
+
+import "testing"
+
+func TestMatchFirst(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetMatchMode(MATCH_FIRST)
+	generic := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id")
+	specific := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")
+	rt.Register(generic, nil)
+	rt.Register(specific, nil)
+	url := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")
+	if found, _ := rt.Find(url); found != CreateHash(generic) {
+		t.Log("expected the first registered route to win")
+		t.FailNow()
+	}
+	if explanation := rt.Explain(url); explanation.Selected != CreateHash(generic) {
+		t.Logf("expected the explanation to select the first route but found %s", explanation.Selected)
+		t.FailNow()
+	}
+
+	rt.SetMatchMode(MATCH_MOST_SPECIFIC)
+	if found, _ := rt.Find(url); found != CreateHash(specific) {
+		t.Log("expected the most specific route to win")
+		t.FailNow()
+	}
+}
+
+func TestMatchFirstComparator(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetMatchMode(MATCH_FIRST)
+	rt.SetComparator(DefaultComparator)
+	specific := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")
+	generic := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id")
+	rt.Register(specific, nil)
+	rt.Register(generic, nil)
+	if found, _ := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")); found != CreateHash(specific) {
+		t.Log("expected the first registered route to win")
+		t.FailNow()
+	}
+	if found, _ := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/1")); found != CreateHash(generic) {
+		t.Log("expected later routes to match when earlier ones do not")
+		t.FailNow()
+	}
+}
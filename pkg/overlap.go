@@ -0,0 +1,161 @@
+package gtr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The Precedence tells why a matching route was preferred over another
+// matching route. Rules are applied in the order they are listed.
+type Precedence string
+
+const (
+	// The route has a higher class
+	PRECEDENCE_CLASS Precedence = "class"
+	// The host of the route is more specific
+	PRECEDENCE_HOST Precedence = "host"
+	// The path of the route has more literal segments
+	PRECEDENCE_RANK Precedence = "rank"
+	// The route constrains more query params
+	PRECEDENCE_QUERY Precedence = "query"
+	// The route was registered for the method of the request
+	PRECEDENCE_METHOD Precedence = "method"
+	// The route was registered first
+	PRECEDENCE_ORDER Precedence = "order"
+)
+
+// Gets why a matching route is preferred over another matching route
+func precedence(route *Route, rank int, other *Route, otherRank int) Precedence {
+	switch {
+	case route.class != other.class:
+		return PRECEDENCE_CLASS
+	case route.hostRank != other.hostRank:
+		return PRECEDENCE_HOST
+	case rank != otherRank:
+		return PRECEDENCE_RANK
+	case route.queryWeight() != other.queryWeight():
+		return PRECEDENCE_QUERY
+	case route.specific(other):
+		return PRECEDENCE_METHOD
+	}
+	return PRECEDENCE_ORDER
+}
+
+// Gets the number of query params the route constrains. Routes
+// ignoring query params constrain none.
+func (route *Route) queryWeight() int {
+	if route.queryMode == QUERY_IGNORE {
+		return 0
+	}
+	return len(route.queryParams) + len(route.queryWildcards)
+}
+
+// Rejects a route that differs from a registered route only by query
+// constraints that a single URL can satisfy at once, for example
+// `?a=1` and `?b=2`, unless one of them constrains more query params
+// and so takes precedence. Otherwise only the registration order would
+// tell them apart.
+func (rt *RouteTable) checkOverlaps(route *Route) error {
+	if existing := rt.overlapping(route); existing != nil {
+		return fmt.Errorf("%w: %s and %s match the same URLs with as many query constraints", AMBIGUOUS_ROUTE, existing.describe(), route.template)
+	}
+	return nil
+}
+
+// Gets the registered route that a route overlaps with, if any (see
+// checkOverlaps)
+func (rt *RouteTable) overlapping(route *Route) *Route {
+	if route.queryWeight() == 0 {
+		return nil
+	}
+	for _, existing := range rt.routes[len(route.segments)] {
+		if existing.queryWeight() == route.queryWeight() && overlaps(existing, route) {
+			return existing
+		}
+	}
+	return nil
+}
+
+// Checks whether two routes only differ by query constraints that a
+// single URL can satisfy at once
+func overlaps(route *Route, other *Route) bool {
+	// Routes of different methods never compete, or the route of the
+	// method of the request takes precedence
+	if route.method != other.method || route.class != other.class {
+		return false
+	}
+	if !strings.EqualFold(route.host, other.host) || len(route.segments) != len(other.segments) {
+		return false
+	}
+	for position, segment := range route.segments {
+		counterpart := other.segments[position]
+		if segment.kind != counterpart.kind || !segment.param() && segment.value != counterpart.value {
+			return false
+		}
+		if (segment.constraint == nil) != (counterpart.constraint == nil) {
+			return false
+		}
+		if segment.constraint != nil && segment.constraint.String() != counterpart.constraint.String() {
+			return false
+		}
+	}
+	if route.noQuery && other.queryWeight() > 0 || other.noQuery && route.queryWeight() > 0 {
+		return false
+	}
+	// Routes with the same query constraints differ by parameter names
+	// only, which is not a query overlap
+	if route.queryMode == other.queryMode && reflect.DeepEqual(route.queryParams, other.queryParams) && (len(route.queryWildcards)+len(other.queryWildcards) == 0 || reflect.DeepEqual(route.queryWildcards, other.queryWildcards)) {
+		return false
+	}
+	for key := range route.queryParams {
+		if _, ok := other.queryParams[key]; ok && !satisfiable(route, other, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Checks whether a single value of a query param can satisfy both
+// routes. Wildcards, types, and placeholders are assumed to overlap
+// unless a literal value rules them out.
+func satisfiable(route *Route, other *Route, key string) bool {
+	if route.queryMode == QUERY_SUBSET || other.queryMode == QUERY_SUBSET {
+		return true
+	}
+	literal, ok := route.literalQuery(key)
+	otherLiteral, otherOk := other.literalQuery(key)
+	switch {
+	case ok && otherOk:
+		return literal == otherLiteral
+	case ok:
+		return other.acceptsQuery(key, literal)
+	case otherOk:
+		return route.acceptsQuery(key, otherLiteral)
+	}
+	return true
+}
+
+// Gets the value a query param must literally have, if any
+func (route *Route) literalQuery(key string) (string, bool) {
+	if _, ok := route.queryNames[key]; ok {
+		return "", false
+	}
+	if _, ok := route.queryTypes[key]; ok {
+		return "", false
+	}
+	value, ok := route.queryParams[key]
+	return value, ok
+}
+
+// Checks whether a typed query param or a query placeholder accepts a value
+func (route *Route) acceptsQuery(key string, value string) bool {
+	if _, ok := route.queryNames[key]; ok {
+		return route.checkPlaceholder(key, value) == CHECK_PARAM
+	}
+	if paramType, ok := route.queryTypes[key]; ok {
+		_, err := paramType.Coerce(value)
+		return err == nil
+	}
+	return true
+}
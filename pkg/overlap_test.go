@@ -0,0 +1,70 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOverlappingQuery(t *testing.T) {
+	tests := []struct {
+		first     string
+		second    string
+		ambiguous bool
+	}{
+		{"http://www.abcdefg.com/items?a=1", "http://www.abcdefg.com/items?b=2", true},
+		{"http://www.abcdefg.com/items?a=1", "http://www.abcdefg.com/items?a=2", false},
+		{"http://www.abcdefg.com/items?a=1", "http://www.abcdefg.com/items?a=1&b=2", false},
+		{"http://www.abcdefg.com/items?a=<int>", "http://www.abcdefg.com/items?a=x", false},
+		{"http://www.abcdefg.com/items?a=<int>", "http://www.abcdefg.com/items?a=1", true},
+		{"http://www.abcdefg.com/items?a=:a<int>", "http://www.abcdefg.com/items?b=1", true},
+		{"http://www.abcdefg.com/items/:id?a=1", "http://www.abcdefg.com/items/:id<int>?b=2", false},
+	}
+	for _, test := range tests {
+		rt := newRouteTable()
+		if err := rt.Register(PrepareURLFrom(t, test.first), nil); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		err := rt.Register(PrepareURLFrom(t, test.second), nil)
+		if errors.Is(err, AMBIGUOUS_ROUTE) != test.ambiguous {
+			t.Logf("expected %s and %s to be ambiguous: %v but found %v", test.first, test.second, test.ambiguous, err)
+			t.FailNow()
+		}
+	}
+	rt := newRouteTable()
+	rt.RegisterMethod("GET", PrepareURLFrom(t, "http://www.abcdefg.com/items?a=1"), nil)
+	if err := rt.RegisterMethod("POST", PrepareURLFrom(t, "http://www.abcdefg.com/items?b=2"), nil); err != nil {
+		t.Logf("expected routes of different methods not to overlap but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestQueryPrecedence(t *testing.T) {
+	for _, reversed := range []bool{false, true} {
+		rt := newRouteTable()
+		templates := []string{"http://www.abcdefg.com/items?a=1", "http://www.abcdefg.com/items?a=1&b=2"}
+		if reversed {
+			templates[0], templates[1] = templates[1], templates[0]
+		}
+		for _, template := range templates {
+			rt.Register(PrepareURLFrom(t, template), nil)
+		}
+		url := PrepareURLFrom(t, "http://www.abcdefg.com/items?b=2&a=1")
+		hash, err := rt.Find(url)
+		if err != nil || hash != CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/items?a=1&b=2")) {
+			t.Logf("expected the route constraining more query params to win but found %s %v", hash, err)
+			t.FailNow()
+		}
+		explanation := rt.Explain(url)
+		if explanation.Selected != hash {
+			t.Logf("expected the explanation to select %s but found %s", hash, explanation.Selected)
+			t.FailNow()
+		}
+		for _, candidate := range explanation.Candidates {
+			if !candidate.Selected && candidate.Precedence != PRECEDENCE_QUERY {
+				t.Logf("expected the query precedence to be explained but found %s", candidate.Precedence)
+				t.FailNow()
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package gtr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The selector struct selects routes by the host and path segments of
+// their templates
+type selector struct {
+	host     string
+	segments []string
+}
+
+// The configOverride struct is a config patch applied to every route
+// whose template matches a selector
+type configOverride struct {
+	*selector
+	patch map[string]any
+}
+
+// Applies a JSON Merge Patch (see MergePatch) to the configs of every
+// route whose template matches a selector, and to the configs of the
+// routes registered from now on that match it, for example to lower
+// the TTL of a whole API at once. Selectors are templates whose path
+// segments are glob patterns, where `*` matches any single segment and
+// `**` matches any number of segments, for example `/api/v1/users/**`
+// or `/api/*/users/:*`. Parameters are matched as written in the
+// template. Selectors without a host select routes of every host.
+func (rt *RouteTable) OverrideConfig(selector string, patch map[string]any) error {
+	parsed, err := parseSelector(selector)
+	if err != nil {
+		return err
+	}
+	override := &configOverride{selector: parsed, patch: patch}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	for _, route := range rt.sorted() {
+		if !override.selects(route) {
+			continue
+		}
+		if err := rt.setConfig(route.hash, MergePatch(rt.configs[route.hash], patch)); err != nil {
+			return err
+		}
+		if err := rt.record(EVENT_UPDATE, route, rt.configs[route.hash]); err != nil {
+			return err
+		}
+	}
+	rt.overrides = append(rt.overrides, override)
+	return nil
+}
+
+// Parses a selector into the host and path segments it matches
+func parseSelector(raw string) (*selector, error) {
+	template, err := ParseTemplate(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	selector := selector{
+		host:     strings.ToLower(template.Host),
+		segments: make([]string, 0),
+	}
+	for _, segment := range strings.Split(normalizePath(routePath(template)), "/") {
+		if len(segment) > 0 {
+			selector.segments = append(selector.segments, segment)
+		}
+	}
+	return &selector, nil
+}
+
+// Checks whether the template of a route matches the selector
+func (selector *selector) selects(route *Route) bool {
+	if len(selector.host) > 0 && !globMatch(selector.host, strings.ToLower(route.host)) {
+		return false
+	}
+	return matchSegments(selector.segments, route.Segments())
+}
+
+// Matches path segments against glob patterns, where `**` matches any
+// number of segments
+func matchSegments(patterns []string, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for skipped := 0; skipped <= len(segments); skipped++ {
+			if matchSegments(patterns[1:], segments[skipped:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 || !globMatch(patterns[0], segments[0]) {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
+
+// Applies the overrides selecting a route to its config
+func (rt *RouteTable) override(route *Route, conf map[string]any) map[string]any {
+	for _, override := range rt.overrides {
+		if override.selects(route) {
+			conf = MergePatch(conf, override.patch)
+		}
+	}
+	return conf
+}
@@ -0,0 +1,78 @@
+package gtr
+
+import "testing"
+
+func TestOverrideConfig(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users":                   {"ttl": 60},
+		"http://www.abcdefg.com/api/v1/users/:username/details": {"ttl": 60, "format": "json"},
+		"http://www.abcdefg.com/api/v1/posts/:id":               {"ttl": 60},
+	})
+	if err := rt.OverrideConfig("/api/v1/users/**", map[string]any{"ttl": 5}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	tests := map[string]any{
+		"http://www.abcdefg.com/api/v1/users":                 5,
+		"http://www.abcdefg.com/api/v1/users/ken/details":     5,
+		"http://www.abcdefg.com/api/v1/posts/1":               60,
+		"http://www.abcdefg.com/api/v1/users/ken/posts/1/raw": 5,
+	}
+	// Routes registered later are patched too
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:username/posts/:id/raw"), nil)
+	for raw, expected := range tests {
+		match, err := rt.Match(PrepareURLFrom(t, raw))
+		if err != nil || match.Config["ttl"] != expected {
+			t.Logf("expected a ttl of %v for %s but found %v %v", expected, raw, match, err)
+			t.FailNow()
+		}
+	}
+	match, _ := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/details"))
+	if match.Config["format"] != "json" {
+		t.Log("expected the override to be merged into the config")
+		t.FailNow()
+	}
+}
+
+func TestOverrideSelectors(t *testing.T) {
+	tests := []struct {
+		selector string
+		template string
+		selected bool
+	}{
+		{"/api/*/users/:*", "http://www.abcdefg.com/api/v1/users/:id", true},
+		{"/api/*/users/:*", "http://www.abcdefg.com/api/v1/users/me", false},
+		{"/api/**/details", "http://www.abcdefg.com/api/v1/users/:id/details", true},
+		{"/api/**", "http://www.abcdefg.com/health", false},
+		{"http://*.abcdefg.com/**", "http://www.abcdefg.com/health", true},
+		{"http://api.abcdefg.com/**", "http://www.abcdefg.com/health", false},
+	}
+	for _, test := range tests {
+		override, err := parseSelector(test.selector)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if override.selects(ParseRoute(PrepareURLFrom(t, test.template))) != test.selected {
+			t.Logf("expected %s to select %s: %v", test.selector, test.template, test.selected)
+			t.FailNow()
+		}
+	}
+}
+
+func TestOverrideTypedConfig(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.OverrideConfig("/**", map[string]any{"ttl": 1}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	template := PrepareURLTemplate(t)
+	if err := RegisterTyped(rt, template, cacheConfig{TTL: 10, Format: "json"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if conf, _ := GetTyped[cacheConfig](rt, CreateHash(template)); conf.TTL != 1 || conf.Format != "json" {
+		t.Logf("expected the typed config to be overridden but found %v", conf)
+		t.FailNow()
+	}
+}
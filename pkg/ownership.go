@@ -0,0 +1,69 @@
+package gtr
+
+import "strings"
+
+// The Ownership struct tells who is responsible for a route, so that
+// it is clear who to reach when its rules misbehave
+// Params:
+//   - Owner: The person owning the route
+//   - Team: The team owning the route
+//   - Contact: Where to reach the owners, for example an email
+//     address, a chat channel, or a pager rotation
+type Ownership struct {
+	Owner   string `json:"owner,omitempty"`
+	Team    string `json:"team,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+// Sets who owns a route
+func WithOwnership(ownership Ownership) RouteOption {
+	return func(route *Route) error {
+		route.ownership = ownership
+		return nil
+	}
+}
+
+// Gets who owns the route
+func (route *Route) Ownership() Ownership {
+	return route.ownership
+}
+
+// Gets who owns the route, or nil if nobody does
+func (route *Route) owners() *Ownership {
+	if route.ownership.IsZero() {
+		return nil
+	}
+	ownership := route.ownership
+	return &ownership
+}
+
+// Checks whether no owner, team, or contact is set
+func (ownership Ownership) IsZero() bool {
+	return ownership == Ownership{}
+}
+
+// Formats the ownership as `owner (team, contact)`, leaving out the
+// parts that are not set
+func (ownership Ownership) String() string {
+	details := make([]string, 0, 2)
+	for _, detail := range []string{ownership.Team, ownership.Contact} {
+		if len(detail) > 0 {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		return ownership.Owner
+	}
+	if len(ownership.Owner) == 0 {
+		return strings.Join(details, ", ")
+	}
+	return ownership.Owner + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Describes a route in errors, naming its owners if any
+func (route *Route) describe() string {
+	if route.ownership.IsZero() {
+		return route.template
+	}
+	return route.template + " owned by " + route.ownership.String()
+}
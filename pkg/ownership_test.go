@@ -0,0 +1,112 @@
+package gtr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOwnership(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	ownership := Ownership{Owner: "jane", Team: "payments", Contact: "#payments-oncall"}
+	if err := rt.Register(template, map[string]any{"ttl": 10.0}, WithOwnership(ownership)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(CreateHash(template))
+	if route.Ownership() != ownership {
+		t.Logf("unexpected ownership %v", route.Ownership())
+		t.FailNow()
+	}
+	if ownership.String() != "jane (payments, #payments-oncall)" {
+		t.Logf("unexpected string %s", ownership.String())
+		t.FailNow()
+	}
+	if (Ownership{Team: "payments"}).String() != "payments" {
+		t.Log("expected the unset parts to be left out")
+		t.FailNow()
+	}
+
+	info, _ := rt.Info(route.hash)
+	if info.Ownership == nil || *info.Ownership != ownership {
+		t.Log("expected the ownership to be described")
+		t.FailNow()
+	}
+	recorder := httptest.NewRecorder()
+	NewAdminHandler(rt).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/routes/"+route.hash, nil))
+	if !strings.Contains(recorder.Body.String(), `"team":"payments"`) {
+		t.Logf("expected the admin API to expose the ownership but found %s", recorder.Body.String())
+		t.FailNow()
+	}
+}
+
+func TestOwnershipExport(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	ownership := Ownership{Team: "payments"}
+	rt.Register(template, map[string]any{"ttl": 10.0}, WithOwnership(ownership))
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil)
+	buffer := bytes.Buffer{}
+	if err := rt.ExportRules(&buffer); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	file := RuleFile{}
+	if err := json.Unmarshal(buffer.Bytes(), &file); err != nil || len(file.Routes) != 2 {
+		t.Logf("unexpected export %s", buffer.String())
+		t.FailNow()
+	}
+	imported := newRouteTable()
+	if err := imported.LoadRules(&buffer); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	route, err := imported.Lookup(CreateHash(template))
+	if err != nil || route.Ownership() != ownership || imported.GetConfig(route.hash)["ttl"] != 10.0 {
+		t.Log("expected the exported rules to restore the routes")
+		t.FailNow()
+	}
+}
+
+func TestOwnershipConflicts(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id"), nil, WithOwnership(Ownership{Team: "identity"}))
+	other := newRouteTable()
+	other.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:userId"), nil, WithOwnership(Ownership{Team: "growth"}))
+	report, err := rt.Merge(other)
+	if err != nil || len(report.Conflicts) != 1 {
+		t.Log("expected a conflict")
+		t.FailNow()
+	}
+	conflict := report.Conflicts[0]
+	if conflict.Existing.Ownership.Team != "identity" || conflict.Incoming.Ownership.Team != "growth" {
+		t.Log("expected the conflict to name the owners of both routes")
+		t.FailNow()
+	}
+
+	changes := CompatibleWith(rt, newRouteTable())
+	if len(changes) != 1 || changes[0].Ownership == nil || changes[0].Ownership.Team != "identity" {
+		t.Log("expected the breaking change to name the owners of the route")
+		t.FailNow()
+	}
+}
+
+func TestOwnershipCollision(t *testing.T) {
+	rt := newRouteTable()
+	for index := 0; index < 20; index++ {
+		url := PrepareURLFrom(t, fmt.Sprintf("http://www.abcdefg.com/api/v1/route%d", index))
+		rt.Register(url, nil, WithOwnership(Ownership{Team: "platform"}))
+	}
+	// A single hex character cannot tell 20 routes apart
+	_, err := rt.Rehash(Hasher{Version: HASH_V3, Length: 1})
+	if !errors.Is(err, HASH_COLLISION) || !strings.Contains(err.Error(), "owned by platform") {
+		t.Logf("expected a collision naming the owners but found %v", err)
+		t.FailNow()
+	}
+}
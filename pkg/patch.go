@@ -0,0 +1,49 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Applies a JSON Merge Patch (RFC 7396) to the config of a route.
+// Keys set to null are removed, objects are merged recursively, and
+// every other value replaces the existing one. The config is replaced
+// rather than mutated, so configs handed out earlier stay unchanged.
+func (rt *RouteTable) PatchConfig(hash string, patch []byte) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return err
+	}
+	document := make(map[string]any)
+	if err := json.Unmarshal(patch, &document); err != nil {
+		return fmt.Errorf("%w: %s", INVALID_PATCH, err.Error())
+	}
+	if err := rt.setConfig(hash, MergePatch(rt.configs[hash], document)); err != nil {
+		return err
+	}
+	return rt.record(EVENT_UPDATE, route, rt.configs[hash])
+}
+
+// Applies a JSON Merge Patch (RFC 7396) to a config and returns the
+// patched copy
+func MergePatch(target map[string]any, patch map[string]any) map[string]any {
+	result := copyMap(target)
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			existing, _ := result[key].(map[string]any)
+			result[key] = MergePatch(existing, nested)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package gtr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	target := map[string]any{
+		"ttl":    10.0,
+		"format": "json",
+		"limits": map[string]any{"rate": 1.0, "burst": 2.0},
+	}
+	patch := map[string]any{
+		"ttl":    20.0,
+		"format": nil,
+		"limits": map[string]any{"burst": nil, "window": "1m"},
+		"tags":   []any{"a"},
+	}
+	expected := map[string]any{
+		"ttl":    20.0,
+		"limits": map[string]any{"rate": 1.0, "window": "1m"},
+		"tags":   []any{"a"},
+	}
+	result := MergePatch(target, patch)
+	if !reflect.DeepEqual(result, expected) {
+		t.Logf("unexpected result %v", result)
+		t.FailNow()
+	}
+	if target["format"] != "json" {
+		t.Log("merge patch mutated its target")
+		t.FailNow()
+	}
+}
+
+func TestPatchConfig(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10, "format": "json"},
+	})
+	hash, _ := rt.Find(PrepareURL(t))
+	if err := rt.PatchConfig(hash, []byte(`{"ttl": 30}`)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["ttl"] != 30.0 || rt.GetConfig(hash)["format"] != "json" {
+		t.Logf("unexpected config %v", rt.GetConfig(hash))
+		t.FailNow()
+	}
+	if err := rt.PatchConfig(hash, []byte(`[1]`)); !errors.Is(err, INVALID_PATCH) {
+		t.Log("expected invalid patch error")
+		t.FailNow()
+	}
+	if err := rt.PatchConfig("unknown", []byte(`{}`)); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Log("expected unknown hash error")
+		t.FailNow()
+	}
+}
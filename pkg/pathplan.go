@@ -0,0 +1,98 @@
+package gtr
+
+import "bytes"
+
+// A bitmap holds one bit per path segment
+type bitmap []uint64
+
+// Sets the bit of a position, growing the bitmap as needed
+func (bits bitmap) set(position int) bitmap {
+	for len(bits) <= position/64 {
+		bits = append(bits, 0)
+	}
+	bits[position/64] |= 1 << (position % 64)
+	return bits
+}
+
+// Checks whether the bit of a position is set
+func (bits bitmap) has(position int) bool {
+	return position/64 < len(bits) && bits[position/64]&(1<<(position%64)) != 0
+}
+
+// The pathPlan of a route lists its path segments in order, so that
+// parsed URLs are compared against templates in a single pass rather
+// than through one map lookup per segment. Templates and parsed URLs
+// share the same plan: the segments are concatenated into the chain,
+// parameters as `?`, and ends holds the end of every segment within it.
+type pathPlan struct {
+	ends  []int
+	chain []byte
+	// The positions of the literal segments and of the constrained
+	// parameters
+	literals    bitmap
+	constrained bitmap
+}
+
+// Appends the next segment to the plan
+func (plan *pathPlan) add(segment string, literal bool, constrained bool) {
+	position := len(plan.ends)
+	plan.chain = append(plan.chain, segment...)
+	plan.ends = append(plan.ends, len(plan.chain))
+	if literal {
+		plan.literals = plan.literals.set(position)
+	}
+	if constrained {
+		plan.constrained = plan.constrained.set(position)
+	}
+}
+
+// Gets the segment at a position
+func (plan *pathPlan) at(position int) []byte {
+	start := 0
+	if position > 0 {
+		start = plan.ends[position-1]
+	}
+	return plan.chain[start:plan.ends[position]]
+}
+
+// Empties the plan, keeping its memory for the next pooled route
+func (plan *pathPlan) reset() {
+	plan.ends = plan.ends[:0]
+	plan.chain = plan.chain[:0]
+	for i := range plan.literals {
+		plan.literals[i] = 0
+	}
+	for i := range plan.constrained {
+		plan.constrained[i] = 0
+	}
+}
+
+func (plan pathPlan) clone() pathPlan {
+	return pathPlan{
+		ends:        append([]int(nil), plan.ends...),
+		chain:       append([]byte(nil), plan.chain...),
+		literals:    append(bitmap(nil), plan.literals...),
+		constrained: append(bitmap(nil), plan.constrained...),
+	}
+}
+
+// Compares the path segments of a parsed URL against the template and
+// gets the rank of the path, bailing out at the first mismatch
+func (route *Route) comparePath(prt *Route) int {
+	plan, other := &route.plan, &prt.plan
+	for position := range plan.ends {
+		switch {
+		case plan.literals.has(position):
+			literal, segment := plan.at(position), other.at(position)
+			if prt.foldCase && !bytes.EqualFold(literal, segment) || !prt.foldCase && !bytes.Equal(literal, segment) {
+				return 0
+			}
+		case plan.constrained.has(position):
+			if !route.segments[position].constraint.accepts(string(other.at(position))) {
+				return 0
+			}
+		}
+	}
+	// Literal segments rank 2 and parameters 1
+	return len(plan.ends) + route.literals
+}
@@ -0,0 +1,67 @@
+package gtr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestComparePath(t *testing.T) {
+	tests := []struct {
+		template string
+		url      string
+		rank     int
+	}{
+		{"http://www.abcdefg.com/api/v1/users/:id", "http://www.abcdefg.com/api/v1/users/1", 7},
+		{"http://www.abcdefg.com/api/v1/users/:id", "http://www.abcdefg.com/api/v2/users/1", 0},
+		{"http://www.abcdefg.com/api/v1/users/:id<int>", "http://www.abcdefg.com/api/v1/users/1", 7},
+		{"http://www.abcdefg.com/api/v1/users/:id<int>", "http://www.abcdefg.com/api/v1/users/ken", 0},
+		{"http://www.abcdefg.com/api/v1/users/:id", "http://www.abcdefg.com/api/v1/Users/1", 0},
+	}
+	for _, test := range tests {
+		template := ParseRoute(PrepareURLFrom(t, test.template))
+		route := ParseRoute(PrepareURLFrom(t, test.url))
+		rank := template.comparePath(route)
+		if rank != test.rank {
+			t.Logf("expected %s to rank %d against %s but found %d", test.url, test.rank, test.template, rank)
+			t.FailNow()
+		}
+		// The fast path and the segment by segment comparison agree
+		candidate := Candidate{}
+		if explained := compare(template, route, &candidate); explained != RouteCompare(template, route) {
+			t.Logf("expected %s to rank %d but found %d", test.url, explained, RouteCompare(template, route))
+			t.FailNow()
+		}
+	}
+}
+
+func TestComparePathLong(t *testing.T) {
+	segments := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		segments = append(segments, fmt.Sprintf("s%d", i))
+	}
+	segments[80] = ":param"
+	template := ParseRoute(PrepareURLFrom(t, "http://www.abcdefg.com/"+strings.Join(segments, "/")))
+	segments[80] = "value"
+	route := ParseRoute(PrepareURLFrom(t, "http://www.abcdefg.com/"+strings.Join(segments, "/")))
+	if rank := template.comparePath(route); rank != 199 {
+		t.Logf("expected a rank of 199 but found %d", rank)
+		t.FailNow()
+	}
+	segments[99] = "other"
+	route = ParseRoute(PrepareURLFrom(t, "http://www.abcdefg.com/"+strings.Join(segments, "/")))
+	if rank := template.comparePath(route); rank != 0 {
+		t.Logf("expected no match but found %d", rank)
+		t.FailNow()
+	}
+}
+
+func TestComparePathFoldCase(t *testing.T) {
+	rt := NewRouteTable(WithCaseInsensitivePaths())
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/API/Users/:id"), nil)
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/USERS/Ken"))
+	if err != nil || match.Params["id"] != "Ken" {
+		t.Logf("unexpected match %v %v", match, err)
+		t.FailNow()
+	}
+}
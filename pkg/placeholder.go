@@ -0,0 +1,73 @@
+package gtr
+
+import (
+	"sort"
+	"strings"
+)
+
+// Gets the name of a host label or query value written as a parameter,
+// either `:name` or `{name}`, including its constraint if any
+func placeholder(value string) (string, bool) {
+	if len(value) > 1 && strings.HasPrefix(value, ":") {
+		return value[1:], true
+	}
+	if len(value) > 2 && strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+		return value[1 : len(value)-1], true
+	}
+	return "", false
+}
+
+// Turns the query params of a template whose values are placeholders,
+// for example `?type=:type`, into named parameters. The query param
+// must still be present, but its value is captured instead of being
+// compared.
+func (route *Route) splitPlaceholders() {
+	for key, value := range route.queryParams {
+		name, ok := placeholder(value)
+		if !ok {
+			continue
+		}
+		// Invalid constraints are rejected by validateParams
+		name, constraint, _ := parseParam(":" + name)
+		if route.queryNames == nil {
+			route.queryNames = make(map[string]string)
+		}
+		route.queryNames[key] = name
+		if constraint != nil {
+			if route.queryConstraints == nil {
+				route.queryConstraints = make(map[string]paramConstraint)
+			}
+			route.queryConstraints[key] = constraint
+		}
+	}
+}
+
+// Checks a query value against the placeholder of a query param
+func (route *Route) checkPlaceholder(key string, value string) CheckResult {
+	if constraint, ok := route.queryConstraints[key]; ok && !constraint.accepts(value) {
+		return CHECK_INVALID
+	}
+	return CHECK_PARAM
+}
+
+// Adds the values captured by the query placeholders of the template
+// to the values of a match
+func (route *Route) queryValues(prt *Route, values map[string]string) {
+	for key, name := range route.queryNames {
+		values[name] = prt.queryParams[key]
+	}
+}
+
+// Gets the names of the query placeholders ordered by query param
+func (route *Route) queryParamNames() []string {
+	keys := make([]string, 0, len(route.queryNames))
+	for key := range route.queryNames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		names = append(names, route.queryNames[key])
+	}
+	return names
+}
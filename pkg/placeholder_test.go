@@ -0,0 +1,74 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQueryPlaceholders(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id?type=:type&page={page<int>}")
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/1?type=cache&page=2"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Params["id"] != "1" || match.Params["type"] != "cache" || match.Params["page"] != "2" || match.Typed["page"] != int64(2) {
+		t.Logf("unexpected match %v %v", match.Params, match.Typed)
+		t.FailNow()
+	}
+	tests := []string{
+		"http://www.abcdefg.com/api/v1/users/1?page=2",
+		"http://www.abcdefg.com/api/v1/users/1?type=cache&page=two",
+	}
+	for _, raw := range tests {
+		if _, err := rt.Find(PrepareURLFrom(t, raw)); !errors.Is(err, NO_MATCH_FOUND) {
+			t.Logf("expected NO_MATCH_FOUND for %s but found %v", raw, err)
+			t.FailNow()
+		}
+	}
+	route, _ := rt.Lookup(match.Hash)
+	if params := route.Params(); len(params) != 3 || params[1] != "page" || params[2] != "type" {
+		t.Logf("unexpected params %v", params)
+		t.FailNow()
+	}
+	for _, example := range rt.Examples(match.Hash, 2) {
+		if _, err := rt.Find(PrepareURLFrom(t, example)); err != nil {
+			t.Logf("expected the example %s to match: %v", example, err)
+			t.FailNow()
+		}
+	}
+}
+
+func TestQueryPlaceholderCompat(t *testing.T) {
+	old := newRouteTable()
+	old.Register(PrepareURLFrom(t, "http://www.abcdefg.com/items?type=cache"), nil)
+	new := newRouteTable()
+	new.Register(PrepareURLFrom(t, "http://www.abcdefg.com/items?type=:type"), nil)
+	for _, change := range CompatibleWith(old, new) {
+		if change.Kind == CHANGE_NARROWED {
+			t.Logf("expected a placeholder to accept the previous value but found %v", change)
+			t.FailNow()
+		}
+	}
+	narrowed := false
+	for _, change := range CompatibleWith(new, old) {
+		narrowed = narrowed || change.Kind == CHANGE_NARROWED
+	}
+	if !narrowed {
+		t.Log("expected a literal to narrow a placeholder")
+		t.FailNow()
+	}
+}
+
+func TestQueryPlaceholderInvalid(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/items?page=:page<integer>"), nil); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,224 @@
+package gtr
+
+import (
+	"strings"
+	"time"
+)
+
+// The Policy type is the config of a route once every layer (method
+// overlays, ...) has been resolved. Well-known keys can be read
+// through typed accessors.
+type Policy map[string]any
+
+const (
+	// The time to live of cached responses, either a time.Duration,
+	// a duration string (`5s`), or a number of seconds
+	POLICY_TTL = "ttl"
+	// Disables caching when true
+	POLICY_BYPASS = "bypass"
+	// The share of matches (0 to 1) reported to match hooks
+	POLICY_METRICS_SAMPLE_RATE = "metrics_sample_rate"
+	// The share of matches (0 to 1) flagged for tracing
+	POLICY_TRACE_SAMPLE_RATE = "trace_sample_rate"
+	// The highest upstream error rate (0 to 1) tolerated before stale
+	// responses are served instead of upstream errors
+	POLICY_ERROR_BUDGET = "error_budget"
+	// The window the upstream error rate is measured over (1m by default)
+	POLICY_ERROR_WINDOW = "error_window"
+	// The number of requests in the window below which the error
+	// budget is never considered exhausted (10 by default)
+	POLICY_ERROR_MIN_REQUESTS = "error_min_requests"
+	// How long past its TTL a cached response may be served when the
+	// upstream fails
+	POLICY_STALE_IF_ERROR = "stale_if_error"
+	// How long past its TTL a cached response may be served while it is
+	// refreshed in the background
+	POLICY_STALE_WHILE_REVALIDATE = "stale_while_revalidate"
+	// The version of the cache keys of a route, bumped to invalidate
+	// every cached response of the route at once (see BumpKeyVersion)
+	POLICY_KEY_VERSION = "key_version"
+)
+
+const (
+	// The method overlay applied to methods without their own overlay
+	ANY_METHOD = "*"
+)
+
+// Adds a method specific overlay to the config of a route
+// Params:
+//   - method: The HTTP method, or ANY_METHOD for every method without
+//     an overlay of its own
+//   - overlay: Config values overriding the route config
+func WithMethodOverlay(method string, overlay map[string]any) RouteOption {
+	return func(route *Route) error {
+		route.overlays[strings.ToUpper(method)] = copyMap(overlay)
+		return nil
+	}
+}
+
+// Adds a method specific overlay to the config of a registered route
+func (rt *RouteTable) SetMethodOverlay(hash string, method string, overlay map[string]any) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	if err := rt.modify(hash, WithMethodOverlay(method, overlay)); err != nil {
+		return err
+	}
+	return rt.recordUpdate(hash)
+}
+
+// Resolves the policy of a route for a method by layering the method
+// overlay (or the ANY_METHOD overlay) on top of the route config. The
+// policy reports bypass while the global bypass is on.
+func (rt *RouteTable) GetPolicy(hash string, method string) Policy {
+	return rt.policy(hash, method, "", "")
+}
+
+// Resolves the policy of a match for a method like GetPolicy, with
+// the overlays of the file extension of the match (see
+// WithExtensionOverlay) and then of its variant (see WithVariants), if
+// any, layered between the route config and the method overlay
+func (rt *RouteTable) MatchPolicy(match *MatchResult, method string) Policy {
+	return rt.policy(match.Hash, method, match.Extension, match.Variant)
+}
+
+func (rt *RouteTable) policy(hash string, method string, extension string, variant string) Policy {
+	policy := Policy(copyMap(rt.GetConfig(hash)))
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	if route, err := rt.lookup(hash); err == nil {
+		for key, value := range route.extensionOverlays[extension] {
+			policy[key] = value
+		}
+		if route.variants != nil {
+			for key, value := range route.variants.Overlays[variant] {
+				policy[key] = value
+			}
+		}
+		overlay, ok := route.overlays[strings.ToUpper(method)]
+		if !ok {
+			overlay = route.overlays[ANY_METHOD]
+		}
+		for key, value := range overlay {
+			policy[key] = value
+		}
+	}
+	if rt.bypass.On {
+		policy[POLICY_BYPASS] = true
+	}
+	return policy
+}
+
+// Gets the time to live of the policy
+func (policy Policy) TTL() (time.Duration, bool) {
+	return durationValue(policy[POLICY_TTL])
+}
+
+// Checks whether caching is bypassed
+func (policy Policy) Bypass() bool {
+	bypass, _ := policy[POLICY_BYPASS].(bool)
+	return bypass
+}
+
+// Gets the share of matches reported to match hooks (1 by default)
+func (policy Policy) MetricsSampleRate() float64 {
+	return rateValue(policy[POLICY_METRICS_SAMPLE_RATE])
+}
+
+// Gets the share of matches flagged for tracing (1 by default)
+func (policy Policy) TraceSampleRate() float64 {
+	return rateValue(policy[POLICY_TRACE_SAMPLE_RATE])
+}
+
+// Gets the highest tolerated upstream error rate, if any
+func (policy Policy) ErrorBudget() (float64, bool) {
+	if _, ok := policy[POLICY_ERROR_BUDGET]; !ok {
+		return 0, false
+	}
+	return rateValue(policy[POLICY_ERROR_BUDGET]), true
+}
+
+// Gets the window the upstream error rate is measured over
+func (policy Policy) ErrorWindow() time.Duration {
+	if window, ok := durationValue(policy[POLICY_ERROR_WINDOW]); ok && window > 0 {
+		return window
+	}
+	return time.Minute
+}
+
+// Gets the number of requests below which the error budget is never
+// considered exhausted
+func (policy Policy) ErrorMinRequests() int {
+	if value, ok := intValue(policy[POLICY_ERROR_MIN_REQUESTS]); ok {
+		return value
+	}
+	return 10
+}
+
+// Gets how long past its TTL a cached response may be served when
+// the upstream fails
+func (policy Policy) StaleIfError() (time.Duration, bool) {
+	return durationValue(policy[POLICY_STALE_IF_ERROR])
+}
+
+// Gets how long past its TTL a cached response may be served while it
+// is refreshed in the background
+func (policy Policy) StaleWhileRevalidate() (time.Duration, bool) {
+	return durationValue(policy[POLICY_STALE_WHILE_REVALIDATE])
+}
+
+// Gets the version of the cache keys (0 by default)
+func (policy Policy) KeyVersion() int {
+	version, _ := intValue(policy[POLICY_KEY_VERSION])
+	return version
+}
+
+func intValue(value any) (int, bool) {
+	switch value := value.(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+func rateValue(value any) float64 {
+	rate := 1.0
+	switch value := value.(type) {
+	case float64:
+		rate = value
+	case float32:
+		rate = float64(value)
+	case int:
+		rate = float64(value)
+	}
+	if rate < 0 {
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+func durationValue(value any) (time.Duration, bool) {
+	switch value := value.(type) {
+	case time.Duration:
+		return value, true
+	case string:
+		duration, err := time.ParseDuration(value)
+		return duration, err == nil
+	case int:
+		return time.Duration(value) * time.Second, true
+	case int64:
+		return time.Duration(value) * time.Second, true
+	case float64:
+		return time.Duration(value * float64(time.Second)), true
+	}
+	return 0, false
+}
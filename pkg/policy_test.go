@@ -0,0 +1,72 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGetPolicy(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	err := rt.Register(template, map[string]any{"ttl": "10s", "format": "json"},
+		WithMethodOverlay("HEAD", map[string]any{"ttl": 5}),
+		WithMethodOverlay(ANY_METHOD, map[string]any{"bypass": true}),
+	)
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	if err := rt.SetMethodOverlay(hash, "get", map[string]any{}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	get := rt.GetPolicy(hash, "GET")
+	if ttl, ok := get.TTL(); !ok || ttl != 10*time.Second || get.Bypass() || get["format"] != "json" {
+		t.Logf("unexpected GET policy %v", get)
+		t.FailNow()
+	}
+	head := rt.GetPolicy(hash, "HEAD")
+	if ttl, _ := head.TTL(); ttl != 5*time.Second || head.Bypass() {
+		t.Logf("unexpected HEAD policy %v", head)
+		t.FailNow()
+	}
+	post := rt.GetPolicy(hash, "POST")
+	if !post.Bypass() {
+		t.Logf("unexpected POST policy %v", post)
+		t.FailNow()
+	}
+	if _, ok := rt.GetConfig(hash)["bypass"]; ok {
+		t.Log("overlay leaked into the route config")
+		t.FailNow()
+	}
+}
+
+func TestSetMethodOverlayReplacesRoute(t *testing.T) {
+	rt := newRouteTable()
+	template, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username")
+	if err := rt.Register(template, map[string]any{"ttl": "10s"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	published, _ := rt.Lookup(hash)
+	if err := rt.SetMethodOverlay(hash, "POST", map[string]any{"bypass": true}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(published.overlays) != 0 {
+		t.Log("expected the published route to be left untouched")
+		t.FailNow()
+	}
+	if route, _ := rt.Lookup(hash); route == published || !rt.GetPolicy(hash, "POST").Bypass() {
+		t.Log("expected the route to be replaced by a copy with the overlay")
+		t.FailNow()
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken")
+	if match, err := rt.Match(url); err != nil || match.Hash != hash {
+		t.Logf("expected the replaced route to match but found %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,44 @@
+package gtr
+
+import (
+	"net/url"
+	"sync"
+)
+
+// Pool of the transient routes parsed from URLs while matching
+var _routePool = sync.Pool{
+	New: func() any {
+		return &Route{
+			queryParams: make(map[string]string),
+			queryTypes:  make(map[string]ParamType),
+		}
+	},
+}
+
+// Parses a URL being matched into a pooled route. Unlike ParseRoute
+// no hash or template is computed, as matching needs neither. The
+// route must be released through releaseRoute once it is no longer used.
+func acquireRoute(url *url.URL) *Route {
+	route := _routePool.Get().(*Route)
+	route.url = normalizeTemplate(url)
+	route.host = route.url.Host
+	route.parse(route.url)
+	return route
+}
+
+// Returns a route acquired through acquireRoute to the pool
+func releaseRoute(route *Route) {
+	route.segments = route.segments[:0]
+	for key := range route.queryParams {
+		delete(route.queryParams, key)
+	}
+	for key := range route.queryTypes {
+		delete(route.queryTypes, key)
+	}
+	route.url = nil
+	route.host = ""
+	route.literals = 0
+	route.plan.reset()
+	route.foldCase = false
+	_routePool.Put(route)
+}
@@ -0,0 +1,41 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestAcquireRoute(t *testing.T) {
+	url := PrepareURL(t)
+	for i := 0; i < 3; i++ {
+		expected := ParseRoute(url)
+		prt := acquireRoute(url)
+		if !reflect.DeepEqual(prt.segments, expected.segments) || !reflect.DeepEqual(prt.queryParams, expected.queryParams) || prt.literals != expected.literals {
+			t.Log("pooled route differs from the parsed route")
+			t.FailNow()
+		}
+		releaseRoute(prt)
+		if len(prt.segments) != 0 || len(prt.queryParams) != 0 || prt.url != nil {
+			t.Log("released route was not reset")
+			t.FailNow()
+		}
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	rt := newRouteTable()
+	for i := 0; i < 100; i++ {
+		template, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/api/v%d/users/:username/details?type=cache", i))
+		rt.Register(template, nil)
+	}
+	url, _ := url.Parse("http://www.abcdefg.com/api/v50/users/ken/details?type=cache")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rt.Find(url); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gtr
+
+// The ProbeTarget struct describes a concrete URL an uptime checker
+// can probe to monitor a route
+// Params:
+//   - URL: A URL matching the route, built from the parameter examples
+//   - Method: The method to probe with, GET unless the route is
+//     registered for another method
+//   - Policy: The policy the route applies to requests of the method
+//   - TTL: The time to live of cached responses, in seconds, if any
+//   - Bypass: Whether caching is bypassed
+type ProbeTarget struct {
+	Hash      string     `json:"hash"`
+	Template  string     `json:"template"`
+	Method    string     `json:"method"`
+	URL       string     `json:"url"`
+	Policy    Policy     `json:"policy"`
+	TTL       *float64   `json:"ttl,omitempty"`
+	Bypass    bool       `json:"bypass"`
+	Ownership *Ownership `json:"ownership,omitempty"`
+}
+
+// Generates one probe target per route, ordered by template, so that
+// synthetic monitoring can be configured from the route table
+func (rt *RouteTable) ProbeTargets() []ProbeTarget {
+	rt.mutex.RLock()
+	routes := rt.sorted()
+	rt.mutex.RUnlock()
+	targets := make([]ProbeTarget, 0, len(routes))
+	for _, route := range routes {
+		method := route.method
+		if len(method) == 0 {
+			method = "GET"
+		}
+		// The policy may come from a slow config provider, so it is
+		// resolved without holding the lock
+		policy := rt.GetPolicy(route.hash, method)
+		target := ProbeTarget{
+			Hash:      route.hash,
+			Template:  route.template,
+			Method:    method,
+			URL:       route.example(0),
+			Policy:    policy,
+			Bypass:    policy.Bypass(),
+			Ownership: route.owners(),
+		}
+		if ttl, ok := policy.TTL(); ok {
+			seconds := ttl.Seconds()
+			target.TTL = &seconds
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
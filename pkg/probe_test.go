@@ -0,0 +1,30 @@
+package gtr
+
+import "testing"
+
+func TestProbeTargets(t *testing.T) {
+	rt := NewRouteTable(WithHasher(Hasher{Version: HASH_V4}))
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id<int>?active=<bool>"), map[string]any{"ttl": "5m"}, WithOwnership(Ownership{Team: "identity"}))
+	tenant, _ := ParseTemplate("http://:tenant.shop.com/orders/:orderId")
+	rt.RegisterMethod("POST", tenant, map[string]any{"bypass": true})
+	targets := rt.ProbeTargets()
+	if len(targets) != 2 {
+		t.Logf("expected 2 targets but found %d", len(targets))
+		t.FailNow()
+	}
+	for _, target := range targets {
+		url := PrepareURLFrom(t, target.URL)
+		if hash, err := rt.FindMethod(target.Method, url); err != nil || hash != target.Hash {
+			t.Logf("expected %s to match its route: %v", target.URL, err)
+			t.FailNow()
+		}
+	}
+	if targets[0].Method != "POST" || !targets[0].Bypass || targets[0].TTL != nil {
+		t.Logf("unexpected target %v", targets[0])
+		t.FailNow()
+	}
+	if targets[1].Method != "GET" || targets[1].TTL == nil || *targets[1].TTL != 300 || targets[1].Ownership.Team != "identity" {
+		t.Logf("unexpected target %v", targets[1])
+		t.FailNow()
+	}
+}
@@ -0,0 +1,80 @@
+package gtr
+
+import (
+	"sync"
+	"time"
+)
+
+// The ConfigProvider interface supplies configs that are not stored
+// in the route table, for example configs kept in a database or a
+// control plane
+type ConfigProvider interface {
+	// Gets the config of a hash; ok is false if the provider has none
+	Config(hash string) (conf map[string]any, ok bool, err error)
+}
+
+// The ConfigProviderFunc type adapts a function to a ConfigProvider
+type ConfigProviderFunc func(hash string) (map[string]any, bool, error)
+
+// Gets the config of a hash
+func (fn ConfigProviderFunc) Config(hash string) (map[string]any, bool, error) {
+	return fn(hash)
+}
+
+type providerCache struct {
+	mut         sync.Mutex
+	provider    ConfigProvider
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	entries     map[string]providerEntry
+}
+
+type providerEntry struct {
+	conf    map[string]any
+	expires time.Time
+}
+
+// Sets the provider GetConfig falls back to for hashes without a
+// config. Configs found by the provider are cached for positiveTTL and
+// misses for negativeTTL; errors are never cached.
+func (rt *RouteTable) SetConfigProvider(provider ConfigProvider, positiveTTL time.Duration, negativeTTL time.Duration) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if provider == nil {
+		rt.provider = nil
+		return
+	}
+	rt.provider = &providerCache{
+		provider:    provider,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		entries:     make(map[string]providerEntry),
+	}
+}
+
+func (cache *providerCache) config(hash string) map[string]any {
+	now := time.Now()
+	cache.mut.Lock()
+	entry, ok := cache.entries[hash]
+	cache.mut.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.conf
+	}
+	conf, ok, err := cache.provider.Config(hash)
+	if err != nil {
+		return nil
+	}
+	ttl := cache.positiveTTL
+	if !ok {
+		conf = nil
+		ttl = cache.negativeTTL
+	}
+	cache.mut.Lock()
+	defer cache.mut.Unlock()
+	if ttl > 0 {
+		cache.entries[hash] = providerEntry{conf: conf, expires: now.Add(ttl)}
+	} else {
+		delete(cache.entries, hash)
+	}
+	return conf
+}
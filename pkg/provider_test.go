@@ -0,0 +1,53 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigProvider(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": nil,
+		"http://www.abcdefg.com/api/v1/posts/:id":                          {"ttl": 1},
+	})
+	hash, _ := rt.Find(PrepareURL(t))
+	calls := make(map[string]int)
+	fail := false
+	rt.SetConfigProvider(ConfigProviderFunc(func(key string) (map[string]any, bool, error) {
+		calls[key]++
+		if fail {
+			return nil, false, errors.New("unavailable")
+		}
+		if key == hash {
+			return map[string]any{"ttl": 10}, true, nil
+		}
+		return nil, false, nil
+	}), time.Minute, time.Minute)
+	for i := 0; i < 3; i++ {
+		if rt.GetConfig(hash)["ttl"] != 10 {
+			t.Log("config was not provided")
+			t.FailNow()
+		}
+		if rt.GetConfig("unknown") != nil {
+			t.Log("unknown hash should not have a config")
+			t.FailNow()
+		}
+	}
+	if calls[hash] != 1 || calls["unknown"] != 1 {
+		t.Logf("provider results were not cached %v", calls)
+		t.FailNow()
+	}
+	for _, route := range rt.Routes() {
+		if route.Hash() != hash && calls[route.Hash()] != 0 {
+			t.Log("provider was consulted for a registered config")
+			t.FailNow()
+		}
+	}
+	rt.SetConfigProvider(rt.provider.provider, 0, 0)
+	fail = true
+	if rt.GetConfig(hash) != nil {
+		t.Log("failing provider returned a config")
+		t.FailNow()
+	}
+}
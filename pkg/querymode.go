@@ -0,0 +1,76 @@
+package gtr
+
+import "fmt"
+
+// The QueryMode determines how the query params of a template are
+// matched against the query of a URL
+type QueryMode int
+
+const (
+	// Every query param of the template must be present with the
+	// value of the template (the default), which suits caching
+	QUERY_STRICT QueryMode = iota
+	// Every query param of the template must be present, whatever
+	// its value
+	QUERY_SUBSET
+	// The query of the URL is not matched at all
+	QUERY_IGNORE
+)
+
+// Gets the name of the mode
+func (mode QueryMode) String() string {
+	switch mode {
+	case QUERY_STRICT:
+		return "strict"
+	case QUERY_SUBSET:
+		return "subset"
+	case QUERY_IGNORE:
+		return "ignore"
+	}
+	return "unknown"
+}
+
+// Encodes the mode by its name
+func (mode QueryMode) MarshalText() ([]byte, error) {
+	return []byte(mode.String()), nil
+}
+
+// Decodes a mode from its name
+func (mode *QueryMode) UnmarshalText(text []byte) error {
+	for _, candidate := range []QueryMode{QUERY_STRICT, QUERY_SUBSET, QUERY_IGNORE} {
+		if candidate.String() == string(text) {
+			*mode = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown query mode %s", INVALID_VALUE, text)
+}
+
+// Sets how the query params of a route are matched
+func WithQueryMode(mode QueryMode) RouteOption {
+	return func(route *Route) error {
+		route.queryMode = mode
+		return nil
+	}
+}
+
+// Gets how the query params of the route are matched
+func (route *Route) QueryMode() QueryMode {
+	return route.queryMode
+}
+
+// Sets how the query params of routes registered from now on are
+// matched, unless they are registered with WithQueryMode
+func (rt *RouteTable) SetQueryMode(mode QueryMode) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.queryMode = mode
+}
+
+// Sets how the query params of routes are matched unless they are
+// registered with WithQueryMode
+func WithDefaultQueryMode(mode QueryMode) Option {
+	return func(rt *RouteTable) {
+		rt.queryMode = mode
+	}
+}
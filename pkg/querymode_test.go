@@ -0,0 +1,72 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestQueryModes(t *testing.T) {
+	rt := newRouteTable()
+	strict := PrepareURLFrom(t, "http://www.abcdefg.com/strict?type=cache&page=<int>")
+	subset := PrepareURLFrom(t, "http://www.abcdefg.com/subset?type=cache&page=<int>")
+	ignore := PrepareURLFrom(t, "http://www.abcdefg.com/ignore?type=cache&page=<int>")
+	rt.Register(strict, nil)
+	rt.Register(subset, nil, WithQueryMode(QUERY_SUBSET))
+	rt.Register(ignore, nil, WithQueryMode(QUERY_IGNORE))
+	tests := map[string]bool{
+		"http://www.abcdefg.com/strict?type=cache&page=1": true,
+		"http://www.abcdefg.com/strict?type=other&page=1": false,
+		"http://www.abcdefg.com/strict?page=1":            false,
+		"http://www.abcdefg.com/subset?type=other&page=x": true,
+		"http://www.abcdefg.com/subset?page=1":            false,
+		"http://www.abcdefg.com/ignore":                   true,
+		"http://www.abcdefg.com/ignore?type=other":        true,
+	}
+	for raw, expected := range tests {
+		match, err := rt.Match(PrepareURLFrom(t, raw))
+		if (err == nil) != expected {
+			t.Logf("unexpected result %v for %s", err, raw)
+			t.FailNow()
+		}
+		if err != nil && !errors.Is(err, NO_MATCH_FOUND) {
+			t.Logf("unexpected error %v for %s", err, raw)
+			t.FailNow()
+		}
+		if err == nil && raw == "http://www.abcdefg.com/subset?type=other&page=x" && len(match.Query) != 0 {
+			t.Logf("expected invalid typed values to be left out but found %v", match.Query)
+			t.FailNow()
+		}
+	}
+}
+
+func TestDefaultQueryMode(t *testing.T) {
+	rt := NewRouteTable(WithDefaultQueryMode(QUERY_SUBSET))
+	template := PrepareURLFrom(t, "http://www.abcdefg.com/items?type=cache")
+	rt.Register(template, nil)
+	route, _ := rt.Lookup(CreateHash(template))
+	if route.QueryMode() != QUERY_SUBSET {
+		t.Logf("expected the default query mode but found %s", route.QueryMode())
+		t.FailNow()
+	}
+
+	// Query modes survive exporting the rules
+	buffer := bytes.Buffer{}
+	rt.ExportRules(&buffer)
+	imported := newRouteTable()
+	if err := imported.LoadRules(&buffer); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := imported.Find(PrepareURLFrom(t, "http://www.abcdefg.com/items?type=other")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	stricter := newRouteTable()
+	stricter.Register(template, nil)
+	changes := CompatibleWith(rt, stricter)
+	if len(changes) != 1 || changes[0].Kind != CHANGE_NARROWED {
+		t.Logf("expected a stricter query mode to narrow the route but found %v", changes)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,51 @@
+package gtr
+
+// The Quota names a limit of SourceLimits
+type Quota string
+
+const (
+	// The number of routes a source owns
+	QUOTA_ROUTES Quota = "routes"
+	// The number of path segments of a template
+	QUOTA_SEGMENTS Quota = "segments"
+	// The number of query params of a template
+	QUOTA_QUERY_PARAMS Quota = "queryParams"
+	// The number of keys of a route config
+	QUOTA_CONFIG_KEYS Quota = "configKeys"
+)
+
+// The QuotaWarning struct describes a registration that reached the
+// soft limit of a quota
+// Params:
+//   - Used: The usage of the quota including the registration
+//   - Soft: The soft limit that was reached
+//   - Hard: The hard limit, or zero if the quota has none
+type QuotaWarning struct {
+	Source string `json:"source"`
+	Quota  Quota  `json:"quota"`
+	Used   int    `json:"used"`
+	Soft   int    `json:"soft"`
+	Hard   int    `json:"hard"`
+}
+
+// The QuotaHook interface is notified about registrations made through
+// RegisterFrom that reach a soft limit. Registrations rejected by a
+// hard limit are not reported, since they fail with QUOTA_EXCEEDED.
+type QuotaHook interface {
+	OnQuotaWarning(warning QuotaWarning)
+}
+
+// The QuotaHookFunc type adapts a function to the QuotaHook interface
+type QuotaHookFunc func(warning QuotaWarning)
+
+// Calls the function
+func (hook QuotaHookFunc) OnQuotaWarning(warning QuotaWarning) {
+	hook(warning)
+}
+
+// Adds a hook notified about registrations reaching a soft limit
+func (rt *RouteTable) AddQuotaHook(hook QuotaHook) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.hooks.quota = append(rt.hooks.quota, hook)
+}
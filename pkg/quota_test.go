@@ -0,0 +1,64 @@
+package gtr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestQuotaWarnings(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetSourceLimits(SourceLimits{
+		MaxRoutes:      3,
+		WarnRoutes:     2,
+		MaxConfigKeys:  4,
+		WarnConfigKeys: 3,
+	})
+	warnings := make([]QuotaWarning, 0)
+	rt.AddQuotaHook(QuotaHookFunc(func(warning QuotaWarning) {
+		// Hooks may use the table
+		rt.Routes()
+		warnings = append(warnings, warning)
+	}))
+	register := func(index int, conf map[string]any) error {
+		url, _ := url.Parse(fmt.Sprintf("http://tenant.com/r%d/:id", index))
+		return rt.RegisterFrom("tenant", url, conf)
+	}
+	if err := register(0, nil); err != nil || len(warnings) != 0 {
+		t.Log("expected no warning below the soft limits")
+		t.FailNow()
+	}
+	if err := register(1, map[string]any{"a": 1, "b": 2, "c": 3}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(warnings) != 2 {
+		t.Logf("expected 2 warnings but found %v", warnings)
+		t.FailNow()
+	}
+	expected := QuotaWarning{Source: "tenant", Quota: QUOTA_CONFIG_KEYS, Used: 3, Soft: 3, Hard: 4}
+	if warnings[0] != expected {
+		t.Logf("unexpected warning %v", warnings[0])
+		t.FailNow()
+	}
+	expected = QuotaWarning{Source: "tenant", Quota: QUOTA_ROUTES, Used: 2, Soft: 2, Hard: 3}
+	if warnings[1] != expected {
+		t.Logf("unexpected warning %v", warnings[1])
+		t.FailNow()
+	}
+
+	// Registering a registered URL again reaches no new limit
+	if err := register(1, nil); err != nil || len(warnings) != 2 {
+		t.Log("expected no warning for a registered URL")
+		t.FailNow()
+	}
+	if err := register(2, nil); err != nil || len(warnings) != 3 {
+		t.Log("expected a warning for every registration past the soft limit")
+		t.FailNow()
+	}
+	if err := register(3, nil); !errors.Is(err, QUOTA_EXCEEDED) || len(warnings) != 3 {
+		t.Log("expected registrations rejected by a hard limit not to be reported")
+		t.FailNow()
+	}
+}
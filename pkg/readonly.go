@@ -0,0 +1,34 @@
+package gtr
+
+import "net/url"
+
+// The ReadOnlyTable is a view of a route table that cannot be used
+// to mutate it. It is meant to be handed to request-path code that
+// must only resolve routes.
+type ReadOnlyTable struct {
+	rt *RouteTable
+}
+
+// Gets a read-only view of the route table
+func (rt *RouteTable) ReadOnly() ReadOnlyTable {
+	return ReadOnlyTable{rt: rt}
+}
+
+// Finds the route template for a given URL
+func (view ReadOnlyTable) Find(url *url.URL) (string, error) {
+	return view.rt.Find(url)
+}
+
+// Gets a copy of the configuration for a given hash
+func (view ReadOnlyTable) GetConfig(hash string) map[string]any {
+	conf := view.rt.GetConfig(hash)
+	if conf == nil {
+		return nil
+	}
+	return copyMap(conf)
+}
+
+// Gets all registered routes ordered by their templates
+func (view ReadOnlyTable) Routes() []*Route {
+	return view.rt.Routes()
+}
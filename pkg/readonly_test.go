@@ -0,0 +1,27 @@
+package gtr
+
+import (
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10},
+	})
+	view := rt.ReadOnly()
+	hash, err := view.Find(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	conf := view.GetConfig(hash)
+	conf["ttl"] = 20
+	if rt.GetConfig(hash)["ttl"] != 10 {
+		t.Log("read-only view leaked a mutable config")
+		t.FailNow()
+	}
+	if len(view.Routes()) != 1 {
+		t.Log("routes are not exposed")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,64 @@
+package gtr
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Creates a loggable form of a matched URL. The values of route
+// parameters are replaced by the parameter names, for example
+// `/users/:username/details`, and so are the values of query params
+// not fixed by the template, so access logs never record raw
+// identifiers. Credentials and fragments are dropped.
+func (rt *RouteTable) RedactURL(url *url.URL, match *MatchResult) (string, error) {
+	route, err := rt.Lookup(match.Hash)
+	if err != nil {
+		return "", err
+	}
+	redacted := *url
+	redacted.User = nil
+	redacted.Fragment = ""
+	redacted.RawFragment = ""
+	if len(url.Opaque) == 0 {
+		segments := strings.Split(url.Path, "/")
+		position := 0
+		for index, value := range segments {
+			if len(value) == 0 {
+				continue
+			}
+			if position < len(route.segments) && route.segments[position].param() {
+				segments[index] = route.segments[position].pattern()
+			}
+			position++
+		}
+		redacted.Path = strings.Join(segments, "/")
+		redacted.RawPath = ""
+	}
+	query := url.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		escaped := queryEscape(key)
+		_, literal := route.queryParams[key]
+		_, typed := route.queryTypes[key]
+		for _, value := range query[key] {
+			if !literal || typed {
+				pairs = append(pairs, escaped+"=:"+escaped)
+				continue
+			}
+			pairs = append(pairs, escaped+"="+queryEscape(value))
+		}
+	}
+	redacted.RawQuery = strings.Join(pairs, "&")
+	return redacted.String(), nil
+}
+
+// Escapes a query key or value where the url package is shadowed
+func queryEscape(value string) string {
+	return url.QueryEscape(value)
+}
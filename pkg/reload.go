@@ -0,0 +1,152 @@
+package gtr
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloads the route table from a rule file (see LoadFromFile). The
+// file is loaded into a new table with the settings of this one, and
+// its routes, configs, and fragments then replace those of this table
+// at once, so that lookups never see a partially loaded file. Lookups
+// in progress complete against the routes they started with. The
+// table is left untouched if the file fails to load.
+// The file owns the whole table: routes registered otherwise, for
+// example through Register, RegisterFrom, a Mux, or RegisterIf, are
+// dropped by a reload. Since the reload cannot be recorded route by
+// route, a snapshot event of the reloaded table is written to the
+// event log, so that Replay still rebuilds the table.
+func (rt *RouteTable) ReloadFile(path string) error {
+	rt.mutex.RLock()
+	if err := rt.checkFrozen(); err != nil {
+		rt.mutex.RUnlock()
+		return err
+	}
+	scratch := rt.empty()
+	rt.mutex.RUnlock()
+	if err := scratch.LoadFromFile(path); err != nil {
+		return err
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	rt.swap(scratch)
+	return rt.recordSnapshot()
+}
+
+// Watches a rule file and reloads the route table whenever the file
+// changes (see ReloadFile). The file is polled for changes to its
+// modification time or size rather than watched through file system
+// notifications (such as fsnotify), which would need a dependency and
+// platform-specific code. The returned function stops watching.
+// Params:
+//   - interval: How often the file is polled
+//   - callback: Called after every reload with its error, nil on
+//     success. May be nil.
+func (rt *RouteTable) WatchFile(path string, interval time.Duration, callback func(err error)) func() {
+	last, _ := os.Stat(path)
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err == nil && last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+					continue
+				}
+				if err == nil {
+					last = info
+					err = rt.ReloadFile(path)
+				}
+				if callback != nil {
+					callback(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// Creates an empty route table with the settings of this one
+// Must be called with the read lock held
+func (rt *RouteTable) empty() *RouteTable {
+	scratch := newRouteTable()
+	scratch.hasher = rt.hasher
+	scratch.limits = rt.limits
+	scratch.ignored = rt.ignored
+	scratch.suffix = rt.suffix
+	scratch.emptyQuery = rt.emptyQuery
+	scratch.scheme = rt.scheme
+	scratch.comparator = rt.comparator
+	scratch.suggest = rt.suggest
+	scratch.mode = rt.mode
+	scratch.foldCase = rt.foldCase
+	scratch.strictQuery = rt.strictQuery
+	scratch.queryMode = rt.queryMode
+	scratch.overrides = rt.overrides
+	return scratch
+}
+
+// Gets a table with the settings, routes, configs, and fragments of the
+// table, which may be changed without changing the table. Routes are
+// shared, since they are replaced rather than mutated.
+// Must be called with the read lock held
+func (rt *RouteTable) copy() *RouteTable {
+	scratch := rt.empty()
+	for segments, bucket := range rt.routes {
+		scratch.routes[segments] = append([]*Route(nil), bucket...)
+	}
+	scratch.index = copyMap(rt.index)
+	scratch.configs = copyMap(rt.configs)
+	scratch.fragments = copyMap(rt.fragments)
+	scratch.inserted = rt.inserted
+	scratch.reindex()
+	return scratch
+}
+
+// Replaces the routes, configs, and fragments of the table by those of
+// another table
+// Must be called with the write lock held
+func (rt *RouteTable) swap(other *RouteTable) {
+	for _, route := range rt.index {
+		if rt.cardinality != nil {
+			rt.cardinality.forget(route)
+		}
+		if rt.groups != nil {
+			rt.groups.forget(route)
+		}
+	}
+	rt.routes = other.routes
+	rt.tries = other.tries
+	rt.index = other.index
+	rt.configs = other.configs
+	rt.typed = other.typed
+	rt.fragments = other.fragments
+	rt.inserted = other.inserted
+	// Replicas cannot catch up with a swap event by event
+	if rt.replication != nil {
+		rt.replication.reset()
+	}
+	// Sources keep their rate limits but own the routes of the other
+	// table only
+	for _, state := range rt.sources {
+		state.routes = 0
+	}
+	for _, route := range rt.index {
+		if state, ok := rt.sources[route.source]; ok && route.source != "" {
+			state.routes++
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReloadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/posts/:id", "config": {"ttl": 5}}]}`), 0644)
+	rt := newRouteTable()
+	if err := rt.LoadFromFile(path); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	posts := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")
+	os.WriteFile(path, []byte(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/users/:username/details?type=cache", "config": {"ttl": 10}}]}`), 0644)
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				rt.Find(posts)
+			}
+		}
+	}()
+	err := rt.ReloadFile(path)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(posts); err == nil {
+		t.Log("expected the routes of the previous file to be dropped")
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if found, err := rt.Find(PrepareURL(t)); err != nil || found != hash || rt.GetConfig(hash)["ttl"] != 10.0 {
+		t.Logf("expected the routes of the file to be loaded: %v", err)
+		t.FailNow()
+	}
+	os.WriteFile(path, []byte(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/users/:id/:id"}]}`), 0644)
+	if err := rt.ReloadFile(path); !errors.Is(err, DUPLICATE_PARAMETER) {
+		t.Logf("expected DUPLICATE_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+	if found, err := rt.Find(PrepareURL(t)); err != nil || found != hash {
+		t.Log("expected a failed reload to leave the table untouched")
+		t.FailNow()
+	}
+}
+
+func TestReloadFileEventLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/posts/:id", "config": {"ttl": 5}}]}`), 0644)
+	rt := newRouteTable()
+	log := bytes.Buffer{}
+	rt.SetEventLog(&log)
+	if err := rt.Register(PrepareURLTemplate(t), nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.ReloadFile(path); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err == nil {
+		t.Log("expected the routes registered in code to be dropped")
+		t.FailNow()
+	}
+	replayed := newRouteTable()
+	if err := replayed.Replay(&log); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	routes := replayed.Routes()
+	if len(routes) != 1 || routes[0].Hash() != CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id")) {
+		t.Logf("expected the replayed log to rebuild the reloaded table but found %d routes", len(routes))
+		t.FailNow()
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/posts/:id"}]}`), 0644)
+	rt := newRouteTable()
+	if err := rt.LoadFromFile(path); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	reloads := make(chan error, 10)
+	stop := rt.WatchFile(path, 10*time.Millisecond, func(err error) {
+		reloads <- err
+	})
+	defer stop()
+	write := func(content string, modified time.Time) error {
+		os.WriteFile(path, []byte(content), 0644)
+		os.Chtimes(path, modified, modified)
+		select {
+		case err := <-reloads:
+			return err
+		case <-time.After(2 * time.Second):
+			t.Log("expected the file to be reloaded")
+			t.FailNow()
+		}
+		return nil
+	}
+	now := time.Now()
+	if err := write(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/users/:username/details?type=cache"}]}`, now.Add(time.Minute)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := write(`{`, now.Add(2*time.Minute)); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err != nil {
+		t.Log("expected a failed reload to leave the table untouched")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,71 @@
+package gtr
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// The TableModel struct is the view of a route table exposed to
+// templates rendered through Render
+// Params:
+//   - Routes: Every route, ordered by template
+//   - Fragments: The fragments defined through DefineFragment
+//   - Hasher: The hasher of the table
+//   - Frozen: Whether the table is frozen
+//   - Stats: The statistics of the table
+type TableModel struct {
+	Routes    []*RouteInfo
+	Fragments map[string]string
+	Hasher    Hasher
+	Frozen    bool
+	Stats     Stats
+}
+
+// Functions that help templates format the table model. Add them to a
+// template before parsing it, for example
+//
+//	template.New("report").Funcs(gtr.RenderFuncs).Parse(...)
+var RenderFuncs = template.FuncMap{
+	// Encodes a value as JSON
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	// Encodes values as a line of CSV, without the line break
+	"csv": func(values ...string) (string, error) {
+		buffer := strings.Builder{}
+		writer := csv.NewWriter(&buffer)
+		if err := writer.Write(values); err != nil {
+			return "", err
+		}
+		writer.Flush()
+		return strings.TrimSuffix(buffer.String(), "\n"), writer.Error()
+	},
+}
+
+// Gets the view of the route table exposed to templates
+func (rt *RouteTable) Model() *TableModel {
+	rt.mutex.RLock()
+	model := TableModel{
+		Routes:    make([]*RouteInfo, 0, len(rt.index)),
+		Fragments: copyMap(rt.fragments),
+		Hasher:    rt.hasher,
+		Frozen:    rt.frozen,
+	}
+	for _, route := range rt.sorted() {
+		model.Routes = append(model.Routes, rt.info(route))
+	}
+	rt.mutex.RUnlock()
+	model.Stats = rt.Stats()
+	return &model
+}
+
+// Renders the model of the route table through a template, so that
+// reports such as HTML dashboards or CSV inventories can be generated
+// in any format. Templates of html/template can render Model instead.
+func (rt *RouteTable) Render(w io.Writer, tmpl *template.Template) error {
+	return tmpl.Execute(w, rt.Model())
+}
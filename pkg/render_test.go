@@ -0,0 +1,45 @@
+package gtr
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+)
+
+func TestRender(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id"), map[string]any{"ttl": 10}, WithOwnership(Ownership{Team: "identity, auth"}))
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), nil)
+	tmpl := template.Must(template.New("inventory").Funcs(RenderFuncs).Parse(
+		"template,team,config\n" +
+			"{{range .Routes}}{{$team := \"\"}}{{with .Ownership}}{{$team = .Team}}{{end}}" +
+			"{{csv .Template $team (json .Config)}}\n{{end}}"))
+	buffer := bytes.Buffer{}
+	if err := rt.Render(&buffer, tmpl); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := "template,team,config\n" +
+		"http://www.abcdefg.com/api/v1/posts/:id,,null\n" +
+		"http://www.abcdefg.com/api/v1/users/:id,\"identity, auth\",\"{\"\"ttl\"\":10}\"\n"
+	if buffer.String() != expected {
+		t.Logf("unexpected output %q", buffer.String())
+		t.FailNow()
+	}
+}
+
+func TestModel(t *testing.T) {
+	rt := newRouteTable()
+	rt.DefineFragment("users", "/api/v1/users")
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/@users/:id"), nil)
+	rt.Freeze()
+	model := rt.Model()
+	if len(model.Routes) != 1 || model.Routes[0].Template != "http://www.abcdefg.com/api/v1/users/:id" {
+		t.Logf("unexpected routes %v", model.Routes)
+		t.FailNow()
+	}
+	if model.Fragments["users"] != "api/v1/users" || !model.Frozen || model.Stats.Routes != 1 {
+		t.Logf("unexpected model %v", model)
+		t.FailNow()
+	}
+}
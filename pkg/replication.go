@@ -0,0 +1,324 @@
+package gtr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The ReplicationMessage struct is a single message of a replication
+// stream. The first message of a stream carries a snapshot of the route
+// table (see Export) unless the stream resumes from a token, and the
+// messages that follow carry the mutations of the table in order (see
+// Event).
+// Params:
+//   - Token: Resumes the stream right after this message
+type ReplicationMessage struct {
+	Token    string          `json:"token"`
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+	Event    *Event          `json:"event,omitempty"`
+}
+
+type replicatedEvent struct {
+	sequence uint64
+	event    Event
+}
+
+// The replicationLog keeps the latest mutations of a route table so
+// that replicas may resume their streams
+type replicationLog struct {
+	size int
+	// Identifies the history that sequence numbers belong to, which
+	// changes whenever the table is swapped
+	epoch    string
+	sequence uint64
+	backlog  []replicatedEvent
+	// Closed and replaced whenever the log changes
+	notify chan struct{}
+}
+
+// Enables replication of the route table. Every mutation is numbered
+// and the latest mutations are kept in a backlog, so that replicas
+// resuming their streams (see Subscribe) catch up event by event
+// rather than through a new snapshot. A backlog of zero or less
+// disables replication.
+func (rt *RouteTable) EnableReplication(backlog int) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if rt.replication != nil {
+		close(rt.replication.notify)
+		rt.replication = nil
+	}
+	if backlog <= 0 {
+		return
+	}
+	rt.replication = &replicationLog{
+		size:   backlog,
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		notify: make(chan struct{}),
+	}
+}
+
+// Streams the route table to a replica until the context is done, the
+// replica fails to receive a message, or replication is disabled. The
+// stream starts with a snapshot of the table unless the token of the
+// last message the replica applied is still in the backlog, in which
+// case only the mutations that followed it are sent. Replicas apply
+// the messages through ApplyReplication. The stream does not depend on
+// a transport: NewReplicationHandler and Follow carry it over HTTP,
+// and a gRPC server-streaming method may carry it by passing the Send
+// method of its stream.
+// Params:
+//   - token: The token of the last message applied, empty for a new
+//     replica
+//   - send: Sends a message to the replica
+func (rt *RouteTable) Subscribe(ctx context.Context, token string, send func(message ReplicationMessage) error) error {
+	epoch, sequence := parseReplicationToken(token)
+	for {
+		rt.mutex.RLock()
+		log := rt.replication
+		if log == nil {
+			rt.mutex.RUnlock()
+			return NOT_REPLICATED
+		}
+		messages, err := log.since(rt, epoch, sequence)
+		notify := log.notify
+		epoch, sequence = log.epoch, log.sequence
+		rt.mutex.RUnlock()
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := send(message); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Applies a message of a replication stream to the route table.
+// Snapshots replace the routes, configs, and fragments of the table at
+// once (see ReloadFile), and events are applied the way Replay applies
+// them.
+func (rt *RouteTable) ApplyReplication(message ReplicationMessage) error {
+	if len(message.Snapshot) > 0 {
+		rt.mutex.RLock()
+		scratch := rt.empty()
+		rt.mutex.RUnlock()
+		if err := scratch.LoadRules(bytes.NewReader(message.Snapshot)); err != nil {
+			return err
+		}
+		rt.mutex.Lock()
+		defer rt.mutex.Unlock()
+		if err := rt.checkFrozen(); err != nil {
+			return err
+		}
+		rt.swap(scratch)
+		return rt.recordSnapshot()
+	}
+	if message.Event == nil {
+		return nil
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	return rt.apply(*message.Event)
+}
+
+// Creates an http.Handler streaming the route table to replicas as
+// newline-delimited JSON messages (see Subscribe). Replicas resume
+// their streams by passing the token of the last message they applied
+// as the `token` query param.
+func NewReplicationHandler(rt *RouteTable) http.Handler {
+	return &replicationHandler{rt: rt}
+}
+
+type replicationHandler struct {
+	rt *RouteTable
+}
+
+func (handler *replicationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	handler.rt.mutex.RLock()
+	replicated := handler.rt.replication != nil
+	handler.rt.mutex.RUnlock()
+	if !replicated {
+		writeError(w, http.StatusNotFound, NOT_REPLICATED)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	encoder := json.NewEncoder(w)
+	handler.rt.Subscribe(r.Context(), r.URL.Query().Get("token"), func(message ReplicationMessage) error {
+		if err := encoder.Encode(message); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+}
+
+// Keeps the route table a warm standby of the route table streamed by
+// a replication handler (see NewReplicationHandler). Broken streams are
+// resumed from the last message applied. The returned function stops
+// following.
+// Params:
+//   - endpoint: The URL of the replication handler
+//   - retry: How long to wait before resuming a broken stream
+//   - callback: Called with the error of every broken stream. May be
+//     nil.
+func (rt *RouteTable) Follow(endpoint string, retry time.Duration, callback func(err error)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		token := ""
+		for {
+			var err error
+			token, err = rt.follow(ctx, endpoint, token)
+			if ctx.Err() != nil {
+				return
+			}
+			if callback != nil {
+				callback(err)
+			}
+			select {
+			case <-time.After(retry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(cancel)
+	}
+}
+
+// Applies the messages of a replication stream until it breaks, and
+// gets the token of the last message applied
+func (rt *RouteTable) follow(ctx context.Context, endpoint string, token string) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return token, err
+	}
+	query := target.Query()
+	query.Set("token", token)
+	target.RawQuery = query.Encode()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return token, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return token, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return token, fmt.Errorf("%w: %s", NOT_REPLICATED, response.Status)
+	}
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for scanner.Scan() {
+		message := ReplicationMessage{}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return token, fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		if err := rt.ApplyReplication(message); err != nil {
+			// Starting over from a snapshot repairs a replica that
+			// diverged from the stream
+			return "", err
+		}
+		token = message.Token
+	}
+	if err := scanner.Err(); err != nil {
+		return token, err
+	}
+	return token, io.ErrUnexpectedEOF
+}
+
+// Numbers a mutation and adds it to the backlog
+func (log *replicationLog) append(event Event) {
+	log.sequence++
+	log.backlog = append(log.backlog, replicatedEvent{sequence: log.sequence, event: event})
+	if len(log.backlog) > log.size {
+		log.backlog = append(log.backlog[:0], log.backlog[len(log.backlog)-log.size:]...)
+	}
+	log.wake()
+}
+
+// Starts a new history, so that every replica receives a new snapshot
+func (log *replicationLog) reset() {
+	log.epoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+	log.backlog = nil
+	log.wake()
+}
+
+func (log *replicationLog) wake() {
+	close(log.notify)
+	log.notify = make(chan struct{})
+}
+
+// Gets the messages a replica needs to catch up from a token
+// Must be called with the read lock held
+func (log *replicationLog) since(rt *RouteTable, epoch string, sequence uint64) ([]ReplicationMessage, error) {
+	first := log.sequence + 1
+	if len(log.backlog) > 0 {
+		first = log.backlog[0].sequence
+	}
+	if epoch == log.epoch && sequence+1 >= first && sequence <= log.sequence {
+		messages := make([]ReplicationMessage, 0, log.sequence-sequence)
+		for _, entry := range log.backlog[len(log.backlog)-int(log.sequence-sequence):] {
+			event := entry.event
+			messages = append(messages, ReplicationMessage{Token: log.token(entry.sequence), Event: &event})
+		}
+		return messages, nil
+	}
+	snapshot, err := rt.export()
+	if err != nil {
+		return nil, err
+	}
+	return []ReplicationMessage{{Token: log.token(log.sequence), Snapshot: snapshot}}, nil
+}
+
+func (log *replicationLog) token(sequence uint64) string {
+	return log.epoch + "." + strconv.FormatUint(sequence, 10)
+}
+
+// Parses a token into its epoch and sequence number. Malformed tokens
+// resume from nowhere.
+func parseReplicationToken(token string) (string, uint64) {
+	epoch, raw, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", 0
+	}
+	sequence, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return "", 0
+	}
+	return epoch, sequence
+}
@@ -0,0 +1,114 @@
+package gtr
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.Subscribe(context.Background(), "", nil); !errors.Is(err, NOT_REPLICATED) {
+		t.Logf("expected NOT_REPLICATED but found %v", err)
+		t.FailNow()
+	}
+	rt.EnableReplication(2)
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), map[string]any{"ttl": 5})
+	messages := make(chan ReplicationMessage, 16)
+	subscribe := func(token string) context.CancelFunc {
+		ctx, cancel := context.WithCancel(context.Background())
+		go rt.Subscribe(ctx, token, func(message ReplicationMessage) error {
+			messages <- message
+			return nil
+		})
+		return cancel
+	}
+	receive := func() ReplicationMessage {
+		select {
+		case message := <-messages:
+			return message
+		case <-time.After(time.Second):
+			t.Log("expected a message")
+			t.FailNow()
+		}
+		return ReplicationMessage{}
+	}
+	cancel := subscribe("")
+	snapshot := receive()
+	if len(snapshot.Snapshot) == 0 {
+		t.Log("expected the stream to start with a snapshot")
+		t.FailNow()
+	}
+	replica := newRouteTable()
+	if err := replica.ApplyReplication(snapshot); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	rt.Register(PrepareURLTemplate(t), map[string]any{"ttl": 10})
+	event := receive()
+	if event.Event == nil || event.Event.Op != EVENT_REGISTER {
+		t.Log("expected the stream to carry the mutation")
+		t.FailNow()
+	}
+	if err := replica.ApplyReplication(event); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if found, err := replica.Find(PrepareURL(t)); err != nil || found != hash {
+		t.Logf("expected the replica to match the registered route: %v", err)
+		t.FailNow()
+	}
+	cancel()
+
+	// Resumes within the backlog event by event
+	rt.UnregisterHash(hash)
+	cancel = subscribe(event.Token)
+	if message := receive(); message.Event == nil || message.Event.Op != EVENT_UNREGISTER {
+		t.Log("expected the stream to resume after the token")
+		t.FailNow()
+	}
+	cancel()
+
+	// Tokens that fell out of the backlog start over from a snapshot
+	rt.Register(PrepareURLTemplate(t), nil)
+	rt.UnregisterHash(hash)
+	cancel = subscribe(event.Token)
+	if message := receive(); len(message.Snapshot) == 0 {
+		t.Log("expected a snapshot for a token out of the backlog")
+		t.FailNow()
+	}
+	cancel()
+}
+
+func TestFollow(t *testing.T) {
+	rt := newRouteTable()
+	rt.EnableReplication(16)
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"), map[string]any{"ttl": 5})
+	server := httptest.NewServer(NewReplicationHandler(rt))
+	defer server.Close()
+	replica := newRouteTable()
+	stop := replica.Follow(server.URL, 10*time.Millisecond, nil)
+	defer stop()
+	eventually := func(check func() bool) bool {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if check() {
+				return true
+			}
+		}
+		return false
+	}
+	posts := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")
+	if !eventually(func() bool { _, err := replica.Find(posts); return err == nil }) {
+		t.Log("expected the replica to receive the snapshot")
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id"))
+	rt.PatchConfig(hash, []byte(`{"ttl": 30}`))
+	if !eventually(func() bool { return replica.GetConfig(hash)["ttl"] == 30.0 }) {
+		t.Log("expected the replica to apply the update")
+		t.FailNow()
+	}
+}
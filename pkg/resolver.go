@@ -0,0 +1,145 @@
+package gtr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The ResolverOptions struct configures a Resolver
+// Params:
+//   - Client: The client used to reach the remote service (a client
+//     with a timeout of 5 seconds if nil, so that a hung remote service
+//     counts as a failure rather than stalling resolution)
+//   - CacheTTL: How long remote results, including misses, are cached (1 minute if zero)
+//   - MaxEntries: The number of cached results after which the cache is reset (10000 if zero)
+//   - FailureThreshold: The consecutive remote failures opening the circuit (5 if zero)
+//   - Cooldown: How long the circuit stays open before a remote call is retried (30 seconds if zero)
+type ResolverOptions struct {
+	Client           *http.Client
+	CacheTTL         time.Duration
+	MaxEntries       int
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// The Resolver struct resolves URLs against a local route table and
+// falls back to a remote classification service (see
+// NewServiceHandler) for URLs the local table does not match
+type Resolver struct {
+	rt      *RouteTable
+	remote  string
+	options ResolverOptions
+	mutex   sync.Mutex
+	cache   map[string]resolverEntry
+	// Consecutive remote failures and the time the circuit closes again
+	failures int
+	open     time.Time
+}
+
+// The timeout of the default client of resolvers
+const _resolverTimeout = 5 * time.Second
+
+type resolverEntry struct {
+	result  *ServiceResult
+	err     error
+	expires time.Time
+}
+
+// Creates a resolver backed by a local route table and a remote service
+// Params:
+//   - rt: The local route table
+//   - remote: The base URL the remote service is mounted on
+//   - options: The caching and circuit breaking options
+func NewResolver(rt *RouteTable, remote string, options ResolverOptions) *Resolver {
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: _resolverTimeout}
+	}
+	if options.CacheTTL == 0 {
+		options.CacheTTL = time.Minute
+	}
+	if options.MaxEntries == 0 {
+		options.MaxEntries = 10000
+	}
+	if options.FailureThreshold == 0 {
+		options.FailureThreshold = 5
+	}
+	if options.Cooldown == 0 {
+		options.Cooldown = 30 * time.Second
+	}
+	return &Resolver{
+		rt:      rt,
+		remote:  strings.TrimSuffix(remote, "/"),
+		options: options,
+		cache:   make(map[string]resolverEntry),
+	}
+}
+
+// Resolves a URL. URLs matched by the local table never reach the
+// remote service. Remote misses fail with NO_MATCH_FOUND, and remote
+// calls fail with CIRCUIT_OPEN while the remote service is considered down.
+func (resolver *Resolver) Resolve(url *url.URL) (*ServiceResult, error) {
+	result, err := resolver.rt.classify(url.String())
+	if err == nil || !isMiss(err) {
+		return result, err
+	}
+	key := url.String()
+	resolver.mutex.Lock()
+	entry, ok := resolver.cache[key]
+	if ok && time.Now().Before(entry.expires) {
+		resolver.mutex.Unlock()
+		return entry.result, entry.err
+	}
+	if time.Now().Before(resolver.open) {
+		resolver.mutex.Unlock()
+		return nil, CIRCUIT_OPEN
+	}
+	resolver.mutex.Unlock()
+
+	result, err = resolver.fetch(key)
+	resolver.mutex.Lock()
+	defer resolver.mutex.Unlock()
+	if err != nil && !isMiss(err) {
+		resolver.failures++
+		if resolver.failures >= resolver.options.FailureThreshold {
+			resolver.open = time.Now().Add(resolver.options.Cooldown)
+		}
+		return nil, err
+	}
+	resolver.failures = 0
+	if len(resolver.cache) >= resolver.options.MaxEntries {
+		resolver.cache = make(map[string]resolverEntry)
+	}
+	resolver.cache[key] = resolverEntry{result: result, err: err, expires: time.Now().Add(resolver.options.CacheTTL)}
+	return result, err
+}
+
+// Classifies a URL through the remote service
+func (resolver *Resolver) fetch(raw string) (*ServiceResult, error) {
+	response, err := resolver.options.Client.Get(resolver.remote + "/match?url=" + url.QueryEscape(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	result := ServiceResult{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("remote returned %s: %w", response.Status, err)
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return &result, nil
+	case http.StatusNotFound:
+		return &result, fmt.Errorf("%w: %s", NO_MATCH_FOUND, result.Error)
+	}
+	return nil, fmt.Errorf("remote returned %s: %s", response.Status, result.Error)
+}
+
+// Checks whether an error means that no route matches a URL
+func isMiss(err error) bool {
+	return errors.Is(err, NO_MATCH_FOUND) || errors.Is(err, HOST_NOT_REGISTERED) || errors.Is(err, NO_URL_REGISTERED)
+}
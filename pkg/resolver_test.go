@@ -0,0 +1,92 @@
+package gtr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolver(t *testing.T) {
+	local := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/posts/:id": nil,
+	})
+	remote := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": nil,
+	})
+	calls := int32(0)
+	handler := NewServiceHandler(remote)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	resolver := NewResolver(local, server.URL, ResolverOptions{})
+
+	if result, err := resolver.Resolve(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1")); err != nil || result.Params["id"] != "1" {
+		t.Logf("unexpected local result %v %v", result, err)
+		t.FailNow()
+	}
+	for i := 0; i < 2; i++ {
+		result, err := resolver.Resolve(PrepareURL(t))
+		if err != nil || result.Hash != CreateHash(PrepareURLTemplate(t)) || result.Params["username"] != "ken" {
+			t.Logf("unexpected remote result %v %v", result, err)
+			t.FailNow()
+		}
+	}
+	miss := PrepareURLFrom(t, "http://www.abcdefg.com/unknown")
+	for i := 0; i < 2; i++ {
+		if _, err := resolver.Resolve(miss); !errors.Is(err, NO_MATCH_FOUND) {
+			t.Logf("expected NO_MATCH_FOUND but found %v", err)
+			t.FailNow()
+		}
+	}
+	if calls != 2 {
+		t.Logf("expected remote results to be cached but found %d calls", calls)
+		t.FailNow()
+	}
+}
+
+func TestResolverCircuitBreaker(t *testing.T) {
+	calls := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		writeError(w, http.StatusInternalServerError, errors.New("down"))
+	}))
+	defer server.Close()
+	resolver := NewResolver(newRouteTable(), server.URL, ResolverOptions{FailureThreshold: 2, Cooldown: 50 * time.Millisecond})
+	url := PrepareURL(t)
+	for i := 0; i < 2; i++ {
+		if _, err := resolver.Resolve(url); err == nil || errors.Is(err, CIRCUIT_OPEN) {
+			t.Logf("expected a remote failure but found %v", err)
+			t.FailNow()
+		}
+	}
+	if _, err := resolver.Resolve(url); !errors.Is(err, CIRCUIT_OPEN) {
+		t.Logf("expected CIRCUIT_OPEN but found %v", err)
+		t.FailNow()
+	}
+	if calls != 2 {
+		t.Logf("expected the open circuit to skip the remote but found %d calls", calls)
+		t.FailNow()
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := resolver.Resolve(url); err == nil || errors.Is(err, CIRCUIT_OPEN) {
+		t.Logf("expected a retried remote call but found %v", err)
+		t.FailNow()
+	}
+	if _, err := resolver.Resolve(url); !errors.Is(err, CIRCUIT_OPEN) {
+		t.Logf("expected the circuit to open again but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestResolverDefaultClient(t *testing.T) {
+	resolver := NewResolver(newRouteTable(), "http://localhost", ResolverOptions{})
+	if resolver.options.Client == http.DefaultClient || resolver.options.Client.Timeout != _resolverTimeout {
+		t.Log("expected the default client to time out")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,308 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The Rule struct describes a route and its config the way it is
+// written in rule files and event logs
+type Rule struct {
+	Template   string                    `json:"template"`
+	Method     string                    `json:"method,omitempty"`
+	Config     map[string]any            `json:"config,omitempty"`
+	Class      *RouteClass               `json:"class,omitempty"`
+	Source     string                    `json:"source,omitempty"`
+	Docs       map[string]ParamDoc       `json:"docs,omitempty"`
+	BodyKeys   []string                  `json:"bodyKeys,omitempty"`
+	Dedup      []string                  `json:"dedup,omitempty"`
+	Ignore     []string                  `json:"ignore,omitempty"`
+	Keep       []string                  `json:"keep,omitempty"`
+	Overlays   map[string]map[string]any `json:"overlays,omitempty"`
+	Extensions map[string]map[string]any `json:"extensions,omitempty"`
+	Experiment *Experiment               `json:"experiment,omitempty"`
+	Variants   *Variants                 `json:"variants,omitempty"`
+	Ownership  *Ownership                `json:"ownership,omitempty"`
+	QueryMode  *QueryMode                `json:"queryMode,omitempty"`
+	Name       string                    `json:"name,omitempty"`
+}
+
+// The RuleFile struct is the content of a base rule file
+// Params:
+//   - Fragments: Fragments defined before any route is registered
+//   - Routes: The routes to register
+type RuleFile struct {
+	Fragments map[string]string `json:"fragments,omitempty"`
+	Routes    []Rule            `json:"routes"`
+}
+
+// The OverlayFile struct is the content of an environment overlay.
+// Overlays may only change the configs of routes defined by the base
+// rule file. Each config is a JSON Merge Patch keyed by the template
+// of the route it applies to.
+type OverlayFile struct {
+	Configs map[string]map[string]any `json:"configs"`
+}
+
+// Gets the rule describing a route
+func (route *Route) rule(conf map[string]any) Rule {
+	class := route.class
+	queryMode := route.queryMode
+	return Rule{
+		Template:   route.template,
+		Method:     route.method,
+		Config:     conf,
+		Class:      &class,
+		Source:     route.source,
+		Docs:       route.docs,
+		BodyKeys:   route.bodyKeys,
+		Dedup:      route.dedupParams,
+		Ignore:     route.ignore,
+		Keep:       route.keep,
+		Overlays:   route.overlays,
+		Extensions: route.extensionOverlays,
+		Experiment: route.experiment,
+		Variants:   route.variants,
+		Ownership:  route.owners(),
+		QueryMode:  &queryMode,
+		Name:       route.name,
+	}
+}
+
+// Gets the route options that restore the route of a rule
+func (rule Rule) options() []RouteOption {
+	opts := make([]RouteOption, 0)
+	if rule.Class != nil {
+		opts = append(opts, WithClass(*rule.Class))
+	}
+	if len(rule.Source) > 0 {
+		source := rule.Source
+		opts = append(opts, func(route *Route) error {
+			route.source = source
+			return nil
+		})
+	}
+	if len(rule.Docs) > 0 {
+		opts = append(opts, WithParamDocs(rule.Docs))
+	}
+	if len(rule.BodyKeys) > 0 {
+		opts = append(opts, WithBodyKeys(rule.BodyKeys...))
+	}
+	if len(rule.Dedup) > 0 {
+		opts = append(opts, WithDedupParams(rule.Dedup...))
+	}
+	if len(rule.Ignore) > 0 {
+		opts = append(opts, IgnoreQuery(rule.Ignore...))
+	}
+	if len(rule.Keep) > 0 {
+		opts = append(opts, KeepQuery(rule.Keep...))
+	}
+	for method, overlay := range rule.Overlays {
+		opts = append(opts, WithMethodOverlay(method, overlay))
+	}
+	for extension, overlay := range rule.Extensions {
+		opts = append(opts, WithExtensionOverlay(extension, overlay))
+	}
+	if rule.Experiment != nil {
+		opts = append(opts, WithExperiment(*rule.Experiment))
+	}
+	if rule.Variants != nil {
+		opts = append(opts, WithVariants(*rule.Variants))
+	}
+	if rule.Ownership != nil {
+		opts = append(opts, WithOwnership(*rule.Ownership))
+	}
+	if rule.QueryMode != nil {
+		opts = append(opts, WithQueryMode(*rule.QueryMode))
+	}
+	if len(rule.Name) > 0 {
+		opts = append(opts, WithName(rule.Name))
+	}
+	return opts
+}
+
+// Restores the settings of a rule that may change once its route is
+// registered. Settings missing from the rule are left unchanged.
+func (rule Rule) update(route *Route) error {
+	if rule.Overlays != nil {
+		route.overlays = make(map[string]map[string]any, len(rule.Overlays))
+		for method, overlay := range rule.Overlays {
+			if err := WithMethodOverlay(method, overlay)(route); err != nil {
+				return err
+			}
+		}
+	}
+	if rule.Experiment != nil {
+		if err := WithExperiment(*rule.Experiment)(route); err != nil {
+			return err
+		}
+	}
+	if rule.Variants != nil {
+		if err := WithVariants(*rule.Variants)(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Registers the route described by a rule
+func (rt *RouteTable) registerRule(rule Rule) error {
+	url, err := ParseTemplate(rule.Template)
+	if err != nil {
+		return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	_, err = rt.register(rule.Method, url, rule.Config, rule.options()...)
+	return err
+}
+
+// Writes every registered route and its config as a JSON rule file
+// that LoadRules can register again. Templates are written with their
+// fragments expanded.
+func (rt *RouteTable) ExportRules(w io.Writer) error {
+	data, err := rt.exportRules()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Encodes the routes while holding the read lock, since rules share
+// the configs and overlays of the routes
+func (rt *RouteTable) exportRules() ([]byte, error) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	file := RuleFile{Routes: make([]Rule, 0, len(rt.index))}
+	for _, route := range rt.sorted() {
+		file.Routes = append(file.Routes, route.rule(rt.configs[route.hash]))
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Registers the fragments and routes of a JSON rule file. The file is
+// decoded before the table is locked, and its rules are registered to a
+// copy of the table first, so that a file failing to load leaves the
+// table unchanged.
+func (rt *RouteTable) LoadRules(r io.Reader) error {
+	file := RuleFile{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	if err := rt.copy().loadRules(file); err != nil {
+		return err
+	}
+	return rt.loadRules(file)
+}
+
+// Registers the fragments and routes of a rule file
+// Must be called with the write lock held
+func (rt *RouteTable) loadRules(file RuleFile) error {
+	for name, path := range file.Fragments {
+		if err := rt.defineFragment(name, path); err != nil {
+			return err
+		}
+	}
+	for index, rule := range file.Routes {
+		if err := rt.registerRule(rule); err != nil {
+			return fmt.Errorf("route %d: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// Applies a JSON environment overlay to the configs of registered
+// routes. Every entry must target a registered route, otherwise no
+// config is changed and HASH_NOT_REGISTERED is returned. Templates
+// are resolved like registered templates, so fragments and both
+// parameter styles may be used.
+func (rt *RouteTable) ApplyOverlay(r io.Reader) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	file := OverlayFile{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	templates := make([]string, 0, len(file.Configs))
+	for template := range file.Configs {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	routes := make([]*Route, len(templates))
+	for index, template := range templates {
+		url, err := ParseTemplate(template)
+		if err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		route, err := rt.prepare("", url)
+		if err != nil {
+			return err
+		}
+		existing, ok := rt.index[route.hash]
+		if !ok {
+			return fmt.Errorf("%w: overlay targets %s", HASH_NOT_REGISTERED, template)
+		}
+		routes[index] = existing
+	}
+	for index, route := range routes {
+		conf := MergePatch(rt.configs[route.hash], file.Configs[templates[index]])
+		if err := rt.setConfig(route.hash, conf); err != nil {
+			return err
+		}
+		if err := rt.record(EVENT_UPDATE, route, conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loads a base rule file followed by any number of environment
+// overlay files
+func (rt *RouteTable) LoadRuleFiles(base string, overlays ...string) error {
+	if err := loadFile(base, rt.LoadRules); err != nil {
+		return err
+	}
+	for _, overlay := range overlays {
+		if err := loadFile(overlay, rt.ApplyOverlay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Populates the route table from a declarative rule file listing
+// the templates, methods, and configs of its routes (see LoadRules),
+// so that cache rules can be defined in config rather than code.
+// Only JSON files (`.json`) are supported, other formats fail with
+// UNSUPPORTED_FORMAT.
+func (rt *RouteTable) LoadFromFile(path string) error {
+	if extension := strings.ToLower(filepath.Ext(path)); extension != ".json" {
+		return fmt.Errorf("%w: %s", UNSUPPORTED_FORMAT, path)
+	}
+	return loadFile(path, rt.LoadRules)
+}
+
+func loadFile(path string, load func(r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := load(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
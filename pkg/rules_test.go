@@ -0,0 +1,160 @@
+package gtr
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _baseRules = `{
+	"fragments": {"userPath": "/users/:username"},
+	"routes": [
+		{"template": "http://www.abcdefg.com/api/v1/@userPath/details?type=cache", "config": {"ttl": 10, "format": "json"}},
+		{"template": "http://www.abcdefg.com/api/v1/posts/{id}", "config": {"ttl": 5}, "class": "user", "bodyKeys": ["/id"]}
+	]
+}`
+
+func TestLoadRuleFiles(t *testing.T) {
+	directory := t.TempDir()
+	base := filepath.Join(directory, "rules.json")
+	overlay := filepath.Join(directory, "production.json")
+	os.WriteFile(base, []byte(_baseRules), 0644)
+	os.WriteFile(overlay, []byte(`{"configs": {"http://www.abcdefg.com/api/v1/@userPath/details?type=cache": {"ttl": 60, "format": null}}}`), 0644)
+	rt := newRouteTable()
+	if err := rt.LoadRuleFiles(base, overlay); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	if conf := rt.GetConfig(hash); conf["ttl"] != 60.0 || conf["format"] != nil {
+		t.Logf("overlay not applied %v", conf)
+		t.FailNow()
+	}
+	posts, err := rt.Lookup(CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/:id")))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if posts.Class() != CLASS_USER || rt.GetConfig(posts.Hash())["ttl"] != 5.0 {
+		t.Log("base rule loaded incorrectly")
+		t.FailNow()
+	}
+}
+
+func TestApplyOverlayValidation(t *testing.T) {
+	rt := newRouteTable()
+	if err := rt.LoadRules(strings.NewReader(_baseRules)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(PrepareURLTemplate(t))
+	err := rt.ApplyOverlay(strings.NewReader(`{"configs": {
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 60},
+		"http://www.abcdefg.com/api/v2/posts/:id": {"ttl": 60}
+	}}`))
+	if !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["ttl"] != 10.0 {
+		t.Log("invalid overlay was partially applied")
+		t.FailNow()
+	}
+	err = rt.ApplyOverlay(strings.NewReader(`{"routes": [{"template": "http://www.abcdefg.com/api/v2/posts/:id"}]}`))
+	if !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected overlays adding routes to be rejected but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestLoadFromFile(t *testing.T) {
+	directory := t.TempDir()
+	path := filepath.Join(directory, "rules.json")
+	os.WriteFile(path, []byte(`{"routes": [
+		{"template": "http://www.abcdefg.com/api/v1/users/:username/details?type=cache", "config": {"ttl": "5m"}},
+		{"template": "http://www.abcdefg.com/api/v1/posts/:id", "method": "POST", "config": {"bypass": true}}
+	]}`), 0644)
+	rt := newRouteTable()
+	if err := rt.LoadFromFile(path); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if ttl, ok := rt.GetPolicy(CreateHash(PrepareURLTemplate(t)), "GET").TTL(); !ok || ttl != 5*time.Minute {
+		t.Logf("unexpected ttl %v", ttl)
+		t.FailNow()
+	}
+	hash, err := rt.FindMethod("POST", PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/posts/1"))
+	if err != nil || !rt.GetPolicy(hash, "POST").Bypass() {
+		t.Logf("expected the POST route to be loaded: %v", err)
+		t.FailNow()
+	}
+	if err := rt.LoadFromFile(filepath.Join(directory, "rules.yaml")); !errors.Is(err, UNSUPPORTED_FORMAT) {
+		t.Logf("expected UNSUPPORTED_FORMAT but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.LoadFromFile(filepath.Join(directory, "missing.json")); err == nil {
+		t.Log("expected missing files to fail")
+		t.FailNow()
+	}
+}
+
+func TestLoadRulesFailure(t *testing.T) {
+	rt := newRouteTable()
+	existing := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/health")
+	rt.Register(existing, nil)
+	rules := `{"fragments": {"v1": "api/v1"}, "routes": [
+		{"template": "http://www.abcdefg.com/@v1/posts/:id"},
+		{"template": "http://www.abcdefg.com/@v1/users/:id/friends/:id"}
+	]}`
+	if err := rt.LoadRules(strings.NewReader(rules)); !errors.Is(err, DUPLICATE_PARAMETER) {
+		t.Logf("expected DUPLICATE_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+	if len(rt.Routes()) != 1 || len(rt.fragments) != 0 {
+		t.Log("expected a failed load to leave the table unchanged")
+		t.FailNow()
+	}
+	if _, err := rt.Lookup(CreateHash(existing)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+type blockingReader struct {
+	release chan struct{}
+	reader  io.Reader
+}
+
+func (reader *blockingReader) Read(p []byte) (int, error) {
+	<-reader.release
+	return reader.reader.Read(p)
+}
+
+func TestLoadRulesDecodesUnlocked(t *testing.T) {
+	rt := newRouteTable()
+	existing := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/health")
+	rt.Register(existing, nil)
+	reader := &blockingReader{release: make(chan struct{}), reader: strings.NewReader(`{"routes": [{"template": "http://www.abcdefg.com/api/v1/posts/:id"}]}`)}
+	done := make(chan error)
+	go func() {
+		done <- rt.LoadRules(reader)
+	}()
+	// Lookups are served while the rule file is being read
+	if _, err := rt.Find(existing); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	close(reader.release)
+	if err := <-done; err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(rt.Routes()) != 2 {
+		t.Log("expected the rule file to be loaded")
+		t.FailNow()
+	}
+}
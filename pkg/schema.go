@@ -0,0 +1,138 @@
+package gtr
+
+// The JSON Schema (draft 2020-12) of rule files (see RuleFile) and of
+// the well-known keys of route configs (see Policy)
+const _configSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/vedadiyan/gtr/rules.schema.json",
+  "title": "GTR rule file",
+  "type": "object",
+  "properties": {
+    "fragments": {
+      "type": "object",
+      "additionalProperties": {"type": "string"}
+    },
+    "routes": {
+      "type": "array",
+      "items": {"$ref": "#/$defs/rule"}
+    }
+  },
+  "required": ["routes"],
+  "additionalProperties": false,
+  "$defs": {
+    "rule": {
+      "type": "object",
+      "properties": {
+        "template": {"type": "string", "minLength": 1},
+        "method": {"type": "string"},
+        "config": {"$ref": "#/$defs/config"},
+        "class": {"enum": ["learned", "user", "system"]},
+        "source": {"type": "string"},
+        "docs": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "object",
+            "properties": {
+              "description": {"type": "string"},
+              "example": {"type": "string"}
+            },
+            "additionalProperties": false
+          }
+        },
+        "bodyKeys": {
+          "type": "array",
+          "items": {"type": "string", "pattern": "^(/.*)?$"}
+        },
+        "dedup": {"type": "array", "items": {"type": "string"}},
+        "ignore": {"type": "array", "items": {"type": "string"}},
+        "keep": {"type": "array", "items": {"type": "string"}},
+        "overlays": {
+          "type": "object",
+          "additionalProperties": {"$ref": "#/$defs/config"}
+        },
+        "extensions": {
+          "type": "object",
+          "additionalProperties": {"$ref": "#/$defs/config"}
+        },
+        "experiment": {
+          "type": "object",
+          "properties": {
+            "param": {"type": "string"},
+            "buckets": {
+              "type": "array",
+              "items": {
+                "type": "object",
+                "properties": {
+                  "name": {"type": "string"},
+                  "weight": {"type": "integer", "minimum": 0},
+                  "overlay": {"$ref": "#/$defs/config"}
+                },
+                "required": ["name", "weight"],
+                "additionalProperties": false
+              }
+            }
+          },
+          "required": ["param", "buckets"],
+          "additionalProperties": false
+        },
+        "variants": {
+          "type": "object",
+          "properties": {
+            "param": {"type": "string"},
+            "overlays": {
+              "type": "object",
+              "additionalProperties": {"$ref": "#/$defs/config"}
+            }
+          },
+          "required": ["param", "overlays"],
+          "additionalProperties": false
+        },
+        "ownership": {
+          "type": "object",
+          "properties": {
+            "owner": {"type": "string"},
+            "team": {"type": "string"},
+            "contact": {"type": "string"}
+          },
+          "additionalProperties": false
+        },
+        "queryMode": {"enum": ["strict", "subset", "ignore"]},
+        "name": {"type": "string"}
+      },
+      "required": ["template"],
+      "additionalProperties": false
+    },
+    "config": {
+      "type": "object",
+      "properties": {
+        "ttl": {"$ref": "#/$defs/duration"},
+        "bypass": {"type": "boolean"},
+        "metrics_sample_rate": {"$ref": "#/$defs/rate"},
+        "trace_sample_rate": {"$ref": "#/$defs/rate"},
+        "error_budget": {"$ref": "#/$defs/rate"},
+        "error_window": {"$ref": "#/$defs/duration"},
+        "error_min_requests": {"type": "integer", "minimum": 0},
+        "stale_if_error": {"$ref": "#/$defs/duration"},
+        "stale_while_revalidate": {"$ref": "#/$defs/duration"},
+        "key_version": {"type": "integer", "minimum": 0}
+      },
+      "additionalProperties": true
+    },
+    "duration": {
+      "description": "A duration string such as 5s or 1h30m, or a number of seconds",
+      "oneOf": [
+        {"type": "string", "pattern": "^([0-9]+(\\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$"},
+        {"type": "number"}
+      ]
+    },
+    "rate": {"type": "number", "minimum": 0, "maximum": 1}
+  }
+}
+`
+
+// Gets the JSON Schema of rule files, including the well-known keys of
+// route configs, so that external tools can validate rule files
+// without importing the Go types. Configs may hold other keys too.
+func ConfigSchema() []byte {
+	return []byte(_configSchema)
+}
@@ -0,0 +1,66 @@
+package gtr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	schema := map[string]any{}
+	if err := json.Unmarshal(ConfigSchema(), &schema); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defs := schema["$defs"].(map[string]any)
+	properties := func(node any, path ...string) map[string]any {
+		for _, key := range path {
+			node = node.(map[string]any)[key]
+		}
+		return node.(map[string]any)["properties"].(map[string]any)
+	}
+	rule := properties(defs["rule"])
+	// Every field of the Go types must be described by the schema
+	covers := func(properties map[string]any, value any) {
+		kind := reflect.TypeOf(value)
+		for i := 0; i < kind.NumField(); i++ {
+			name := strings.Split(kind.Field(i).Tag.Get("json"), ",")[0]
+			if _, ok := properties[name]; !ok {
+				t.Logf("expected the schema to describe %s.%s", kind.Name(), name)
+				t.FailNow()
+			}
+		}
+	}
+	covers(properties(schema), RuleFile{})
+	covers(rule, Rule{})
+	covers(properties(rule["docs"], "additionalProperties"), ParamDoc{})
+	covers(properties(rule["experiment"]), Experiment{})
+	covers(properties(rule["experiment"], "properties", "buckets", "items"), Bucket{})
+	covers(properties(rule["variants"]), Variants{})
+	covers(properties(rule["ownership"]), Ownership{})
+	config := properties(defs["config"])
+	for _, key := range []string{POLICY_TTL, POLICY_BYPASS, POLICY_METRICS_SAMPLE_RATE, POLICY_TRACE_SAMPLE_RATE, POLICY_ERROR_BUDGET, POLICY_ERROR_WINDOW, POLICY_ERROR_MIN_REQUESTS, POLICY_STALE_IF_ERROR, POLICY_STALE_WHILE_REVALIDATE, POLICY_KEY_VERSION} {
+		if _, ok := config[key]; !ok {
+			t.Logf("expected the schema to describe the %s policy", key)
+			t.FailNow()
+		}
+	}
+	for _, class := range []RouteClass{CLASS_LEARNED, CLASS_USER, CLASS_SYSTEM} {
+		if !strings.Contains(string(ConfigSchema()), `"`+class.String()+`"`) {
+			t.Logf("expected the schema to list the %s class", class)
+			t.FailNow()
+		}
+	}
+}
+
+func TestAdminSchema(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewAdminHandler(newRouteTable()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/schema", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/schema+json" || !json.Valid(recorder.Body.Bytes()) {
+		t.Logf("unexpected response %d %s", recorder.Code, recorder.Body.String())
+		t.FailNow()
+	}
+}
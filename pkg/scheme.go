@@ -0,0 +1,42 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// The SchemePolicy determines which URL schemes the route table
+// accepts when registering and matching URLs
+type SchemePolicy int
+
+const (
+	// Schemes are not checked, so `mailto:` or `ftp://` URLs are
+	// matched like any other URL (the default)
+	SCHEME_ANY SchemePolicy = iota
+	// Only `http`, `https`, virtual routes (`graphql:`, `jsonrpc:`)
+	// and URLs without a scheme are accepted; everything else fails
+	// with UNSUPPORTED_SCHEME
+	SCHEME_HTTP_ONLY
+)
+
+// Sets the scheme policy of the route table
+func (rt *RouteTable) SetSchemePolicy(policy SchemePolicy) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.scheme = policy
+}
+
+func (rt *RouteTable) checkScheme(url *url.URL) error {
+	if rt.scheme != SCHEME_HTTP_ONLY {
+		return nil
+	}
+	scheme := strings.ToLower(url.Scheme)
+	switch {
+	case len(scheme) == 0, scheme == "http", scheme == "https":
+		return nil
+	case _virtualSchemes[scheme] && len(url.Opaque) > 0:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", UNSUPPORTED_SCHEME, url.Scheme)
+}
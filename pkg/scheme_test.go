@@ -0,0 +1,50 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestSchemePolicy(t *testing.T) {
+	mailto, _ := url.Parse("mailto:ken@abcdefg.com")
+	ftp, _ := url.Parse("ftp://www.abcdefg.com/api/v1/users/:username/details?type=cache")
+	graphql, _ := url.Parse("graphql:GetUser")
+	relative, _ := url.Parse("/api/v1/users/:username/details?type=cache")
+
+	rt := newRouteTable()
+	for _, url := range []*url.URL{mailto, ftp, graphql} {
+		if err := rt.Register(url, nil); err != nil {
+			t.Logf("%s should be accepted by default: %s", url, err)
+			t.FailNow()
+		}
+	}
+	if _, err := rt.Find(mailto); err != nil {
+		t.Log("mailto should match by default")
+		t.FailNow()
+	}
+
+	rt = newRouteTable()
+	rt.SetSchemePolicy(SCHEME_HTTP_ONLY)
+	for _, url := range []*url.URL{mailto, ftp} {
+		if err := rt.Register(url, nil); !errors.Is(err, UNSUPPORTED_SCHEME) {
+			t.Logf("%s should be rejected", url)
+			t.FailNow()
+		}
+	}
+	for _, url := range []*url.URL{PrepareURLTemplate(t), graphql, relative} {
+		if err := rt.Register(url, nil); err != nil {
+			t.Logf("%s should be accepted: %s", url, err)
+			t.FailNow()
+		}
+	}
+	if _, err := rt.Find(PrepareURL(t)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	ftpRequest, _ := url.Parse("ftp://www.abcdefg.com/api/v1/users/ken/details?type=cache")
+	if _, err := rt.Find(ftpRequest); !errors.Is(err, UNSUPPORTED_SCHEME) {
+		t.Log("ftp request should be rejected")
+		t.FailNow()
+	}
+}
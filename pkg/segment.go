@@ -0,0 +1,42 @@
+package gtr
+
+// The segmentKind tells literal path segments from parameters
+type segmentKind uint8
+
+const (
+	_literalSegment segmentKind = iota
+	_paramSegment
+)
+
+// The segment struct describes a single path segment. Segments are
+// stored in the order they appear, without the empty segments of
+// repeated slashes.
+type segment struct {
+	kind segmentKind
+	// The literal segment, or the name of a parameter
+	value string
+	// The index of the segment within the path split by slashes
+	index int
+	// The constraint of a parameter, if any
+	constraint paramConstraint
+	// The name of the extension parameter of a parameter written
+	// `:name.:ext`, if any
+	extension string
+}
+
+// Checks whether the segment is a parameter
+func (segment segment) param() bool {
+	return segment.kind == _paramSegment
+}
+
+// Gets the segment the way it is written in templates, without the
+// constraints of parameters
+func (segment segment) pattern() string {
+	switch {
+	case !segment.param():
+		return segment.value
+	case len(segment.extension) > 0:
+		return ":" + segment.value + ".:" + segment.extension
+	}
+	return ":" + segment.value
+}
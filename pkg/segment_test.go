@@ -0,0 +1,51 @@
+package gtr
+
+import "testing"
+
+func TestOrderedSegments(t *testing.T) {
+	route := ParseRoute(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id<int>/posts/{post}"))
+	expected := []segment{
+		{kind: _literalSegment, value: "api", index: 1},
+		{kind: _literalSegment, value: "v1", index: 2},
+		{kind: _literalSegment, value: "users", index: 3},
+		{kind: _paramSegment, value: "id", index: 4},
+		{kind: _literalSegment, value: "posts", index: 5},
+		{kind: _paramSegment, value: "post", index: 6},
+	}
+	if len(route.segments) != len(expected) {
+		t.Logf("expected %d segments but found %d", len(expected), len(route.segments))
+		t.FailNow()
+	}
+	for position, segment := range expected {
+		found := route.segments[position]
+		if found.kind != segment.kind || found.value != segment.value || found.index != segment.index {
+			t.Logf("expected %v at %d but found %v", segment, position, found)
+			t.FailNow()
+		}
+	}
+	if route.segments[3].constraint == nil || route.segments[5].constraint != nil {
+		t.Log("expected only the id parameter to be constrained")
+		t.FailNow()
+	}
+}
+
+func TestEmptySegments(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/api/users/:id"), nil)
+	url := PrepareURLFrom(t, "http://www.abcdefg.com/api//users/1")
+	match, err := rt.Match(url)
+	if err != nil || match.Params["id"] != "1" {
+		t.Logf("expected empty segments to be ignored but found %v %v", match, err)
+		t.FailNow()
+	}
+	if redacted, _ := rt.RedactURL(url, match); redacted != "http://www.abcdefg.com/api//users/:id" {
+		t.Logf("unexpected redacted URL %s", redacted)
+		t.FailNow()
+	}
+	// Explanations report the indexes of the segments within the template
+	explanation := rt.Explain(url)
+	if segments := explanation.Candidates[0].Segments; segments[2].Index != 3 || segments[2].Value != "1" {
+		t.Logf("unexpected segment checks %v", segments)
+		t.FailNow()
+	}
+}
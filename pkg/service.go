@@ -0,0 +1,110 @@
+package gtr
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The ServiceResult struct is the outcome of classifying a single URL
+// through the classification service
+type ServiceResult struct {
+	URL      string            `json:"url"`
+	Hash     string            `json:"hash,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Query    map[string]any    `json:"query,omitempty"`
+	CacheKey string            `json:"cacheKey,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Creates an http.Handler classifying URLs against the route table,
+// so that services written in other languages can reuse it for their
+// cache-key decisions
+//
+//	GET  /match?url=...  classifies a single URL
+//	POST /match          classifies a stream of newline separated URLs
+//	                     and streams the results back as JSON lines
+//
+// Use http.StripPrefix to mount the handler under a prefix.
+func NewServiceHandler(rt *RouteTable) http.Handler {
+	return &serviceHandler{rt: rt}
+}
+
+// Serves the classification service of the route table on an address
+func ListenAndServe(addr string, rt *RouteTable) error {
+	return http.ListenAndServe(addr, NewServiceHandler(rt))
+}
+
+type serviceHandler struct {
+	rt *RouteTable
+}
+
+func (handler *serviceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.Trim(r.URL.Path, "/") != "match" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		result, err := handler.rt.classify(r.URL.Query().Get("url"))
+		status := http.StatusOK
+		if err != nil {
+			status = statusOf(err)
+		}
+		writeJSON(w, status, result)
+	case http.MethodPost:
+		handler.stream(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (handler *serviceHandler) stream(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		raw := strings.TrimSpace(scanner.Text())
+		if len(raw) == 0 {
+			continue
+		}
+		result, _ := handler.rt.classify(raw)
+		if err := encoder.Encode(result); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// Classifies a raw URL
+func (rt *RouteTable) classify(raw string) (*ServiceResult, error) {
+	result := ServiceResult{URL: raw}
+	url, err := url.Parse(raw)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		result.Error = err.Error()
+		return &result, err
+	}
+	match, err := rt.Match(url)
+	if err != nil {
+		result.Error = err.Error()
+		return &result, err
+	}
+	result.Hash = match.Hash
+	if route, err := rt.Lookup(match.Hash); err == nil {
+		result.Template = route.template
+	}
+	result.Params = match.Params
+	result.Query = match.Query
+	result.CacheKey, _ = rt.CacheKey(url)
+	return &result, nil
+}
@@ -0,0 +1,58 @@
+package gtr
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServiceHandler(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": {"ttl": 10},
+	})
+	server := httptest.NewServer(NewServiceHandler(rt))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/match?url=" + url.QueryEscape(PrepareURL(t).String()))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	result := ServiceResult{}
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || result.Hash != CreateHash(PrepareURLTemplate(t)) || result.Params["username"] != "ken" || len(result.CacheKey) == 0 {
+		t.Logf("unexpected result %d %v", response.StatusCode, result)
+		t.FailNow()
+	}
+
+	response, _ = http.Get(server.URL + "/match?url=" + url.QueryEscape("http://www.abcdefg.com/api/v1/users/ken/details"))
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Logf("expected 404 but found %d", response.StatusCode)
+		t.FailNow()
+	}
+
+	body := "http://www.abcdefg.com/api/v1/users/ken/details?type=cache\n\nhttp://www.abcdefg.com/other\nhttp://www.abcdefg.com/api/v1/users/dennis/details?type=cache\n"
+	response, err = http.Post(server.URL+"/match", "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	defer response.Body.Close()
+	results := make([]ServiceResult, 0)
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		result := ServiceResult{}
+		json.Unmarshal(scanner.Bytes(), &result)
+		results = append(results, result)
+	}
+	if len(results) != 3 || results[0].Params["username"] != "ken" || len(results[1].Error) == 0 || results[2].Params["username"] != "dennis" {
+		t.Logf("unexpected results %v", results)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,91 @@
+package gtr
+
+import (
+	"hash/fnv"
+	"net/url"
+	"strings"
+)
+
+// The ShardedRouteTable partitions routes across several route tables
+// based on the hash of their host, so that registrations for one host
+// never block lookups for another. Hosts are sharded regardless of
+// their case and port. Templates without a host or with wildcard or
+// parameter host labels may match hosts of any shard, so they are
+// registered to every shard.
+type ShardedRouteTable struct {
+	shards []*RouteTable
+}
+
+// Creates a new sharded route table
+// Params:
+//   - shards: The number of shards (values below 1 are treated as 1)
+func NewShardedRouteTable(shards int) *ShardedRouteTable {
+	if shards < 1 {
+		shards = 1
+	}
+	srt := ShardedRouteTable{
+		shards: make([]*RouteTable, shards),
+	}
+	for i := range srt.shards {
+		srt.shards[i] = newRouteTable()
+	}
+	return &srt
+}
+
+// Gets the index of the shard responsible for a host
+func (srt *ShardedRouteTable) ShardFor(host string) int {
+	name, _ := splitHost(host)
+	hash := fnv.New32a()
+	hash.Write([]byte(strings.TrimSuffix(strings.ToLower(name), ".")))
+	return int(hash.Sum32() % uint32(len(srt.shards)))
+}
+
+// Registers a new route to the shard responsible for its host, or to
+// every shard if its host may match hosts of any shard. Every shard is
+// locked while a route is registered to every shard, so that the route
+// is registered to all of them or, if a shard rejects it, to none.
+func (srt *ShardedRouteTable) Register(url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	if route := ParseRoute(url); route.literalHost() {
+		return srt.shards[srt.ShardFor(url.Host)].Register(url, conf, opts...)
+	}
+	// Shards are locked in order, so that concurrent registrations
+	// never wait for each other in a cycle
+	for _, shard := range srt.shards {
+		shard.mutex.Lock()
+		defer shard.mutex.Unlock()
+	}
+	registered := make([]*Route, 0, len(srt.shards))
+	for _, shard := range srt.shards {
+		route, err := shard.register("", url, conf, opts...)
+		if err != nil {
+			for index, route := range registered {
+				if route != nil {
+					srt.shards[index].unregister(route.hash)
+				}
+			}
+			return err
+		}
+		registered = append(registered, route)
+	}
+	return nil
+}
+
+// Checks whether the host of the template only has literal labels
+func (route *Route) literalHost() bool {
+	for _, label := range route.hostLabels {
+		if label == "*" || strings.HasPrefix(label, ":") {
+			return false
+		}
+	}
+	return len(route.hostLabels) > 0
+}
+
+// Finds the route template for a given URL
+func (srt *ShardedRouteTable) Find(url *url.URL) (string, error) {
+	return srt.shards[srt.ShardFor(url.Host)].Find(url)
+}
+
+// Gets configuration for a given host and hash
+func (srt *ShardedRouteTable) GetConfig(host string, hash string) map[string]any {
+	return srt.shards[srt.ShardFor(host)].GetConfig(hash)
+}
@@ -0,0 +1,92 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestShardedRouteTable(t *testing.T) {
+	srt := NewShardedRouteTable(8)
+	hosts := []string{"a.example.com", "b.example.com", "c.example.com"}
+	for index, host := range hosts {
+		url, _ := url.Parse("http://" + host + "/api/users/:username")
+		if err := srt.Register(url, map[string]any{"ttl": index}); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	for index, host := range hosts {
+		url, _ := url.Parse("http://" + host + "/api/users/ken")
+		hash, err := srt.Find(url)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if srt.GetConfig(host, hash)["ttl"] != index {
+			t.Log("config resolved from the wrong shard")
+			t.FailNow()
+		}
+	}
+	if srt.ShardFor("A.EXAMPLE.COM") != srt.ShardFor("a.example.com") {
+		t.Log("host sharding should be case insensitive")
+		t.FailNow()
+	}
+}
+
+func TestShardedRouteTableHosts(t *testing.T) {
+	srt := NewShardedRouteTable(8)
+	if srt.ShardFor("a.example.com:443") != srt.ShardFor("a.example.com") {
+		t.Log("host sharding should ignore the port")
+		t.FailNow()
+	}
+	for _, template := range []string{"http://*.example.com/api/posts/:id", "http://:tenant.example.com/api/users/:username"} {
+		url, err := ParseTemplate(template)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+		if err := srt.Register(url, map[string]any{"ttl": 5}); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	for _, raw := range []string{"http://a.example.com/api/posts/1", "http://b.example.com:8080/api/users/ken", "http://c.example.com/api/users/ken"} {
+		url, _ := url.Parse(raw)
+		hash, err := srt.Find(url)
+		if err != nil {
+			t.Logf("expected %s to match a host template: %v", raw, err)
+			t.FailNow()
+		}
+		if srt.GetConfig(url.Host, hash)["ttl"] != 5 {
+			t.Logf("config of %s resolved from the wrong shard", raw)
+			t.FailNow()
+		}
+	}
+}
+
+func TestShardedRouteTableRollback(t *testing.T) {
+	srt := NewShardedRouteTable(8)
+	named, _ := url.Parse("http://a.example.com/api/users/:username")
+	if err := srt.Register(named, nil, WithName("users")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	// The shard of a.example.com rejects the name, so no shard may keep
+	// the route
+	pattern, _ := ParseTemplate("http://*.example.com/api/posts/:id")
+	if err := srt.Register(pattern, nil, WithName("users")); !errors.Is(err, DUPLICATE_NAME) {
+		t.Logf("expected DUPLICATE_NAME but found %v", err)
+		t.FailNow()
+	}
+	for _, shard := range srt.shards {
+		if len(shard.Routes()) > 1 {
+			t.Log("expected the rejected route to be rolled back on every shard")
+			t.FailNow()
+		}
+	}
+	if err := srt.Register(pattern, nil, WithName("posts")); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+}
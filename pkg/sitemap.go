@@ -0,0 +1,107 @@
+package gtr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// The number of URLs a sitemap may hold (see sitemaps.org)
+const _sitemapLimit = 50000
+
+// A SitemapEnumerator lists the URLs of a route to write to a sitemap,
+// as the values of the route parameters of each URL keyed by name
+type SitemapEnumerator func(route *Route) ([]map[string]string, error)
+
+// The SitemapOptions struct configures the sitemap of a route table
+// Params:
+//   - BaseURL: The scheme and host of the URLs of templates without a
+//     host, since sitemaps only hold absolute URLs
+//   - Enumerators: The enumerators of the routes with parameters,
+//     keyed by route hash or name
+type SitemapOptions struct {
+	BaseURL     *url.URL
+	Enumerators map[string]SitemapEnumerator
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+// Writes a sitemap (see sitemaps.org) listing the public URLs of the
+// route table, so that the URL inventory of a site follows its routes.
+// The URLs of a route are built from the parameter values listed by
+// its enumerator (see Route.Build), while routes without an enumerator
+// are listed if their URL can be built without parameters. Virtual
+// routes, learned routes, and routes registered for methods other than
+// GET are left out. Enumerators are called without holding the lock of
+// the table.
+func (rt *RouteTable) WriteSitemap(w io.Writer, opts SitemapOptions) error {
+	rt.mutex.RLock()
+	routes := rt.sorted()
+	rt.mutex.RUnlock()
+	set := sitemapURLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		if len(route.url.Opaque) > 0 || route.class == CLASS_LEARNED || len(route.method) > 0 && route.method != http.MethodGet {
+			continue
+		}
+		enumerator, ok := opts.Enumerators[route.hash]
+		if !ok && len(route.name) > 0 {
+			enumerator, ok = opts.Enumerators[route.name]
+		}
+		var urls []*url.URL
+		if ok {
+			values, err := enumerator(route)
+			if err != nil {
+				return fmt.Errorf("%s: %w", route.template, err)
+			}
+			for _, params := range values {
+				url, err := route.Build(params, nil)
+				if err != nil {
+					return fmt.Errorf("%s: %w", route.template, err)
+				}
+				urls = append(urls, url)
+			}
+		} else if url, err := route.Build(nil, nil); err == nil {
+			urls = append(urls, url)
+		}
+		for _, url := range urls {
+			if len(url.Host) == 0 {
+				if opts.BaseURL == nil {
+					return fmt.Errorf("%w: %s has no host and no base URL was given", INVALID_VALUE, route.template)
+				}
+				url.Scheme, url.Host = opts.BaseURL.Scheme, opts.BaseURL.Host
+			}
+			if len(url.Scheme) == 0 {
+				url.Scheme = "https"
+				if opts.BaseURL != nil && len(opts.BaseURL.Scheme) > 0 {
+					url.Scheme = opts.BaseURL.Scheme
+				}
+			}
+			loc := url.String()
+			if seen[loc] {
+				continue
+			}
+			seen[loc] = true
+			set.URLs = append(set.URLs, sitemapURL{Loc: loc})
+		}
+	}
+	if len(set.URLs) > _sitemapLimit {
+		return fmt.Errorf("%w: %d URLs exceed the %d URLs of a sitemap", QUOTA_EXCEEDED, len(set.URLs), _sitemapLimit)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(set)
+}
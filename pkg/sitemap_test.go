@@ -0,0 +1,67 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	rt := newRouteTable()
+	rt.Register(PrepareURLFrom(t, "/about"), nil)
+	rt.Register(PrepareURLFrom(t, "/posts/:slug"), nil, WithName("post"))
+	rt.Register(PrepareURLFrom(t, "/users/:username"), nil)
+	rt.RegisterMethod(http.MethodPost, PrepareURLFrom(t, "/contact"), nil)
+	rt.Register(PrepareURLFrom(t, "http://www.abcdefg.com/docs"), nil)
+	buffer := bytes.NewBuffer(nil)
+	err := rt.WriteSitemap(buffer, SitemapOptions{
+		BaseURL: &url.URL{Scheme: "https", Host: "example.com"},
+		Enumerators: map[string]SitemapEnumerator{
+			"post": func(route *Route) ([]map[string]string, error) {
+				return []map[string]string{{"slug": "hello"}, {"slug": "world"}}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/about</loc>
+  </url>
+  <url>
+    <loc>https://example.com/posts/hello</loc>
+  </url>
+  <url>
+    <loc>https://example.com/posts/world</loc>
+  </url>
+  <url>
+    <loc>http://www.abcdefg.com/docs</loc>
+  </url>
+</urlset>`
+	if buffer.String() != expected {
+		t.Logf("unexpected sitemap %s", buffer.String())
+		t.FailNow()
+	}
+	err = rt.WriteSitemap(buffer, SitemapOptions{})
+	if !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE without a base URL but found %v", err)
+		t.FailNow()
+	}
+	err = rt.WriteSitemap(buffer, SitemapOptions{
+		BaseURL: &url.URL{Scheme: "https", Host: "example.com"},
+		Enumerators: map[string]SitemapEnumerator{
+			"post": func(route *Route) ([]map[string]string, error) {
+				return []map[string]string{{"slug": "a/b"}}, nil
+			},
+		},
+	})
+	if !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE for an invalid value but found %v", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,172 @@
+package gtr
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+)
+
+// The SourceLimits struct bounds what a single untrusted source
+// (for example a tenant self-service API) may register. Zero values
+// disable the corresponding limit. Every quota also has a soft limit
+// that is reported to the quota hooks once reached, so that operators
+// are warned before registrations start failing.
+// Params:
+//   - Rate: The sustained number of registrations per second
+//   - Burst: The number of registrations allowed at once (the rate
+//     rounded up, and at least 1, if zero)
+//   - MaxRoutes: The number of routes a source may own
+//   - MaxSegments: The number of path segments of a template
+//   - MaxQueryParams: The number of query params of a template
+//   - MaxConfigKeys: The number of keys of a route config
+//   - WarnRoutes, WarnSegments, WarnQueryParams, WarnConfigKeys: The
+//     soft limits of the quotas above
+type SourceLimits struct {
+	Rate            float64
+	Burst           int
+	MaxRoutes       int
+	MaxSegments     int
+	MaxQueryParams  int
+	MaxConfigKeys   int
+	WarnRoutes      int
+	WarnSegments    int
+	WarnQueryParams int
+	WarnConfigKeys  int
+}
+
+type sourceState struct {
+	tokens float64
+	last   time.Time
+	routes int
+}
+
+// Sets the limits applied to registrations made through RegisterFrom
+func (rt *RouteTable) SetSourceLimits(limits SourceLimits) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.limits = &limits
+}
+
+// Registers a new route on behalf of an untrusted source. The
+// registration is rejected with QUOTA_EXCEEDED if the template or
+// config exceed the source limits, or with RATE_LIMITED if the source
+// registers too fast. Soft limits reached by the registration are
+// reported to the quota hooks. Routes registered by a source always
+// belong to CLASS_USER.
+func (rt *RouteTable) RegisterFrom(source string, url *url.URL, conf map[string]any, opts ...RouteOption) error {
+	warnings, hooks, err := func() ([]QuotaWarning, []QuotaHook, error) {
+		rt.mutex.Lock()
+		defer rt.mutex.Unlock()
+		warnings, err := rt.registerFrom(source, url, conf, opts...)
+		return warnings, rt.hooks.quota, err
+	}()
+	// Hooks are called without holding the lock, so they may use the table
+	for _, warning := range warnings {
+		for _, hook := range hooks {
+			hook.OnQuotaWarning(warning)
+		}
+	}
+	return err
+}
+
+// Registers a new route on behalf of a source and gets the soft limits
+// reached by the registration
+func (rt *RouteTable) registerFrom(source string, url *url.URL, conf map[string]any, opts ...RouteOption) ([]QuotaWarning, error) {
+	if err := rt.checkFrozen(); err != nil {
+		return nil, err
+	}
+	state, ok := rt.sources[source]
+	if !ok {
+		state = &sourceState{last: time.Now()}
+		if rt.limits != nil {
+			state.tokens = rt.limits.burst()
+		}
+		rt.sources[source] = state
+	}
+	warnings := []QuotaWarning(nil)
+	if rt.limits != nil {
+		found, err := rt.limits.validate(source, state, url, conf)
+		if err != nil {
+			return nil, err
+		}
+		if err := rt.limits.take(state); err != nil {
+			return nil, err
+		}
+		warnings = found
+	}
+	count := len(rt.index)
+	opts = append(opts, WithClass(CLASS_USER), func(route *Route) error {
+		route.source = source
+		return nil
+	})
+	if _, err := rt.register("", url, conf, opts...); err != nil {
+		return nil, err
+	}
+	// Registering an already registered URL reaches no new limit
+	if len(rt.index) == count {
+		return nil, nil
+	}
+	state.routes++
+	if rt.limits != nil {
+		warnings = rt.limits.warn(warnings, source, QUOTA_ROUTES, state.routes, rt.limits.WarnRoutes, rt.limits.MaxRoutes)
+	}
+	return warnings, nil
+}
+
+// Checks a registration against the hard limits and gets the soft
+// limits it reaches
+func (limits *SourceLimits) validate(source string, state *sourceState, url *url.URL, conf map[string]any) ([]QuotaWarning, error) {
+	route := ParseRoute(url)
+	if limits.MaxRoutes > 0 && state.routes >= limits.MaxRoutes {
+		return nil, fmt.Errorf("%w: source owns %d routes", QUOTA_EXCEEDED, state.routes)
+	}
+	if limits.MaxSegments > 0 && len(route.segments) > limits.MaxSegments {
+		return nil, fmt.Errorf("%w: template has %d segments", QUOTA_EXCEEDED, len(route.segments))
+	}
+	if limits.MaxQueryParams > 0 && len(route.queryParams) > limits.MaxQueryParams {
+		return nil, fmt.Errorf("%w: template has %d query params", QUOTA_EXCEEDED, len(route.queryParams))
+	}
+	if limits.MaxConfigKeys > 0 && len(conf) > limits.MaxConfigKeys {
+		return nil, fmt.Errorf("%w: config has %d keys", QUOTA_EXCEEDED, len(conf))
+	}
+	warnings := []QuotaWarning(nil)
+	warnings = limits.warn(warnings, source, QUOTA_SEGMENTS, len(route.segments), limits.WarnSegments, limits.MaxSegments)
+	warnings = limits.warn(warnings, source, QUOTA_QUERY_PARAMS, len(route.queryParams), limits.WarnQueryParams, limits.MaxQueryParams)
+	warnings = limits.warn(warnings, source, QUOTA_CONFIG_KEYS, len(conf), limits.WarnConfigKeys, limits.MaxConfigKeys)
+	return warnings, nil
+}
+
+// Adds a warning if a quota reaches its soft limit
+func (limits *SourceLimits) warn(warnings []QuotaWarning, source string, quota Quota, used int, soft int, hard int) []QuotaWarning {
+	if soft <= 0 || used < soft {
+		return warnings
+	}
+	return append(warnings, QuotaWarning{Source: source, Quota: quota, Used: used, Soft: soft, Hard: hard})
+}
+
+// Gets the capacity of the token buckets of the sources
+func (limits *SourceLimits) burst() float64 {
+	if limits.Burst > 0 || limits.Rate <= 0 {
+		return float64(limits.Burst)
+	}
+	return math.Max(1, math.Ceil(limits.Rate))
+}
+
+// Takes a token from the token bucket of a source
+func (limits *SourceLimits) take(state *sourceState) error {
+	if limits.Rate <= 0 && limits.Burst <= 0 {
+		return nil
+	}
+	now := time.Now()
+	state.tokens += now.Sub(state.last).Seconds() * limits.Rate
+	if burst := limits.burst(); state.tokens > burst {
+		state.tokens = burst
+	}
+	state.last = now
+	if state.tokens < 1 {
+		return RATE_LIMITED
+	}
+	state.tokens--
+	return nil
+}
@@ -0,0 +1,58 @@
+package gtr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestRegisterFrom(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetSourceLimits(SourceLimits{
+		Rate:        0.001,
+		Burst:       2,
+		MaxSegments: 3,
+	})
+	for i := 0; i < 2; i++ {
+		url, _ := url.Parse(fmt.Sprintf("http://tenant.com/r%d/:id", i))
+		if err := rt.RegisterFrom("tenant", url, nil); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	limited, _ := url.Parse("http://tenant.com/r3/:id")
+	if err := rt.RegisterFrom("tenant", limited, nil); !errors.Is(err, RATE_LIMITED) {
+		t.Log("expected rate limit error")
+		t.FailNow()
+	}
+	if err := rt.RegisterFrom("other", limited, nil); err != nil {
+		t.Log("sources should be limited independently")
+		t.FailNow()
+	}
+	deep, _ := url.Parse("http://tenant.com/a/b/c/d")
+	if err := rt.RegisterFrom("another", deep, nil); !errors.Is(err, QUOTA_EXCEEDED) {
+		t.Log("expected quota error")
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(CreateHash(limited))
+	if route.Source() != "other" {
+		t.Log("route source was not recorded")
+		t.FailNow()
+	}
+}
+
+func TestRegisterFromWithoutBurst(t *testing.T) {
+	rt := newRouteTable()
+	rt.SetSourceLimits(SourceLimits{Rate: 0.001})
+	first, _ := url.Parse("http://tenant.com/r1/:id")
+	if err := rt.RegisterFrom("tenant", first, nil); err != nil {
+		t.Logf("expected a burst of 1 without a burst limit but found %s", err)
+		t.FailNow()
+	}
+	second, _ := url.Parse("http://tenant.com/r2/:id")
+	if err := rt.RegisterFrom("tenant", second, nil); !errors.Is(err, RATE_LIMITED) {
+		t.Log("expected rate limit error")
+		t.FailNow()
+	}
+}
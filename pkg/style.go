@@ -0,0 +1,92 @@
+package gtr
+
+import (
+	"net/url"
+	"strings"
+)
+
+// The ParamStyle determines how parameters are written when a
+// template is rendered
+type ParamStyle int
+
+const (
+	// Express style parameters, for example `:username`
+	PARAM_STYLE_COLON ParamStyle = iota
+	// OpenAPI style parameters, for example `{username}`
+	PARAM_STYLE_BRACE
+)
+
+// Renders the route template using the given parameter style
+func (route *Route) Format(style ParamStyle) string {
+	if style != PARAM_STYLE_BRACE {
+		return route.template
+	}
+	url, err := ParseTemplate(route.template)
+	if err != nil {
+		return route.template
+	}
+	labels := strings.Split(url.Host, ".")
+	for index, label := range labels {
+		if strings.HasPrefix(label, ":") {
+			labels[index] = "{" + label[1:] + "}"
+		}
+	}
+	url.Host = strings.Join(labels, ".")
+	segments := strings.Split(url.Path, "/")
+	for index, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[index] = "{" + strings.Replace(segment[1:], ".:", "}.{", 1) + "}"
+		}
+	}
+	url.Path = strings.Join(segments, "/")
+	return formatTemplate(url)
+}
+
+// Rewrites curly-brace parameters to their colon equivalents
+func normalizePath(path string) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		if len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			// Extension parameters are written `{name}.{ext}`
+			segments[index] = ":" + strings.Replace(segment[1:len(segment)-1], "}.{", ".:", 1)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func normalizeTemplate(template *url.URL) *url.URL {
+	path := normalizePath(template.Path)
+	if path == template.Path {
+		return template
+	}
+	normalized := *template
+	normalized.Path = path
+	normalized.RawPath = ""
+	return &normalized
+}
+
+// Formats a template without escaping its path so that parameter
+// markers stay readable
+func formatTemplate(template *url.URL) string {
+	if len(template.Opaque) > 0 {
+		return template.String()
+	}
+	buffer := strings.Builder{}
+	if len(template.Scheme) > 0 {
+		buffer.WriteString(template.Scheme)
+		buffer.WriteString(":")
+	}
+	if len(template.Scheme) > 0 || len(template.Host) > 0 {
+		buffer.WriteString("//")
+		buffer.WriteString(template.Host)
+	}
+	buffer.WriteString(template.Path)
+	if len(template.RawQuery) > 0 || template.ForceQuery {
+		buffer.WriteString("?")
+		buffer.WriteString(template.RawQuery)
+	}
+	return buffer.String()
+}
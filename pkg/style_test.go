@@ -0,0 +1,40 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestBraceParams(t *testing.T) {
+	colon, _ := url.Parse("http://www.abcdefg.com/api/v1/users/:username/details?type=cache")
+	brace, _ := url.Parse("http://www.abcdefg.com/api/v1/users/{username}/details?type=cache")
+	if CreateHash(colon) != CreateHash(brace) {
+		t.Log("brace and colon templates hash differently")
+		t.FailNow()
+	}
+	rt := newRouteTable()
+	rt.Register(brace, nil)
+	rt.Register(colon, nil)
+	if len(rt.Routes()) != 1 {
+		t.Log("brace and colon templates registered separately")
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURL(t))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Params["username"] != "ken" {
+		t.Log("brace parameter was not extracted")
+		t.FailNow()
+	}
+	route, _ := rt.Lookup(match.Hash)
+	if route.Format(PARAM_STYLE_BRACE) != "http://www.abcdefg.com/api/v1/users/{username}/details?type=cache" {
+		t.Log("brace style rendered incorrectly")
+		t.FailNow()
+	}
+	if route.Format(PARAM_STYLE_COLON) != "http://www.abcdefg.com/api/v1/users/:username/details?type=cache" {
+		t.Log("colon style rendered incorrectly")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,101 @@
+package gtr
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// The maximum number of suggestions of a MatchError
+const _maxSuggestions = 3
+
+// The MatchError struct is returned instead of NO_MATCH_FOUND and
+// HOST_NOT_REGISTERED when suggestions are enabled. It wraps the
+// original error, so errors.Is keeps working.
+type MatchError struct {
+	Err error
+	URL string
+	// The nearest registered templates, closest first
+	Suggestions []string
+}
+
+func (matchError *MatchError) Error() string {
+	if len(matchError.Suggestions) == 0 {
+		return fmt.Sprintf("%s: %s", matchError.Err.Error(), matchError.URL)
+	}
+	return fmt.Sprintf("%s: %s (did you mean %s?)", matchError.Err.Error(), matchError.URL, strings.Join(matchError.Suggestions, ", "))
+}
+
+func (matchError *MatchError) Unwrap() error {
+	return matchError.Err
+}
+
+// Enables or disables "did you mean" suggestions for URLs that do not
+// match any route. Suggestions are the registered templates nearest to
+// the URL by segment-wise edit distance, which is not free to compute,
+// so they are meant for developer-facing diagnostics.
+func (rt *RouteTable) SetSuggestions(enabled bool) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	rt.suggest = enabled
+}
+
+// Wraps a matching error with the templates nearest to the URL
+func (rt *RouteTable) suggestions(url *url.URL, segments []string, err error) error {
+	type suggestion struct {
+		template string
+		distance int
+	}
+	// Only templates differing in at most half of the segments qualify
+	limit := (len(segments) + 1) / 2
+	if limit == 0 {
+		limit = 1
+	}
+	found := make([]suggestion, 0)
+	for _, route := range rt.index {
+		distance := editDistance(route.Segments(), segments)
+		if distance <= limit {
+			found = append(found, suggestion{route.template, distance})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].template < found[j].template
+	})
+	matchError := MatchError{Err: err, URL: url.String(), Suggestions: make([]string, 0, _maxSuggestions)}
+	for index := 0; index < len(found) && index < _maxSuggestions; index++ {
+		matchError.Suggestions = append(matchError.Suggestions, found[index].template)
+	}
+	return &matchError
+}
+
+// Computes the Levenshtein distance between template segments and URL
+// segments. Parameters match any segment.
+func editDistance(template []string, segments []string) int {
+	previous := make([]int, len(segments)+1)
+	current := make([]int, len(segments)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(template); i++ {
+		current[0] = i
+		for j := 1; j <= len(segments); j++ {
+			cost := 1
+			if template[i-1] == segments[j-1] || strings.HasPrefix(template[i-1], ":") {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(segments)]
+}
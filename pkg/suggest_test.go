@@ -0,0 +1,66 @@
+package gtr
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details": nil,
+		"http://www.abcdefg.com/api/v1/users/:username":         nil,
+		"http://www.abcdefg.com/api/v1/posts/:id/comments":      nil,
+		"http://www.abcdefg.com/static/logo.png":                nil,
+	})
+	typo, _ := url.Parse("http://www.abcdefg.com/api/v1/usres/ken/details")
+	if _, err := rt.Find(typo); err != NO_MATCH_FOUND {
+		t.Logf("expected a plain NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+	rt.SetSuggestions(true)
+	_, err := rt.Find(typo)
+	if !errors.Is(err, NO_MATCH_FOUND) {
+		t.Logf("expected NO_MATCH_FOUND but found %v", err)
+		t.FailNow()
+	}
+	matchError := &MatchError{}
+	if !errors.As(err, &matchError) {
+		t.Log("expected a MatchError")
+		t.FailNow()
+	}
+	expected := []string{
+		"http://www.abcdefg.com/api/v1/users/:username/details",
+		"http://www.abcdefg.com/api/v1/posts/:id/comments",
+		"http://www.abcdefg.com/api/v1/users/:username",
+	}
+	if !reflect.DeepEqual(matchError.Suggestions, expected) {
+		t.Logf("unexpected suggestions %v", matchError.Suggestions)
+		t.FailNow()
+	}
+	missing, _ := url.Parse("http://www.abcdefg.com/api/v1/users/ken/details/more/segments")
+	if _, err := rt.Match(missing); !errors.Is(err, HOST_NOT_REGISTERED) || !errors.As(err, &matchError) {
+		t.Logf("expected a HOST_NOT_REGISTERED MatchError but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		template []string
+		segments []string
+		distance int
+	}{
+		{[]string{"api", ":id"}, []string{"api", "1"}, 0},
+		{[]string{"api", "users"}, []string{"api", "usres"}, 1},
+		{[]string{"api", "users", ":id"}, []string{"api", "1"}, 1},
+		{[]string{"api"}, []string{"static", "logo.png"}, 2},
+	}
+	for _, test := range tests {
+		if distance := editDistance(test.template, test.segments); distance != test.distance {
+			t.Logf("expected %d but found %d for %v", test.distance, distance, test.template)
+			t.FailNow()
+		}
+	}
+}
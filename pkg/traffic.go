@@ -0,0 +1,277 @@
+package gtr
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The TrafficReport struct summarizes the traffic matched by the route
+// table over a period, to guide the tuning of cache policies
+// Params:
+//   - Matches: The number of matches observed during the period
+//   - Sampled: The number of matches in the reservoir sample the
+//     shapes were taken from
+type TrafficReport struct {
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Matches int            `json:"matches"`
+	Sampled int            `json:"sampled"`
+	Routes  []RouteTraffic `json:"routes"`
+}
+
+// The RouteTraffic struct is the traffic shape of a single route
+// Params:
+//   - Shapes: The shapes of the parameter values in the sample, the
+//     most common first
+//   - Cardinality: The estimated number of distinct values of each
+//     parameter
+type RouteTraffic struct {
+	Hash        string            `json:"hash"`
+	Template    string            `json:"template"`
+	Matches     int               `json:"matches"`
+	Shapes      []ShapeCount      `json:"shapes"`
+	Cardinality map[string]uint64 `json:"cardinality"`
+}
+
+// The ShapeCount struct counts the sampled matches of a route whose
+// parameter values share a shape such as `id=int, name=alpha`
+type ShapeCount struct {
+	Shape string `json:"shape"`
+	Count int    `json:"count"`
+}
+
+type trafficSample struct {
+	route *Route
+	shape string
+}
+
+type routeCounter struct {
+	matches     int
+	cardinality map[string]*hyperLogLog
+}
+
+type trafficSampler struct {
+	mutex   sync.Mutex
+	size    int
+	start   time.Time
+	matches int
+	sample  []trafficSample
+	routes  map[*Route]*routeCounter
+}
+
+// Enables or disables sampling of the lookups made through Find and
+// Match. Matches are counted per route, the cardinality of every
+// parameter is estimated, and a reservoir of size matches is kept to
+// estimate how the parameter values of each route are shaped. A size
+// of zero or less disables sampling.
+func (rt *RouteTable) SampleTraffic(size int) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if size <= 0 {
+		rt.sampler = nil
+		return
+	}
+	rt.sampler = newTrafficSampler(size, time.Now())
+}
+
+// Gets the traffic report of the matches sampled so far
+// Params:
+//   - top: The number of routes reported, the most matched first, or
+//     zero or less for every route
+func (rt *RouteTable) TrafficReport(top int) TrafficReport {
+	rt.mutex.RLock()
+	sampler := rt.sampler
+	rt.mutex.RUnlock()
+	if sampler == nil {
+		now := time.Now()
+		return TrafficReport{Start: now, End: now, Routes: make([]RouteTraffic, 0)}
+	}
+	return sampler.report(top, time.Now(), false)
+}
+
+// Reports the sampled traffic periodically, starting a new period
+// after every report. The returned function stops the reports.
+// Params:
+//   - interval: The length of a period
+//   - top: The number of routes reported, or zero or less for every route
+//   - report: Called with the report of every period
+func (rt *RouteTable) ReportTraffic(interval time.Duration, top int, report func(TrafficReport)) func() {
+	rt.mutex.RLock()
+	sampler := rt.sampler
+	rt.mutex.RUnlock()
+	if sampler == nil {
+		return func() {}
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				report(sampler.report(top, now, true))
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+func newTrafficSampler(size int, now time.Time) *trafficSampler {
+	return &trafficSampler{
+		size:   size,
+		start:  now,
+		sample: make([]trafficSample, 0, size),
+		routes: make(map[*Route]*routeCounter),
+	}
+}
+
+// Records a match using reservoir sampling, so every match of the
+// period is equally likely to be in the sample
+func (sampler *trafficSampler) observe(route *Route, values map[string]string) {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+	sampler.matches++
+	counter, ok := sampler.routes[route]
+	if !ok {
+		counter = &routeCounter{cardinality: make(map[string]*hyperLogLog)}
+		sampler.routes[route] = counter
+	}
+	counter.matches++
+	for name, value := range values {
+		hll, ok := counter.cardinality[name]
+		if !ok {
+			hll = &hyperLogLog{}
+			counter.cardinality[name] = hll
+		}
+		hll.add(value)
+	}
+	if len(sampler.sample) < sampler.size {
+		sampler.sample = append(sampler.sample, trafficSample{route: route, shape: route.shapeOf(values)})
+		return
+	}
+	if index := rand.Intn(sampler.matches); index < sampler.size {
+		sampler.sample[index] = trafficSample{route: route, shape: route.shapeOf(values)}
+	}
+}
+
+// Moves the counters and samples of a route to the route replacing it
+func (sampler *trafficSampler) replace(route *Route, replacement *Route) {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+	if counter, ok := sampler.routes[route]; ok {
+		delete(sampler.routes, route)
+		sampler.routes[replacement] = counter
+	}
+	for index := range sampler.sample {
+		if sampler.sample[index].route == route {
+			sampler.sample[index].route = replacement
+		}
+	}
+}
+
+func (sampler *trafficSampler) report(top int, now time.Time, reset bool) TrafficReport {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+	report := TrafficReport{
+		Start:   sampler.start,
+		End:     now,
+		Matches: sampler.matches,
+		Sampled: len(sampler.sample),
+		Routes:  make([]RouteTraffic, 0, len(sampler.routes)),
+	}
+	shapes := make(map[*Route]map[string]int)
+	for _, sample := range sampler.sample {
+		if shapes[sample.route] == nil {
+			shapes[sample.route] = make(map[string]int)
+		}
+		shapes[sample.route][sample.shape]++
+	}
+	for route, counter := range sampler.routes {
+		traffic := RouteTraffic{
+			Hash:        route.hash,
+			Template:    route.template,
+			Matches:     counter.matches,
+			Shapes:      make([]ShapeCount, 0, len(shapes[route])),
+			Cardinality: make(map[string]uint64, len(counter.cardinality)),
+		}
+		for shape, count := range shapes[route] {
+			traffic.Shapes = append(traffic.Shapes, ShapeCount{Shape: shape, Count: count})
+		}
+		sort.Slice(traffic.Shapes, func(i, j int) bool {
+			if traffic.Shapes[i].Count != traffic.Shapes[j].Count {
+				return traffic.Shapes[i].Count > traffic.Shapes[j].Count
+			}
+			return traffic.Shapes[i].Shape < traffic.Shapes[j].Shape
+		})
+		for name, hll := range counter.cardinality {
+			traffic.Cardinality[name] = hll.estimate()
+		}
+		report.Routes = append(report.Routes, traffic)
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		if report.Routes[i].Matches != report.Routes[j].Matches {
+			return report.Routes[i].Matches > report.Routes[j].Matches
+		}
+		return report.Routes[i].Template < report.Routes[j].Template
+	})
+	if top > 0 && len(report.Routes) > top {
+		report.Routes = report.Routes[:top]
+	}
+	if reset {
+		sampler.start = now
+		sampler.matches = 0
+		sampler.sample = sampler.sample[:0]
+		sampler.routes = make(map[*Route]*routeCounter)
+	}
+	return report
+}
+
+// Gets the shape of the parameter values of a match, listing the kind
+// of every value in the order the parameters appear in the template
+func (route *Route) shapeOf(values map[string]string) string {
+	params := route.Params()
+	kinds := make([]string, 0, len(params))
+	for _, name := range params {
+		kinds = append(kinds, name+"="+valueKind(values[name]))
+	}
+	return strings.Join(kinds, ", ")
+}
+
+// Classifies a parameter value as int, uuid, hex, alpha, alnum or other
+func valueKind(value string) string {
+	switch {
+	case len(value) == 0:
+		return "empty"
+	case isNumeric(value):
+		return "int"
+	case _uuidSegment.MatchString(value):
+		return "uuid"
+	case _hexSegment.MatchString(value):
+		return "hex"
+	}
+	letters, digits := false, false
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters = true
+		case c >= '0' && c <= '9':
+			digits = true
+		default:
+			return "other"
+		}
+	}
+	if letters && digits {
+		return "alnum"
+	}
+	return "alpha"
+}
@@ -0,0 +1,98 @@
+package gtr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTrafficReport(t *testing.T) {
+	rt := newRouteTable()
+	users := PrepareURLFrom(t, "http://www.abcdefg.com/users/:username/posts/:id")
+	health := PrepareURLFrom(t, "http://www.abcdefg.com/health")
+	for _, template := range []string{users.String(), health.String()} {
+		if err := rt.Register(PrepareURLFrom(t, template), nil); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	if report := rt.TrafficReport(0); report.Matches != 0 || len(report.Routes) != 0 {
+		t.Log("expected an empty report while sampling is disabled")
+		t.FailNow()
+	}
+	rt.SampleTraffic(100)
+	for i := 0; i < 300; i++ {
+		username := "ken"
+		if i%3 == 0 {
+			username = fmt.Sprintf("user%d", i%30)
+		}
+		rt.Find(PrepareURLFrom(t, fmt.Sprintf("http://www.abcdefg.com/users/%s/posts/%d", username, i)))
+	}
+	rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/health"))
+	rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/unknown"))
+
+	report := rt.TrafficReport(0)
+	if report.Matches != 301 || report.Sampled != 100 || len(report.Routes) != 2 {
+		t.Logf("unexpected report %d matches %d sampled %d routes", report.Matches, report.Sampled, len(report.Routes))
+		t.FailNow()
+	}
+	top := report.Routes[0]
+	if top.Hash != CreateHash(users) || top.Matches != 300 {
+		t.Logf("unexpected top route %s with %d matches", top.Template, top.Matches)
+		t.FailNow()
+	}
+	if top.Cardinality["id"] < 290 || top.Cardinality["id"] > 310 || top.Cardinality["username"] != 11 {
+		t.Logf("unexpected cardinality %v", top.Cardinality)
+		t.FailNow()
+	}
+	if len(top.Shapes) != 2 || top.Shapes[0].Shape != "username=alpha, id=int" || top.Shapes[1].Shape != "username=alnum, id=int" {
+		t.Logf("unexpected shapes %v", top.Shapes)
+		t.FailNow()
+	}
+	if report := rt.TrafficReport(1); len(report.Routes) != 1 {
+		t.Log("expected the report to be limited to the top route")
+		t.FailNow()
+	}
+}
+
+func TestReportTraffic(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	rt.SampleTraffic(10)
+	rt.Find(PrepareURL(t))
+	reports := make(chan TrafficReport)
+	stop := rt.ReportTraffic(10*time.Millisecond, 0, func(report TrafficReport) {
+		reports <- report
+	})
+	defer stop()
+	if report := <-reports; report.Matches != 1 {
+		t.Logf("expected 1 match but found %d", report.Matches)
+		t.FailNow()
+	}
+	if report := <-reports; report.Matches != 0 {
+		t.Logf("expected a new period after the report but found %d matches", report.Matches)
+		t.FailNow()
+	}
+}
+
+func TestValueKind(t *testing.T) {
+	tests := map[string]string{
+		"":                                     "empty",
+		"42":                                   "int",
+		"3f2504e0-4f89-11d3-9a0c-0305e82c3301": "uuid",
+		"0123456789abcdef0":                    "hex",
+		"ken":                                  "alpha",
+		"ken42":                                "alnum",
+		"ken.42":                               "other",
+	}
+	for value, kind := range tests {
+		if valueKind(value) != kind {
+			t.Logf("expected %s for %q but found %s", kind, value, valueKind(value))
+			t.FailNow()
+		}
+	}
+}
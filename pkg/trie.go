@@ -0,0 +1,123 @@
+package gtr
+
+// A trieNode indexes the routes of a bucket by their path segments.
+// Every route is stored at the end of a path made of one edge per
+// segment, either a literal edge keyed by the segment or a parameter
+// edge, so that a lookup only reaches the routes whose literal
+// segments all appear in the URL. Its cost grows with the depth of the
+// path rather than with the number of routes sharing the bucket.
+type trieNode struct {
+	// Literal edges keyed by the segment at the depth of the node
+	literals map[string]*trieNode
+	// The parameter edge
+	param *trieNode
+	// The routes ending at the node, in registration order
+	routes []*Route
+}
+
+// Adds a route to the trie
+func (node *trieNode) insert(route *Route) {
+	for _, segment := range route.segments {
+		node = node.child(segment)
+	}
+	node.routes = append(node.routes, route)
+}
+
+// Removes a route from the trie along with the nodes left without
+// routes. Returns whether the node itself was left empty.
+func (node *trieNode) remove(route *Route) bool {
+	return node.removeAt(route, route.segments)
+}
+
+func (node *trieNode) removeAt(route *Route, segments []segment) bool {
+	if len(segments) == 0 {
+		for i, existing := range node.routes {
+			if existing == route {
+				node.routes = append(node.routes[:i:i], node.routes[i+1:]...)
+				break
+			}
+		}
+		return node.empty()
+	}
+	if segments[0].param() {
+		if node.param != nil && node.param.removeAt(route, segments[1:]) {
+			node.param = nil
+		}
+		return node.empty()
+	}
+	if child, ok := node.literals[segments[0].value]; ok && child.removeAt(route, segments[1:]) {
+		delete(node.literals, segments[0].value)
+	}
+	return node.empty()
+}
+
+// Replaces a route of the trie by a copy with the same segments
+func (node *trieNode) replace(route *Route, clone *Route) {
+	for _, segment := range route.segments {
+		node = node.child(segment)
+	}
+	routes := make([]*Route, len(node.routes))
+	for i, existing := range node.routes {
+		if existing == route {
+			existing = clone
+		}
+		routes[i] = existing
+	}
+	node.routes = routes
+}
+
+func (node *trieNode) empty() bool {
+	return len(node.routes) == 0 && len(node.literals) == 0 && node.param == nil
+}
+
+func (node *trieNode) child(segment segment) *trieNode {
+	if segment.param() {
+		if node.param == nil {
+			node.param = &trieNode{}
+		}
+		return node.param
+	}
+	if node.literals == nil {
+		node.literals = make(map[string]*trieNode)
+	}
+	child, ok := node.literals[segment.value]
+	if !ok {
+		child = &trieNode{}
+		node.literals[segment.value] = child
+	}
+	return child
+}
+
+// Visits every route whose literal segments match the parsed URL.
+// Parameters, constraints, and query params are left to the comparison
+// of the visited routes.
+func (node *trieNode) visit(prt *Route, fn func(route *Route)) {
+	node.visitAt(prt, 0, fn)
+}
+
+func (node *trieNode) visitAt(prt *Route, depth int, fn func(route *Route)) {
+	for _, route := range node.routes {
+		fn(route)
+	}
+	if depth >= len(prt.segments) {
+		return
+	}
+	if child, ok := node.literals[prt.segment(depth)]; ok {
+		child.visitAt(prt, depth+1, fn)
+	}
+	if node.param != nil {
+		node.param.visitAt(prt, depth+1, fn)
+	}
+}
+
+// Rebuilds the tries of every bucket
+func (rt *RouteTable) reindex() {
+	rt.tries = make(map[int]*trieNode, len(rt.routes))
+	for segments, bucket := range rt.routes {
+		trie := &trieNode{}
+		for _, route := range bucket {
+			trie.insert(route)
+		}
+		rt.tries[segments] = trie
+	}
+}
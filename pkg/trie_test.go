@@ -0,0 +1,91 @@
+package gtr
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+)
+
+func TestTrieMatchesLinearScan(t *testing.T) {
+	trie := newRouteTable()
+	linear := newRouteTable()
+	linear.SetComparator(DefaultComparator)
+	segments := []string{"api", "users", "posts", ":id", ":name", "v1"}
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		path := ""
+		for j := 0; j < 3; j++ {
+			path += "/" + segments[random.Intn(len(segments))]
+		}
+		template := PrepareURLFrom(t, "http://www.abcdefg.com"+path)
+		trie.Register(template, nil)
+		linear.Register(template, nil)
+	}
+	values := []string{"api", "users", "posts", "v1", "ken", "42"}
+	for i := 0; i < 300; i++ {
+		path := ""
+		for j := 0; j < 3; j++ {
+			path += "/" + values[random.Intn(len(values))]
+		}
+		url := PrepareURLFrom(t, "http://www.abcdefg.com"+path)
+		expected, expectedErr := linear.Find(url)
+		hash, err := trie.Find(url)
+		if hash != expected || (err == nil) != (expectedErr == nil) {
+			t.Logf("%s matched %s (%v) instead of %s (%v)", path, hash, err, expected, expectedErr)
+			t.FailNow()
+		}
+	}
+}
+
+func TestTrieTies(t *testing.T) {
+	rt := newRouteTable()
+	first := PrepareURLFrom(t, "http://www.abcdefg.com/:kind/ken")
+	second := PrepareURLFrom(t, "http://www.abcdefg.com/users/:name")
+	rt.Register(first, nil)
+	rt.Register(second, nil)
+	url := PrepareURLFrom(t, "http://www.abcdefg.com/users/ken")
+	if hash, _ := rt.Find(url); hash != CreateHash(first) {
+		t.Log("expected the first registered route to win a tie")
+		t.FailNow()
+	}
+	// The tries are rebuilt when routes are replaced
+	if _, err := rt.Rehash(Hasher{Version: HASH_V2}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	rt.Compact()
+	if hash, _ := rt.Find(url); hash != (Hasher{Version: HASH_V2}).Hash(first) {
+		t.Log("expected the tie to be kept after rehashing")
+		t.FailNow()
+	}
+}
+
+func BenchmarkFindManyRoutes(b *testing.B) {
+	for _, routes := range []int{100, 1000, 10000} {
+		for _, linear := range []bool{false, true} {
+			name := fmt.Sprintf("routes=%d/trie", routes)
+			if linear {
+				name = fmt.Sprintf("routes=%d/linear", routes)
+			}
+			b.Run(name, func(b *testing.B) {
+				rt := newRouteTable()
+				if linear {
+					rt.SetComparator(DefaultComparator)
+				}
+				for i := 0; i < routes; i++ {
+					template, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/api/v%d/users/:username/details", i))
+					rt.Register(template, nil)
+				}
+				url, _ := url.Parse(fmt.Sprintf("http://www.abcdefg.com/api/v%d/users/ken/details", routes/2))
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := rt.Find(url); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Registers a new route with a typed config. The config is stored as
+// is and can be read back without conversion through GetTyped, while
+// GetConfig serves a map view of it built from its JSON tags.
+func RegisterTyped[T any](rt *RouteTable, url *url.URL, conf T, opts ...RouteOption) error {
+	view, err := configView(conf)
+	if err != nil {
+		return err
+	}
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	route, err := rt.register("", url, view, opts...)
+	if route == nil {
+		return err
+	}
+	if rt.typed == nil {
+		rt.typed = make(map[string]any)
+	}
+	rt.typed[route.hash] = conf
+	if err != nil {
+		return err
+	}
+	// The config may have been patched by overrides (see OverrideConfig)
+	if len(rt.overrides) > 0 {
+		return rt.setConfig(route.hash, rt.configs[route.hash])
+	}
+	return nil
+}
+
+// Gets the typed config of a route registered through RegisterTyped.
+// The second return value is false if the route has no config of type T.
+func GetTyped[T any](rt *RouteTable, hash string) (T, bool) {
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	conf, ok := rt.typed[hash].(T)
+	return conf, ok
+}
+
+// Builds the map view of a typed config
+func configView(conf any) (map[string]any, error) {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+	}
+	view := make(map[string]any)
+	if err := json.Unmarshal(data, &view); err != nil {
+		return nil, fmt.Errorf("%w: config must be a struct or a map", INVALID_VALUE)
+	}
+	return view, nil
+}
+
+// Replaces the config of a route. Routes with a typed config have
+// the new config decoded into their type, which fails with
+// INVALID_VALUE if the config does not fit it.
+func (rt *RouteTable) setConfig(hash string, conf map[string]any) error {
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	if typed, ok := rt.typed[hash]; ok {
+		data, err := json.Marshal(conf)
+		if err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		value := reflect.New(reflect.TypeOf(typed))
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return fmt.Errorf("%w: %s", INVALID_VALUE, err.Error())
+		}
+		rt.typed[hash] = value.Elem().Interface()
+	}
+	rt.configs[hash] = conf
+	return nil
+}
@@ -0,0 +1,58 @@
+package gtr
+
+import (
+	"errors"
+	"testing"
+)
+
+type cacheConfig struct {
+	TTL    int    `json:"ttl"`
+	Format string `json:"format,omitempty"`
+}
+
+func TestRegisterTyped(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	if err := RegisterTyped(rt, template, cacheConfig{TTL: 10, Format: "json"}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	conf, ok := GetTyped[cacheConfig](rt, hash)
+	if !ok || conf.TTL != 10 || conf.Format != "json" {
+		t.Logf("unexpected typed config %v", conf)
+		t.FailNow()
+	}
+	if _, ok := GetTyped[map[string]any](rt, hash); ok {
+		t.Log("expected a config of another type to be missing")
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["ttl"] != 10.0 || rt.GetConfig(hash)["format"] != "json" {
+		t.Logf("unexpected config view %v", rt.GetConfig(hash))
+		t.FailNow()
+	}
+	if err := rt.PatchConfig(hash, []byte(`{"ttl": 20}`)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if conf, _ := GetTyped[cacheConfig](rt, hash); conf.TTL != 20 || conf.Format != "json" {
+		t.Logf("typed config not updated by patch %v", conf)
+		t.FailNow()
+	}
+	if err := rt.PatchConfig(hash, []byte(`{"ttl": "long"}`)); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash)["ttl"] != 20.0 {
+		t.Log("rejected patch changed the config")
+		t.FailNow()
+	}
+}
+
+func TestRegisterTypedInvalid(t *testing.T) {
+	rt := newRouteTable()
+	if err := RegisterTyped(rt, PrepareURLTemplate(t), 10); !errors.Is(err, INVALID_VALUE) {
+		t.Logf("expected INVALID_VALUE but found %v", err)
+		t.FailNow()
+	}
+}
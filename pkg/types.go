@@ -0,0 +1,87 @@
+package gtr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The ParamType declares the type of a parameter value. Types are
+// declared in templates by wrapping their name in angle brackets,
+// for example `?page=<int>&active=<bool>` or `/orders/:id<int>`.
+type ParamType string
+
+const (
+	PARAM_STRING ParamType = "string"
+	PARAM_INT    ParamType = "int"
+	PARAM_FLOAT  ParamType = "float"
+	PARAM_BOOL   ParamType = "bool"
+	PARAM_UUID   ParamType = "uuid"
+)
+
+// Converts a raw value to the declared type
+func (paramType ParamType) Coerce(value string) (any, error) {
+	switch paramType {
+	case PARAM_STRING:
+		return value, nil
+	case PARAM_INT:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not an int", INVALID_VALUE, value)
+		}
+		return number, nil
+	case PARAM_FLOAT:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a float", INVALID_VALUE, value)
+		}
+		return number, nil
+	case PARAM_BOOL:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a bool", INVALID_VALUE, value)
+		}
+		return boolean, nil
+	case PARAM_UUID:
+		if !_uuidSegment.MatchString(value) {
+			return nil, fmt.Errorf("%w: %q is not a uuid", INVALID_VALUE, value)
+		}
+		return strings.ToLower(value), nil
+	}
+	return nil, fmt.Errorf("%w: unknown type %s", INVALID_VALUE, paramType)
+}
+
+func parseParamType(value string) (ParamType, bool) {
+	if !strings.HasPrefix(value, "<") || !strings.HasSuffix(value, ">") {
+		return "", false
+	}
+	paramType := ParamType(value[1 : len(value)-1])
+	switch paramType {
+	case PARAM_STRING, PARAM_INT, PARAM_FLOAT, PARAM_BOOL, PARAM_UUID:
+		return paramType, true
+	}
+	return "", false
+}
+
+// A typeConstraint restricts a route parameter to the values of a type,
+// for example `:id<int>`
+type typeConstraint struct {
+	paramType ParamType
+}
+
+func (constraint typeConstraint) accepts(value string) bool {
+	_, err := constraint.paramType.Coerce(value)
+	return err == nil
+}
+
+func (constraint typeConstraint) example() string {
+	return exampleTypedValue(constraint.paramType, 0)
+}
+
+func (constraint typeConstraint) samples() []string {
+	return []string{"0", "-1", "1.5", "true", "sample", "00000000-0000-4000-8000-000000000001"}
+}
+
+func (constraint typeConstraint) String() string {
+	return string(constraint.paramType)
+}
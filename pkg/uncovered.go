@@ -0,0 +1,139 @@
+package gtr
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// The number of distinct values tracked per uncovered query param.
+// Params with more values are reported with this many.
+const _maxTrackedValues = 1000
+
+// The UncoveredQuery struct reports a query param seen on requests
+// to a route although the template of the route neither declares nor
+// ignores it. Params with many distinct values fragment the cache and
+// are candidates for the ignore-lists.
+type UncoveredQuery struct {
+	Hash           string `json:"hash"`
+	Template       string `json:"template"`
+	Key            string `json:"key"`
+	Count          int    `json:"count"`
+	DistinctValues int    `json:"distinctValues"`
+}
+
+type queryStat struct {
+	count  int
+	values map[string]bool
+}
+
+type queryTracker struct {
+	mutex sync.Mutex
+	stats map[*Route]map[string]*queryStat
+}
+
+// Enables or disables tracking of uncovered query params on lookups
+// made through Find and Match. Disabling tracking drops what was
+// tracked so far.
+func (rt *RouteTable) TrackUncoveredQuery(enabled bool) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if !enabled {
+		rt.tracker = nil
+		return
+	}
+	if rt.tracker == nil {
+		rt.tracker = &queryTracker{stats: make(map[*Route]map[string]*queryStat)}
+	}
+}
+
+// Gets the uncovered query params tracked so far, the ones with the
+// most distinct values first
+func (rt *RouteTable) UncoveredQueryReport() []UncoveredQuery {
+	report := make([]UncoveredQuery, 0)
+	rt.mutex.RLock()
+	tracker := rt.tracker
+	rt.mutex.RUnlock()
+	if tracker == nil {
+		return report
+	}
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	for route, stats := range tracker.stats {
+		for key, stat := range stats {
+			report = append(report, UncoveredQuery{
+				Hash:           route.hash,
+				Template:       route.template,
+				Key:            key,
+				Count:          stat.count,
+				DistinctValues: len(stat.values),
+			})
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].DistinctValues != report[j].DistinctValues {
+			return report[i].DistinctValues > report[j].DistinctValues
+		}
+		if report[i].Count != report[j].Count {
+			return report[i].Count > report[j].Count
+		}
+		if report[i].Template != report[j].Template {
+			return report[i].Template < report[j].Template
+		}
+		return report[i].Key < report[j].Key
+	})
+	return report
+}
+
+// Gets the query params of a request that are neither declared by
+// the template of the matching route nor ignored by its cache keys
+func (rt *RouteTable) uncovered(route *Route, query url.Values) []string {
+	keys := make([]string, 0)
+	for key := range query {
+		if _, ok := route.queryParams[key]; ok || route.varies(key) || rt.isQueryIgnored(route, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Records the uncovered query params of a matched request
+func (tracker *queryTracker) track(rt *RouteTable, route *Route, url *url.URL) {
+	query := url.Query()
+	keys := rt.uncovered(route, query)
+	if len(keys) == 0 {
+		return
+	}
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	stats, ok := tracker.stats[route]
+	if !ok {
+		stats = make(map[string]*queryStat)
+		tracker.stats[route] = stats
+	}
+	for _, key := range keys {
+		stat, ok := stats[key]
+		if !ok {
+			stat = &queryStat{values: make(map[string]bool)}
+			stats[key] = stat
+		}
+		stat.count++
+		for _, value := range query[key] {
+			if len(stat.values) < _maxTrackedValues {
+				stat.values[value] = true
+			}
+		}
+	}
+}
+
+// Moves the stats of a route to the route replacing it
+func (tracker *queryTracker) replace(route *Route, replacement *Route) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if stats, ok := tracker.stats[route]; ok {
+		delete(tracker.stats, route)
+		tracker.stats[replacement] = stats
+	}
+}
@@ -0,0 +1,51 @@
+package gtr
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUncoveredQuery(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache&filter[*]=*": nil,
+	})
+	rt.SetIgnoredQuery("utm_*")
+	match, err := rt.Match(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/ken/details?type=cache&filter[a]=1&utm_source=x&session=1&page=2"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(match.Uncovered, []string{"page", "session"}) {
+		t.Logf("unexpected uncovered params %v", match.Uncovered)
+		t.FailNow()
+	}
+}
+
+func TestUncoveredQueryReport(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/users/:username/details?type=cache": nil,
+	})
+	if len(rt.UncoveredQueryReport()) != 0 {
+		t.Log("expected an empty report while tracking is disabled")
+		t.FailNow()
+	}
+	rt.TrackUncoveredQuery(true)
+	for i := 0; i < 10; i++ {
+		url := PrepareURLFrom(t, fmt.Sprintf("http://www.abcdefg.com/api/v1/users/ken/details?type=cache&session=%d&lang=en", i))
+		if _, err := rt.Find(url); err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+	}
+	report := rt.UncoveredQueryReport()
+	if len(report) != 2 || report[0].Key != "session" || report[0].DistinctValues != 10 || report[1].Key != "lang" || report[1].Count != 10 || report[1].DistinctValues != 1 {
+		t.Logf("unexpected report %v", report)
+		t.FailNow()
+	}
+	rt.TrackUncoveredQuery(false)
+	if len(rt.UncoveredQueryReport()) != 0 {
+		t.Log("expected disabling tracking to drop the report")
+		t.FailNow()
+	}
+}
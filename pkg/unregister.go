@@ -0,0 +1,87 @@
+package gtr
+
+import "net/url"
+
+// Removes the route registered for a template from the route table
+// Returns HASH_NOT_REGISTERED if the template was never registered
+func (rt *RouteTable) Unregister(url *url.URL) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	route, err := rt.prepare("", url)
+	if err != nil {
+		return err
+	}
+	return rt.unregister(route.hash)
+}
+
+// Removes the route registered for a hash from the route table
+func (rt *RouteTable) UnregisterHash(hash string) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	return rt.unregister(hash)
+}
+
+// Replaces the config of a registered route
+func (rt *RouteTable) UpdateConfig(hash string, conf map[string]any) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return err
+	}
+	if err := rt.setConfig(hash, conf); err != nil {
+		return err
+	}
+	return rt.record(EVENT_UPDATE, route, conf)
+}
+
+// Removes a route from its bucket, its trie, and every map keyed by
+// its hash
+func (rt *RouteTable) unregister(hash string) error {
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	route, err := rt.lookup(hash)
+	if err != nil {
+		return err
+	}
+	segments := len(route.segments)
+	bucket := rt.routes[segments]
+	for i, existing := range bucket {
+		if existing == route {
+			copy(bucket[i:], bucket[i+1:])
+			bucket[len(bucket)-1] = nil
+			bucket = bucket[:len(bucket)-1]
+			break
+		}
+	}
+	if len(bucket) == 0 {
+		delete(rt.routes, segments)
+		delete(rt.tries, segments)
+	} else {
+		rt.routes[segments] = bucket
+		rt.tries[segments].remove(route)
+	}
+	delete(rt.index, hash)
+	delete(rt.configs, hash)
+	delete(rt.typed, hash)
+	if state, ok := rt.sources[route.source]; ok && route.source != "" {
+		state.routes--
+	}
+	if rt.cardinality != nil {
+		rt.cardinality.forget(route)
+	}
+	if rt.groups != nil {
+		rt.groups.forget(route)
+	}
+	return rt.record(EVENT_UNREGISTER, route, nil)
+}
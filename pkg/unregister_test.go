@@ -0,0 +1,147 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestUnregister(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	other := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id/posts/:postId")
+	if err := rt.Register(template, map[string]any{"ttl": 10}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Register(other, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if err := rt.Unregister(template); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	hash := CreateHash(template)
+	if _, err := rt.Lookup(hash); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if rt.GetConfig(hash) != nil {
+		t.Log("expected the config to be removed")
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURL(t)); err == nil {
+		t.Log("expected the removed route not to match")
+		t.FailNow()
+	}
+	if found, _ := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/1/posts/2")); found != CreateHash(other) {
+		t.Log("expected other routes to still match")
+		t.FailNow()
+	}
+	if err := rt.UnregisterHash(hash); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.UnregisterHash(CreateHash(other)); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if len(rt.routes) != 0 || len(rt.tries) != 0 || len(rt.configs) != 0 {
+		t.Log("expected every bucket and config to be removed")
+		t.FailNow()
+	}
+
+	// Routes can be registered again once removed
+	if err := rt.Register(template, nil); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if found, _ := rt.Find(PrepareURL(t)); found != hash {
+		t.Log("expected the route to match again")
+		t.FailNow()
+	}
+}
+
+func TestUnregisterFrozen(t *testing.T) {
+	rt := newRouteTable()
+	template := PrepareURLTemplate(t)
+	rt.Register(template, nil)
+	rt.Freeze()
+	if err := rt.Unregister(template); !errors.Is(err, TABLE_FROZEN) {
+		t.Logf("expected TABLE_FROZEN but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.UpdateConfig(CreateHash(template), nil); !errors.Is(err, TABLE_FROZEN) {
+		t.Logf("expected TABLE_FROZEN but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLTemplate(t)
+	rt.Register(template, map[string]any{"ttl": 10.0, "bypass": true})
+	hash := CreateHash(template)
+	if err := rt.UpdateConfig(hash, map[string]any{"ttl": 20.0}); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if conf := rt.GetConfig(hash); conf["ttl"] != 20.0 || conf["bypass"] != nil {
+		t.Logf("expected the config to be replaced but found %v", conf)
+		t.FailNow()
+	}
+	if err := rt.UpdateConfig("missing", nil); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected HASH_NOT_REGISTERED but found %v", err)
+		t.FailNow()
+	}
+	if err := rt.Unregister(template); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	replayed := newRouteTable()
+	if err := replayed.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if _, err := replayed.Lookup(hash); !errors.Is(err, HASH_NOT_REGISTERED) {
+		t.Logf("expected the replayed route to be removed but found %v", err)
+		t.FailNow()
+	}
+}
+
+func TestUnregisterTrie(t *testing.T) {
+	rt := newRouteTable()
+	first := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/:id")
+	second := PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")
+	rt.Register(first, nil)
+	rt.Register(second, nil)
+	if err := rt.Unregister(second); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if found, _ := rt.Find(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/users/me")); found != CreateHash(first) {
+		t.Log("expected the remaining route to match")
+		t.FailNow()
+	}
+	for _, trie := range rt.tries {
+		if nodes := countNodes(trie); nodes != 5 {
+			t.Logf("expected the nodes of the removed route to be pruned but found %d nodes", nodes)
+			t.FailNow()
+		}
+	}
+}
+
+func countNodes(node *trieNode) int {
+	count := 1
+	for _, child := range node.literals {
+		count += countNodes(child)
+	}
+	if node.param != nil {
+		count += countNodes(node.param)
+	}
+	return count
+}
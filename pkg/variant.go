@@ -0,0 +1,58 @@
+package gtr
+
+import "fmt"
+
+// The Variants struct keys config overlays by the value of one of the
+// parameters of a route, for example a locale or a device class, so
+// that the variants of a route differ in config without duplicating
+// its template
+// Params:
+//   - Param: The name of the variant parameter
+//   - Overlays: Config values overriding the route config, keyed by
+//     the value of the variant parameter
+type Variants struct {
+	Param    string                    `json:"param"`
+	Overlays map[string]map[string]any `json:"overlays"`
+}
+
+// Designates the variant parameter of a route and the config overlays
+// of its values (see GetConfigVariant and MatchPolicy)
+func WithVariants(variants Variants) RouteOption {
+	return func(route *Route) error {
+		for _, name := range route.Params() {
+			if name == variants.Param {
+				route.variants = &Variants{Param: variants.Param, Overlays: copyMap(variants.Overlays)}
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", UNKNOWN_PARAMETER, variants.Param)
+	}
+}
+
+// Designates the variant parameter of an already registered route
+func (rt *RouteTable) SetVariants(hash string, variants Variants) error {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	if err := rt.checkFrozen(); err != nil {
+		return err
+	}
+	if err := rt.modify(hash, WithVariants(variants)); err != nil {
+		return err
+	}
+	return rt.recordUpdate(hash)
+}
+
+// Gets the config of a route for a value of its variant parameter, that
+// is the overlay of the variant layered on top of the route config.
+// Variants without an overlay get the route config.
+func (rt *RouteTable) GetConfigVariant(hash string, variant string) map[string]any {
+	conf := copyMap(rt.GetConfig(hash))
+	rt.mutex.RLock()
+	defer rt.mutex.RUnlock()
+	if route, err := rt.lookup(hash); err == nil && route.variants != nil {
+		for key, value := range route.variants.Overlays[variant] {
+			conf[key] = value
+		}
+	}
+	return conf
+}
@@ -0,0 +1,87 @@
+package gtr
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	rt := newRouteTable()
+	variants := Variants{
+		Param: "device",
+		Overlays: map[string]map[string]any{
+			"mobile": {"ttl": "5m"},
+		},
+	}
+	err := rt.Register(PrepareURLFrom(t, "/:device/api/v1/posts/:id"), map[string]any{"ttl": "1m", "bypass": false}, WithVariants(variants), WithMethodOverlay(http.MethodPost, map[string]any{"bypass": true}))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	match, err := rt.Match(PrepareURLFrom(t, "/mobile/api/v1/posts/1"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if match.Variant != "mobile" {
+		t.Logf("expected the mobile variant but found %q", match.Variant)
+		t.FailNow()
+	}
+	if policy := rt.MatchPolicy(match, http.MethodGet); policy[POLICY_TTL] != "5m" || policy.Bypass() {
+		t.Logf("unexpected policy %v", policy)
+		t.FailNow()
+	}
+	if policy := rt.MatchPolicy(match, http.MethodPost); policy[POLICY_TTL] != "5m" || !policy.Bypass() {
+		t.Logf("expected the method overlay on top of the variant but found %v", policy)
+		t.FailNow()
+	}
+	if conf := rt.GetConfigVariant(match.Hash, "desktop"); conf["ttl"] != "1m" {
+		t.Logf("expected the route config for a variant without overlay but found %v", conf)
+		t.FailNow()
+	}
+	if conf := rt.GetConfigVariant(match.Hash, "mobile"); conf["ttl"] != "5m" || rt.GetConfig(match.Hash)["ttl"] != "1m" {
+		t.Log("expected the overlay on a copy of the route config")
+		t.FailNow()
+	}
+	if err := rt.Register(PrepareURLFrom(t, "/api/v1/users/:id"), nil, WithVariants(Variants{Param: "locale"})); !errors.Is(err, UNKNOWN_PARAMETER) {
+		t.Logf("expected UNKNOWN_PARAMETER but found %v", err)
+		t.FailNow()
+	}
+	exported, _ := rt.Export()
+	restored := newRouteTable()
+	if _, err := restored.Import(exported); err != nil || !strings.Contains(string(exported), `"variants"`) {
+		t.Logf("expected the variants to be exported: %v", err)
+		t.FailNow()
+	}
+	if conf := restored.GetConfigVariant(match.Hash, "mobile"); conf["ttl"] != "5m" {
+		t.Log("expected the variants to be restored")
+		t.FailNow()
+	}
+}
+
+func TestReplayVariants(t *testing.T) {
+	log := bytes.Buffer{}
+	rt := newRouteTable()
+	rt.SetEventLog(&log)
+	template := PrepareURLFrom(t, "/:device/api/v1/posts/:id")
+	hash := CreateHash(template)
+	rt.Register(template, map[string]any{"ttl": "1m"})
+	variants := Variants{Param: "device", Overlays: map[string]map[string]any{"mobile": {"ttl": "5m"}}}
+	if err := rt.SetVariants(hash, variants); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	replayed := newRouteTable()
+	if err := replayed.Replay(bytes.NewReader(log.Bytes())); err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	if conf := replayed.GetConfigVariant(hash, "mobile"); conf["ttl"] != "5m" {
+		t.Logf("replay did not restore the variants, found %v", conf)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,33 @@
+package gtr
+
+import "net/url"
+
+var (
+	_virtualSchemes = map[string]bool{
+		"graphql": true,
+		"jsonrpc": true,
+	}
+)
+
+// Gets the path used for matching a URL. Opaque URLs such as
+// `graphql:GetUser` are virtual routes whose scheme becomes the
+// first segment, so they never collide with regular paths.
+func routePath(url *url.URL) string {
+	if len(url.Opaque) == 0 {
+		return url.Path
+	}
+	return "/" + url.Scheme + ":/" + url.Opaque
+}
+
+// Creates the URL of a virtual route
+// Params:
+//   - scheme: The kind of virtual route, for example `graphql`
+//   - name: The name of the route, for example the operation name
+//   - query: Additional attributes that templates can constrain
+func virtualURL(scheme string, name string, query url.Values) *url.URL {
+	return &url.URL{
+		Scheme:   scheme,
+		Opaque:   name,
+		RawQuery: query.Encode(),
+	}
+}
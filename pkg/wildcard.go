@@ -0,0 +1,52 @@
+package gtr
+
+import "strings"
+
+// Moves the query params of a template whose keys contain `*` to the
+// wildcard query params of the route. A template such as
+// `?filter[*]=*` matches URLs having at least one query param whose key
+// matches the key pattern and whose value matches the value pattern.
+func (route *Route) splitWildcards() {
+	for key, value := range route.queryParams {
+		if !strings.Contains(key, "*") {
+			continue
+		}
+		if route.queryWildcards == nil {
+			route.queryWildcards = make(map[string]string)
+		}
+		route.queryWildcards[key] = value
+		delete(route.queryParams, key)
+		delete(route.queryTypes, key)
+	}
+}
+
+// Finds a query param of a URL matching a wildcard query param of
+// the template
+// Returns the matching value alongside CHECK_PARAM, or CHECK_MISMATCH
+// if only the key matched, or CHECK_MISSING if no key matched.
+func matchWildcard(pattern string, value string, query map[string]string) (string, CheckResult) {
+	result := CHECK_MISSING
+	found := ""
+	for key, val := range query {
+		if !globMatch(pattern, key) {
+			continue
+		}
+		if globMatch(value, val) {
+			return key + "=" + val, CHECK_PARAM
+		}
+		result = CHECK_MISMATCH
+		found = key + "=" + val
+	}
+	return found, result
+}
+
+// Checks whether a query param key is covered by a wildcard query
+// param of the route
+func (route *Route) varies(key string) bool {
+	for pattern := range route.queryWildcards {
+		if globMatch(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
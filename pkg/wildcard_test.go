@@ -0,0 +1,55 @@
+package gtr
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestWildcardQuery(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/articles?filter[*]=*": {"ttl": 10},
+		"http://www.abcdefg.com/api/v1/authors?sort=*_desc*": {"ttl": 10},
+	})
+	tests := map[string]bool{
+		"http://www.abcdefg.com/api/v1/articles?filter[author]=ken":    true,
+		"http://www.abcdefg.com/api/v1/articles?page=2&filter[tag]=go": true,
+		"http://www.abcdefg.com/api/v1/articles?page=2":                false,
+		"http://www.abcdefg.com/api/v1/articles?filters=go":            false,
+		"http://www.abcdefg.com/api/v1/authors?sort=*_desc*":           true,
+		"http://www.abcdefg.com/api/v1/authors?sort=name_desc":         false,
+	}
+	for raw, expected := range tests {
+		url, _ := url.Parse(raw)
+		if _, err := rt.Find(url); (err == nil) != expected {
+			t.Logf("unexpected result %v for %s", err, raw)
+			t.FailNow()
+		}
+	}
+	examples := rt.Examples(CreateHash(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/articles?filter[*]=*")), 1)
+	if len(examples) != 1 {
+		t.Log("expected an example")
+		t.FailNow()
+	}
+	if _, err := rt.Find(PrepareURLFrom(t, examples[0])); err != nil {
+		t.Logf("example %s does not match: %v", examples[0], err)
+		t.FailNow()
+	}
+}
+
+func TestWildcardQueryVaries(t *testing.T) {
+	rt := PrepareTable(t, map[string]map[string]any{
+		"http://www.abcdefg.com/api/v1/articles?filter[*]=*": nil,
+	})
+	rt.SetIgnoredQuery("*")
+	first, err := rt.CacheKey(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/articles?filter[tag]=go&page=1"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+	second, _ := rt.CacheKey(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/articles?filter[tag]=rust&page=1"))
+	third, _ := rt.CacheKey(PrepareURLFrom(t, "http://www.abcdefg.com/api/v1/articles?filter[tag]=go&page=2"))
+	if first == second || first != third {
+		t.Log("expected wildcard query params to vary the cache key")
+		t.FailNow()
+	}
+}